@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/quickadd"
+)
+
+var (
+	addMode     string
+	addLane     string
+	addTemplate string
+)
+
+// laneIndex returns the lane with the given title (ignoring case) or number,
+// starting at 1.
+func laneIndex(c *model.ToDoContent, arg string) (int, error) {
+	for i, title := range c.Titles {
+		if strings.EqualFold(strings.TrimSpace(title), strings.TrimSpace(arg)) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(arg); err == nil && n >= 1 && n <= len(c.Titles) {
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("lane '%v' not found, use one of: %v", arg, strings.Join(c.Titles, ", "))
+}
+
+// addTask adds a task to the end of a lane, optionally created from an item
+// template of the board. Tasks without template get the default priority of
+// the settings. The text may set further attributes in the quick-add syntax,
+// see quickadd.Parse; they take precedence over the template and the lane
+// given.
+func addTask(c *model.ToDoContent, lane, template, text string, s config.Settings, now time.Time) error {
+	if len(c.Titles) == 0 {
+		return fmt.Errorf("the board has no lanes")
+	}
+	q := quickadd.Parse(text, c.Titles, s.DateFormat, now)
+	if len(q.Errors) > 0 {
+		return errors.New(strings.Join(q.Errors, ", "))
+	}
+	if err := q.CheckRefs(c); err != nil {
+		return err
+	}
+	idx := q.Lane
+	if idx < 0 {
+		idx = 0
+		if lane != "" {
+			var err error
+			if idx, err = laneIndex(c, lane); err != nil {
+				return err
+			}
+		}
+	}
+	pos := len(c.Items[idx])
+	if template == "" {
+		if q.Title == "" {
+			return fmt.Errorf("no title given")
+		}
+		c.AddItem(idx, pos, q.Title, "", s.Priority(), "", "")
+	} else {
+		t := c.FindItemTemplate(template)
+		if t == nil {
+			return fmt.Errorf("item template '%v' not found", template)
+		}
+		if err := c.AddFromTemplate(idx, pos, t, q.Title, now); err != nil {
+			return err
+		}
+	}
+
+	item := &c.Items[idx][pos]
+	item.Tags = q.Tags
+	if q.Priority > 0 {
+		item.Priority = q.Priority
+	}
+	if q.Due != "" {
+		item.Due = q.Due
+	}
+	if q.Color != "" {
+		item.Color = q.Color
+	}
+	return q.Apply(c, item.Guid)
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add [title]",
+	Short: "add a task",
+	Long: `adds a task to the end of a lane (default: the first lane) of a mode (default:
+the last used mode). With --template the task is created from an item template
+of the mode, the title given is inserted into the title of the template.
+
+The title may contain these words, which set further attributes:
+  !1 .. !4            priority, 1 is high
+  #tag                tag
+  @due:fri, due:+2d   due date: a date, today, tomorrow, a weekday or an offset
+  color:red           color
+  >Doing              lane, "_" stands for a space, a unique prefix is enough
+  epic:42             epic, the number or GUID of a task
+  blocks:42           link to a task, also blockedby:42 and relates:42`,
+	Example: `  todo add "Call Bob"
+  todo add --template review "PR 123"
+  todo add 'Fix login !1 #bug @due:fri color:red >Doing'
+  todo add 'Write tests epic:42 blockedby:43'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		mode, err := lastMode(usr.HomeDir, addMode)
+		if err != nil {
+			return err
+		}
+		s, err := config.LoadSettings(usr.HomeDir, mode)
+		if err != nil {
+			s = config.Settings{}
+		}
+		text := strings.TrimSpace(strings.Join(args, " "))
+		return newModeStore(usr.HomeDir).Update(mode, func(c *model.ToDoContent) error {
+			return addTask(c, addLane, addTemplate, text, s, time.Now())
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.Flags().StringVarP(&addMode, "mode", "m", "", "mode of the task (default: the last used mode)")
+	addCmd.Flags().StringVarP(&addLane, "lane", "l", "", "title or number of the lane")
+	addCmd.Flags().StringVarP(&addTemplate, "template", "t", "", "item template of the task")
+}
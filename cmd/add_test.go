@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestAddTask(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.SetItemTemplate(model.ItemTemplate{Name: "review", Title: "Code review: {}", Priority: 1, Due: "+1d"})
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+	s := config.Settings{}
+
+	if err := addTask(c, "", "", "plain", s, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := addTask(c, "doing", "Review", "PR 123", s, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := addTask(c, "3", "", "done", config.Settings{DefaultPriority: 4}, now); err != nil {
+		t.Fatal(err)
+	}
+	if c.Items[0][0].Title != "plain" || c.Items[0][0].Priority != 2 || len(c.Items[2]) != 1 || c.Items[2][0].Priority != 4 {
+		t.Fatalf("unexpected board %+v", c.Items)
+	}
+	if item := c.Items[1][0]; item.Title != "Code review: PR 123" || item.Priority != 1 || item.Due != "2024-05-11" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+
+	if err := addTask(c, "", "review", "PR 7 !3 #ci >don", s, now); err != nil {
+		t.Fatal(err)
+	}
+	if item := c.Items[2][1]; item.Title != "Code review: PR 7" || item.Priority != 3 || item.Tags[0] != "ci" || item.Due != "2024-05-11" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+
+	if err := addTask(c, "", "", "tests epic:1 blockedby:#2", s, now); err != nil {
+		t.Fatal(err)
+	}
+	if item := c.Items[0][1]; item.Parent != c.Items[0][0].Guid || len(item.Links) != 1 || item.Links[0].Guid != c.Items[1][0].Guid || item.Links[0].Type != model.LinkBlockedBy {
+		t.Fatalf("epic or link not set: %+v", item)
+	}
+	if addTask(c, "", "", "x epic:99", s, now) == nil || len(c.Items[0]) != 2 {
+		t.Fatalf("task with unknown epic added")
+	}
+
+	if addTask(c, "", "", "x color:nocolor", s, now) == nil || addTask(c, "Later", "", "x", s, now) == nil || addTask(c, "", "missing", "x", s, now) == nil || addTask(c, "", "", "", s, now) == nil {
+		t.Fatalf("invalid task accepted")
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+var agendaAllModes bool
+
+// printAgenda prints the lane counts with the sums of the estimates and the
+// overdue, due and high priority tasks of the given modes.
+func printAgenda(w io.Writer, summaries []model.ModeSummary) {
+	for i, s := range summaries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, s.Mode)
+		if s.Err != nil {
+			fmt.Fprintf(w, "  error: %v\n", s.Err)
+			continue
+		}
+		for _, lane := range s.Lanes {
+			if lane.Estimate > 0 {
+				fmt.Fprintf(w, "  %-20s %3d  %v\n", lane.Title, lane.Count, model.FormatEstimate(lane.Estimate, s.EstimateUnit))
+				continue
+			}
+			fmt.Fprintf(w, "  %-20s %3d\n", lane.Title, lane.Count)
+		}
+		section := func(title string, items []model.AgendaItem) {
+			if len(items) == 0 {
+				return
+			}
+			fmt.Fprintf(w, "  %v:\n", title)
+			for _, ai := range items {
+				due := ai.Item.Due
+				if due == "" {
+					due = "-"
+				}
+				fmt.Fprintf(w, "    %-10s  %v (%v)\n", due, ai.Item.Title, ai.LaneTitle)
+			}
+		}
+		section("overdue", s.Overdue)
+		section("due today", s.DueToday)
+		section("follow up", s.FollowUp)
+		section("high priority", s.Top)
+	}
+}
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda [mode]",
+	Short: "show the tasks per lane and the overdue, due and high priority tasks",
+	Long: `shows the number of tasks per lane, the overdue tasks, the tasks due today and
+the tasks with high priority of a mode (default: the last used mode), or with
+--all-modes of all modes. The same overview is shown in the program with F8.`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		store := newModeStore(usr.HomeDir)
+		now := time.Now()
+		if agendaAllModes {
+			summaries, err := store.Summaries(now)
+			if err != nil {
+				return err
+			}
+			for i := range summaries {
+				if unit := estimateUnit(usr.HomeDir, summaries[i].Mode); unit != "" {
+					summaries[i].EstimateUnit = unit
+				}
+			}
+			printAgenda(os.Stdout, summaries)
+			return nil
+		}
+
+		var arg string
+		if len(args) == 1 {
+			arg = args[0]
+		}
+		mode, err := lastMode(usr.HomeDir, arg)
+		if err != nil {
+			return err
+		}
+		if !store.Exists(mode) {
+			return fmt.Errorf("mode '%v' not found", mode)
+		}
+		content, err := store.Open(mode)
+		if err != nil {
+			return err
+		}
+		content.SetEstimateUnit(estimateUnit(usr.HomeDir, mode))
+		printAgenda(os.Stdout, []model.ModeSummary{model.Summarize(mode, content, now)})
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(agendaCmd)
+	agendaCmd.Flags().BoolVarP(&agendaAllModes, "all-modes", "a", false, "show all modes")
+}
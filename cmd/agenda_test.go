@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestPrintAgenda(t *testing.T) {
+	item := model.AgendaItem{LaneTitle: "Doing", Item: model.Item{Title: "report", Due: "2024-05-08"}}
+	var buf bytes.Buffer
+	printAgenda(&buf, []model.ModeSummary{{
+		Mode:         "work",
+		EstimateUnit: "h",
+		Lanes:        []model.LaneCount{{Title: "Doing", Count: 1, Estimate: 2.5}, {Title: "Done"}},
+		Overdue:      []model.AgendaItem{item},
+	}})
+
+	out := buf.String()
+	for _, want := range []string{"work\n", "Doing", "  1  2.5h\n", "overdue:\n", "2024-05-08  report (Doing)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%v", want, out)
+		}
+	}
+	if strings.Contains(out, "due today") {
+		t.Fatalf("empty section printed:\n%v", out)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os/user"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/config"
+)
+
+var configMode string
+
+func settingsHelp() string {
+	var b strings.Builder
+	for _, info := range config.SettingInfos() {
+		fmt.Fprintf(&b, "\n  %-16s %s", info.Key, info.Description)
+		if len(info.Values) > 0 {
+			fmt.Fprintf(&b, "\n  %-16s values: %s", "", strings.Join(info.Values, ", "))
+		}
+	}
+	return b.String()
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "show or change the settings",
+	Long: `shows or changes the settings stored in ~/.todo/settings.json. With --mode the
+settings of a mode are changed, which override the user settings for this mode
+(stored in ~/.todo/mode/<name>/settings.json).
+
+Settings:` + settingsHelp(),
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all settings",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		values, err := config.ListSettings(usr.HomeDir, configMode)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			value := v.Value
+			if value == "" {
+				value = "-"
+			}
+			if v.Mode {
+				value += " (mode)"
+			}
+			fmt.Printf("%-16s %s\n", v.Key, value)
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:       "get <key>",
+	Short:     "show a setting",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: config.SettingKeys(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		value, err := config.GetSetting(usr.HomeDir, configMode, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:       "set <key> <value>",
+	Short:     "change a setting, an empty value removes it",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: config.SettingKeys(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		return config.SetSetting(usr.HomeDir, configMode, args[0], args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd)
+	configCmd.PersistentFlags().StringVarP(&configMode, "mode", "m", "", "use the settings of a mode")
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/cklukas/todo/internal/crypt"
+	"github.com/cklukas/todo/internal/model"
+)
+
+// passphraseEnv may hold the passphrase of encrypted modes, e.g. for "todo
+// serve" running without terminal.
+const passphraseEnv = "TODO_PASSPHRASE"
+
+// ciphers caches the opened ciphers per mode, so switching modes does not
+// ask for the passphrase again.
+var ciphers = map[string]model.Cipher{}
+
+var encryptAgeKey string
+
+// passphrasePrompt is false while the GUI is running, the terminal can not
+// be used for asking passphrases then.
+var passphrasePrompt = true
+
+func readPassphrase(prompt string) (string, error) {
+	if p := os.Getenv(passphraseEnv); len(p) > 0 {
+		return p, nil
+	}
+	if !passphrasePrompt || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("passphrase required, set %v", passphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(p), err
+}
+
+func modePath(home, mode string) (string, error) {
+	todoDir, err := model.ModeDir(mode)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(home, todoDir), nil
+}
+
+// modeCipher returns the cipher of an encrypted mode, asking for the
+// passphrase if needed. It returns nil for modes which are not encrypted.
+func modeCipher(home, mode string) (model.Cipher, error) {
+	if c, ok := ciphers[mode]; ok {
+		return c, nil
+	}
+	dir, err := modePath(home, mode)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := crypt.LoadConfig(dir)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+	c, err := cfg.Open(func() (string, error) {
+		return readPassphrase(fmt.Sprintf("Passphrase for mode '%v': ", mode))
+	})
+	if err != nil {
+		return nil, err
+	}
+	ciphers[mode] = c
+	return c, nil
+}
+
+// passphraseRequired returns whether opening a mode asks for the passphrase
+// or reads a key file.
+func passphraseRequired(home, mode string) bool {
+	if _, ok := ciphers[mode]; ok || len(os.Getenv(passphraseEnv)) > 0 {
+		return false
+	}
+	dir, err := modePath(home, mode)
+	if err != nil {
+		return false
+	}
+	cfg, err := crypt.LoadConfig(dir)
+	return err == nil && cfg != nil
+}
+
+// newModeStore returns the store of the local modes, opening encrypted modes
+// with modeCipher.
+func newModeStore(home string) *model.ModeStore {
+	return model.NewModeStore(home, func(mode string) (model.Cipher, error) {
+		return modeCipher(home, mode)
+	})
+}
+
+// openMode opens the board of a mode including its encryption.
+func openMode(home, mode string) (*model.ToDoContent, error) {
+	cipher, err := modeCipher(home, mode)
+	if err != nil {
+		return nil, err
+	}
+	return model.OpenMode(home, mode, cipher)
+}
+
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt <mode>",
+	Short: "encrypt the data of a mode",
+	Long: `encrypts todo.json, the backups and the archived items of a mode. The key is
+protected by a passphrase, or read from an age identity file (--age-key, a new
+key is generated if the file does not exist). Close running instances of the
+mode before converting it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		dir, err := modePath(usr.HomeDir, args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path.Join(dir, "todo.json")); err != nil {
+			return fmt.Errorf("mode '%v' not found", args[0])
+		}
+		if cfg, err := crypt.LoadConfig(dir); err != nil || cfg != nil {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("mode '%v' is already encrypted", args[0])
+		}
+
+		var cfg *crypt.Config
+		var c *crypt.Cipher
+		if len(encryptAgeKey) > 0 {
+			cfg, c, err = crypt.NewKeyFileConfig(encryptAgeKey)
+		} else {
+			var p1, p2 string
+			if p1, err = readPassphrase("New passphrase: "); err != nil {
+				return err
+			}
+			if os.Getenv(passphraseEnv) == "" {
+				if p2, err = readPassphrase("Repeat passphrase: "); err != nil {
+					return err
+				}
+				if p1 != p2 {
+					return errors.New("passphrases do not match")
+				}
+			}
+			cfg, c, err = crypt.NewPassphraseConfig(p1)
+		}
+		if err != nil {
+			return err
+		}
+
+		// write the configuration first, so running instances never see
+		// encrypted files without knowing about the encryption
+		if err := cfg.Save(dir); err != nil {
+			return err
+		}
+		if err := crypt.EncryptMode(dir, c); err != nil {
+			return err
+		}
+		fmt.Printf("Mode '%v' encrypted\n", args[0])
+		return nil
+	},
+}
+
+var decryptCmd = &cobra.Command{
+	Use:   "decrypt <mode>",
+	Short: "remove the encryption of a mode",
+	Long:  `converts todo.json, the backups and the archived items of an encrypted mode back to plain JSON. Close running instances of the mode before converting it.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		dir, err := modePath(usr.HomeDir, args[0])
+		if err != nil {
+			return err
+		}
+		cfg, err := crypt.LoadConfig(dir)
+		if err != nil {
+			return err
+		}
+		if cfg == nil {
+			return fmt.Errorf("mode '%v' is not encrypted", args[0])
+		}
+		c, err := cfg.Open(func() (string, error) {
+			return readPassphrase(fmt.Sprintf("Passphrase for mode '%v': ", args[0]))
+		})
+		if err != nil {
+			return err
+		}
+		if err := crypt.DecryptMode(dir, c); err != nil {
+			return err
+		}
+		if err := os.Remove(path.Join(dir, crypt.ConfigFile)); err != nil {
+			return err
+		}
+		fmt.Printf("Mode '%v' decrypted\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+	encryptCmd.Flags().StringVar(&encryptAgeKey, "age-key", "", "age identity file to use instead of a passphrase")
+}
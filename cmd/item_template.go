@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os/user"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+var (
+	itemTemplateMode string
+	itemTemplate     model.ItemTemplate
+)
+
+// updateLastMode applies f to the board of the mode given by --mode or, by
+// default, the mode used last.
+func updateLastMode(arg string, f func(c *model.ToDoContent) error) error {
+	usr, err := user.Current()
+	if err != nil {
+		return err
+	}
+	mode, err := lastMode(usr.HomeDir, arg)
+	if err != nil {
+		return err
+	}
+	return newModeStore(usr.HomeDir).Update(mode, f)
+}
+
+var itemTemplateCmd = &cobra.Command{
+	Use:     "item-template",
+	Aliases: []string{"preset"},
+	Short:   "manage the item templates of a mode",
+	Long: `item templates are presets for new tasks, stored per mode. They are selected in
+the Add Task dialog or with "todo add --template".`,
+}
+
+var itemTemplateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the item templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		mode, err := lastMode(usr.HomeDir, itemTemplateMode)
+		if err != nil {
+			return err
+		}
+		store := newModeStore(usr.HomeDir)
+		if !store.Exists(mode) {
+			return fmt.Errorf("mode '%v' not found", mode)
+		}
+		c, err := store.Open(mode)
+		if err != nil {
+			return err
+		}
+		for _, t := range c.ItemTemplates {
+			fmt.Printf("%-16s %v (priority %v", t.Name, t.Title, t.TaskPriority())
+			if t.Color != "" {
+				fmt.Printf(", %v", t.Color)
+			}
+			if t.Due != "" {
+				fmt.Printf(", due %v", t.Due)
+			}
+			fmt.Println(")")
+		}
+		return nil
+	},
+}
+
+var itemTemplateSetCmd = &cobra.Command{
+	Use:   "set <template>",
+	Short: "add or replace an item template",
+	Long: `adds or replaces an item template. In the title, "{}" is replaced by the title
+entered for the task; without "{}" it is appended. The due date is an offset
+from the day the task is added, e.g. +2d, +1w or +1m.`,
+	Example: `  todo item-template set review --title "Code review: {}" --priority 1 --color red --due +2d --note "- [ ] tests"`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t := itemTemplate
+		t.Name = args[0]
+		if _, err := model.DueFromOffset(t.Due, time.Now()); err != nil {
+			return err
+		}
+		if t.Priority < 1 || t.Priority > 4 {
+			return fmt.Errorf("invalid priority %v, use 1 (high) to 4 (idle)", t.Priority)
+		}
+		return updateLastMode(itemTemplateMode, func(c *model.ToDoContent) error {
+			c.SetItemTemplate(t)
+			return nil
+		})
+	},
+}
+
+var itemTemplateRmCmd = &cobra.Command{
+	Use:   "rm <template>",
+	Short: "remove an item template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateLastMode(itemTemplateMode, func(c *model.ToDoContent) error {
+			if !c.RemoveItemTemplate(args[0]) {
+				return fmt.Errorf("item template '%v' not found", args[0])
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(itemTemplateCmd)
+	itemTemplateCmd.AddCommand(itemTemplateListCmd, itemTemplateSetCmd, itemTemplateRmCmd)
+	itemTemplateCmd.PersistentFlags().StringVarP(&itemTemplateMode, "mode", "m", "", "mode of the templates (default: the last used mode)")
+	f := itemTemplateSetCmd.Flags()
+	f.StringVar(&itemTemplate.Title, "title", "{}", "title pattern")
+	f.StringVar(&itemTemplate.Secondary, "details", "", "details")
+	f.StringVar(&itemTemplate.Note, "note", "", "note")
+	f.IntVar(&itemTemplate.Priority, "priority", 2, "priority, 1 (high) to 4 (idle)")
+	f.StringVar(&itemTemplate.Color, "color", "", "color")
+	f.StringVar(&itemTemplate.Due, "due", "", "due date offset, e.g. +2d")
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+)
+
+var (
+	listMode     string
+	listAllModes bool
+	listAssignee string
+	listLane     string
+	listWaiting  bool
+	listSearch   string
+)
+
+// estimateUnit returns the unit of estimates configured for a mode, empty if
+// the settings can not be read.
+func estimateUnit(home, mode string) string {
+	s, err := config.LoadSettings(home, mode)
+	if err != nil {
+		return ""
+	}
+	return s.EstimateUnit
+}
+
+// printList prints the tasks of a board per lane, optionally only the tasks
+// of one lane (lane >= 0), of an assignee, the waiting ones or the ones
+// matching a search text, see model.Item.Matches. The lanes show
+// the sum of the estimates of the tasks listed. Lanes without tasks to show
+// are left out.
+func printList(w io.Writer, mode string, c *model.ToDoContent, lane int, assignee string, waiting bool, search string) {
+	fmt.Fprintln(w, mode)
+	for i, title := range c.Titles {
+		if lane >= 0 && i != lane {
+			continue
+		}
+		var lines []string
+		var sum float64
+		for _, item := range c.GetLaneItems(i) {
+			if assignee != "" && !item.AssignedTo(assignee) || waiting && !item.IsWaiting() || !item.Matches(search) {
+				continue
+			}
+			line := item.Title
+			if ref := item.Ref(); ref != "" {
+				line = ref + " " + line
+			}
+			if item.Due != "" {
+				line += " (due " + item.Due + ")"
+			}
+			if item.Estimate > 0 {
+				line += " [" + model.FormatEstimate(item.Estimate, c.EstimateUnit()) + "]"
+				sum += item.Estimate
+			}
+			if len(item.Assignees) > 0 {
+				line += " @" + strings.Join(item.Assignees, " @")
+			}
+			if wf := item.Waiting; wf != nil {
+				line += " (waiting on " + wf.On
+				if wf.FollowUp != "" {
+					line += ", follow up " + wf.FollowUp
+				}
+				line += ")"
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if sum > 0 {
+			title += " (" + model.FormatEstimate(sum, c.EstimateUnit()) + ")"
+		}
+		fmt.Fprintf(w, "  %v:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(w, "    %v\n", line)
+		}
+	}
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the tasks of a mode",
+	Long: `lists the tasks of a mode (default: the last used mode) per lane, or with
+--all-modes of all modes. --assignee shows only the tasks assigned to a user,
+"me" is the current user, --waiting only the tasks waiting on someone and
+--search only the tasks with the number (42 or #42) or with the text in the
+title, note or tags.`,
+	Example: `  todo list --assignee me
+  todo list --mode work --lane Doing
+  todo list --all-modes --assignee alice
+  todo list --all-modes --waiting
+  todo list --search '#42'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		assignee := listAssignee
+		if assignee == "me" {
+			assignee = usr.Username
+		}
+		store := newModeStore(usr.HomeDir)
+		var modes []string
+		if listAllModes {
+			if modes, err = store.Modes(); err != nil {
+				return err
+			}
+		} else {
+			mode, err := lastMode(usr.HomeDir, listMode)
+			if err != nil {
+				return err
+			}
+			if !store.Exists(mode) {
+				return fmt.Errorf("mode '%v' not found", mode)
+			}
+			modes = []string{mode}
+		}
+		for i, mode := range modes {
+			c, err := store.Open(mode)
+			if err != nil {
+				return fmt.Errorf("mode '%v': %w", mode, err)
+			}
+			c.SetEstimateUnit(estimateUnit(usr.HomeDir, mode))
+			lane := -1
+			if listLane != "" {
+				if lane, err = laneIndex(c, listLane); err != nil {
+					if listAllModes {
+						continue
+					}
+					return err
+				}
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			printList(os.Stdout, mode, c, lane, assignee, listWaiting, listSearch)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	f := listCmd.Flags()
+	f.StringVarP(&listMode, "mode", "m", "", "mode of the tasks (default: the last used mode)")
+	f.BoolVarP(&listAllModes, "all-modes", "a", false, "list all modes")
+	f.StringVar(&listAssignee, "assignee", "", `show only the tasks assigned to this user, "me" for the current user`)
+	f.BoolVar(&listWaiting, "waiting", false, "show only the tasks waiting on someone")
+	f.StringVarP(&listLane, "lane", "l", "", "show only the tasks of this lane (title or number)")
+	f.StringVar(&listSearch, "search", "", "show only the tasks with this number or text")
+}
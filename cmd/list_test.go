@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestPrintList(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "2024-05-08", "")
+	c.AddItem(0, 1, "deploy", "", 2, "", "")
+	c.AddItem(1, 0, "docs", "", 2, "", "")
+	c.Items[0][0].Assignees = []string{"ann", "bob"}
+	c.Items[1][0].Assignees = []string{"bob"}
+	c.Items[1][0].Estimate = 3
+
+	var buf bytes.Buffer
+	printList(&buf, "work", c, -1, "ann", false, "")
+	if want := "work\n  To Do:\n    #1 review (due 2024-05-08) @ann @bob\n"; buf.String() != want {
+		t.Fatalf("list of ann:\n%v", buf.String())
+	}
+
+	buf.Reset()
+	printList(&buf, "work", c, 1, "", false, "")
+	if want := "work\n  Doing (3pt):\n    #3 docs [3pt] @bob\n"; buf.String() != want {
+		t.Fatalf("list of lane:\n%v", buf.String())
+	}
+
+	c.Items[0][1].Waiting = &model.WaitingFor{On: "ops", Since: "2024-05-06", FollowUp: "2024-05-09"}
+	buf.Reset()
+	printList(&buf, "work", c, -1, "", true, "")
+	if want := "work\n  To Do:\n    #2 deploy (waiting on ops, follow up 2024-05-09)\n"; buf.String() != want {
+		t.Fatalf("list of waiting:\n%v", buf.String())
+	}
+
+	buf.Reset()
+	printList(&buf, "work", c, -1, "", false, "#3")
+	if want := "work\n  Doing (3pt):\n    #3 docs [3pt] @bob\n"; buf.String() != want {
+		t.Fatalf("list of search:\n%v", buf.String())
+	}
+}
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/flytam/filenamify"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+)
+
+var (
+	mergeUnmatched  string
+	newModeTemplate string
+	templateMode    string
+	templateItems   bool
+)
+
+// templatesDir returns the directory of the user board templates.
+func templatesDir(home string) string {
+	return path.Join(home, ".todo", "templates")
+}
+
+// modeName converts a mode given on the command line to the name of its
+// directory.
+func modeName(arg string) (string, error) {
+	if arg == "main" {
+		return arg, nil
+	}
+	return filenamify.FilenamifyV2(arg, func(options *filenamify.Options) {
+		options.Replacement = "_"
+	})
+}
+
+func modeNames(args []string) ([]string, error) {
+	res := make([]string, len(args))
+	for i, a := range args {
+		n, err := modeName(a)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = n
+	}
+	return res, nil
+}
+
+// lastMode returns the mode given on the command line or, if arg is empty,
+// the mode used last.
+func lastMode(home, arg string) (string, error) {
+	if arg != "" {
+		return modeName(arg)
+	}
+	if m, err := config.LoadLastModeFromSettings(home); err == nil && len(m) > 0 {
+		return m, nil
+	}
+	return "main", nil
+}
+
+func localModeStore() (*model.ModeStore, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return nil, err
+	}
+	return newModeStore(usr.HomeDir), nil
+}
+
+var modeCmd = &cobra.Command{
+	Use:   "mode",
+	Short: "manage modes",
+	Long: `creates, removes, renames or merges modes. Running instances showing a changed
+mode close it and switch to the mode which replaced it.`,
+}
+
+var modeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list all modes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := localModeStore()
+		if err != nil {
+			return err
+		}
+		modes, err := store.Modes()
+		if err != nil {
+			return err
+		}
+		for _, m := range modes {
+			fmt.Println(m)
+		}
+		return nil
+	},
+}
+
+var modeNewCmd = &cobra.Command{
+	Use:   "new <mode>",
+	Short: "create a mode, optionally from a board template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := modeNames(args)
+		if err != nil {
+			return err
+		}
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		templates, err := model.LoadTemplates(templatesDir(usr.HomeDir))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		t := model.FindTemplate(templates, newModeTemplate)
+		if t == nil {
+			return fmt.Errorf("unknown template '%v', use one of: %v", newModeTemplate, strings.Join(model.TemplateNames(templates), ", "))
+		}
+		return newModeStore(usr.HomeDir).Create(names[0], t)
+	},
+}
+
+var modeTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "list the board templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		templates, err := model.LoadTemplates(templatesDir(usr.HomeDir))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		for _, t := range templates {
+			titles := make([]string, len(t.Lanes))
+			for i, lane := range t.Lanes {
+				titles[i] = lane.Title
+			}
+			fmt.Printf("%-16s %v\n", t.Name, strings.Join(titles, ", "))
+		}
+		return nil
+	},
+}
+
+var modeSaveTemplateCmd = &cobra.Command{
+	Use:   "save-template <template>",
+	Short: "save the lanes of a mode as board template",
+	Long: `saves the lanes of a mode (default: main) with their colors, sort modes and WIP
+limits as template in ~/.todo/templates. With --items the tasks are included.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, err := modeName(templateMode)
+		if err != nil {
+			return err
+		}
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		store := newModeStore(usr.HomeDir)
+		if !store.Exists(mode) {
+			return fmt.Errorf("mode '%v' not found", mode)
+		}
+		content, err := store.Open(mode)
+		if err != nil {
+			return err
+		}
+		return model.SaveTemplate(templatesDir(usr.HomeDir), model.TemplateFromBoard(args[0], content, templateItems))
+	},
+}
+
+var modeRmCmd = &cobra.Command{
+	Use:   "rm <mode>",
+	Short: "remove a mode, its tasks are moved to the archive of the main mode",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := modeNames(args)
+		if err != nil {
+			return err
+		}
+		store, err := localModeStore()
+		if err != nil {
+			return err
+		}
+		return store.Remove(names[0])
+	},
+}
+
+var modeRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "rename a mode",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := modeNames(args)
+		if err != nil {
+			return err
+		}
+		store, err := localModeStore()
+		if err != nil {
+			return err
+		}
+		return store.Rename(names[0], names[1])
+	},
+}
+
+var modeMergeCmd = &cobra.Command{
+	Use:   "merge <source> <target>",
+	Short: "move all tasks of a mode into another mode and remove it",
+	Long: `moves all tasks of the source mode into the lanes of the target mode with the
+same title and removes the source mode. For lanes without match in the target
+mode, --unmatched decides whether a new lane is added ("new") or the tasks are
+archived ("archive"). Without the flag you are asked for each such lane.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := modeNames(args)
+		if err != nil {
+			return err
+		}
+		source, target := names[0], names[1]
+		store, err := localModeStore()
+		if err != nil {
+			return err
+		}
+		if !store.Exists(source) {
+			return fmt.Errorf("mode '%v' not found", source)
+		}
+		if !store.Exists(target) {
+			return fmt.Errorf("mode '%v' not found", target)
+		}
+		src, err := store.Open(source)
+		if err != nil {
+			return err
+		}
+		dst, err := store.Open(target)
+		if err != nil {
+			return err
+		}
+		lanes := model.MatchLanes(src.Titles, dst.Titles)
+		for i, dest := range lanes {
+			if dest != model.LaneNew || len(src.Items[i]) == 0 {
+				continue
+			}
+			if lanes[i], err = unmatchedLane(src.Titles[i], len(src.Items[i]), dst.Titles); err != nil {
+				return err
+			}
+		}
+		return store.Merge(source, target, lanes)
+	},
+}
+
+// unmatchedLane returns the target of a source lane without match in the
+// target mode, as given by --unmatched or chosen by the user.
+func unmatchedLane(title string, count int, targets []string) (int, error) {
+	switch mergeUnmatched {
+	case "new":
+		return model.LaneNew, nil
+	case "archive":
+		return model.LaneArchive, nil
+	case "":
+	default:
+		return 0, fmt.Errorf("invalid value '%v' for --unmatched, use new or archive", mergeUnmatched)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return 0, fmt.Errorf("lane '%v' has no match in the target mode, use --unmatched", title)
+	}
+
+	fmt.Printf("Lane '%v' (%v tasks) has no match in the target mode:\n", title, count)
+	for i, t := range targets {
+		fmt.Printf("  %v) move to lane '%v'\n", i+1, t)
+	}
+	fmt.Println("  n) add as new lane")
+	fmt.Println("  a) archive tasks")
+	in := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("Choice: ")
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		choice := strings.ToLower(strings.TrimSpace(line))
+		switch choice {
+		case "n":
+			return model.LaneNew, nil
+		case "a":
+			return model.LaneArchive, nil
+		}
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(targets) {
+			return n - 1, nil
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(modeCmd)
+	modeCmd.AddCommand(modeListCmd, modeNewCmd, modeTemplatesCmd, modeSaveTemplateCmd, modeRmCmd, modeRenameCmd, modeMergeCmd)
+	modeNewCmd.Flags().StringVarP(&newModeTemplate, "template", "t", model.DefaultTemplateName, "board template of the new mode")
+	modeSaveTemplateCmd.Flags().StringVarP(&templateMode, "mode", "m", "main", "mode to save as template")
+	modeSaveTemplateCmd.Flags().BoolVar(&templateItems, "items", false, "include the tasks")
+	modeMergeCmd.Flags().StringVar(&mergeUnmatched, "unmatched", "", "handling of lanes without match: new or archive")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -8,6 +9,8 @@ import (
 	"os/user"
 	"path"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/flytam/filenamify"
 	"github.com/fsnotify/fsnotify"
@@ -15,6 +18,7 @@ import (
 	"github.com/rivo/tview"
 	"github.com/spf13/cobra"
 
+	"github.com/cklukas/todo/internal/client"
 	"github.com/cklukas/todo/internal/config"
 	"github.com/cklukas/todo/internal/model"
 	"github.com/cklukas/todo/internal/ui"
@@ -36,7 +40,6 @@ var AppVersion string = ""
 
 func main(cmd *cobra.Command, args []string) error {
 	baseTodoDir := ".todo"
-	todoDir := baseTodoDir
 	mode := "main"
 
 	usr, errU := user.Current()
@@ -47,9 +50,6 @@ func main(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
 		if m, err := config.LoadLastModeFromSettings(usr.HomeDir); err == nil && len(m) > 0 {
 			mode = m
-			if mode != "main" {
-				todoDir = path.Join(baseTodoDir, "mode", mode)
-			}
 		}
 	}
 
@@ -63,127 +63,181 @@ func main(cmd *cobra.Command, args []string) error {
 			log.Fatal(err)
 		}
 
-		todoDir = path.Join(todoDirModes, saveName)
 		mode = saveName
 	}
 
-	saveName := mode
-
-	var err error
-
-	nextModeLaneFocus := 0
-	for {
-		var nextMode string
-		nextMode, nextModeLaneFocus, err = launchGui(todoDir, todoDirModes, saveName, nextModeLaneFocus)
-		if len(nextMode) == 0 {
-			break
-		}
-		// store selected mode for future runs
-		if err := config.SaveLastModeToSettings(usr.HomeDir, nextMode); err != nil {
-			log.Print(err)
-		}
-		if nextMode == "main" {
-			todoDir = baseTodoDir
-			saveName = "main"
-			continue
-		}
-		saveName, err = filenamify.FilenamifyV2(nextMode, func(options *filenamify.Options) {
-			options.Replacement = "_"
-		})
+	token := serverToken
+	if len(serverURL) > 0 && len(token) == 0 {
+		cfg, err := config.LoadServerConfig(usr.HomeDir)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		todoDir = path.Join(todoDirModes, saveName)
+		token = cfg.Token
 	}
 
-	return err
+	if len(serverURL) > 0 {
+		return launchRemoteGui(serverURL, token, mode)
+	}
+	return launchGui(todoDirModes, mode)
 }
 
-func getStatusBar(lanes *ui.Lanes, mode string) *tview.Flex {
-	bAbout := tview.NewButton("[brown::-]F1 [black::-]About")
-	bAbout.SetBackgroundColor(tcell.ColorLightGray)
-	bAbout.SetSelectedFunc(lanes.CmdAbout)
-
-	bAddToDo := tview.NewButton("[red::-]F2 [black::-]Add Task")
-	bAddToDo.SetBackgroundColor(tcell.ColorLightGray)
-	bAddToDo.SetSelectedFunc(lanes.CmdAddTask)
-
-	bEditToDo := tview.NewButton("[red::-]F3 [black::-]Edit")
-	bEditToDo.SetBackgroundColor(tcell.ColorLightGray)
-	bEditToDo.SetSelectedFunc(lanes.CmdEditTask)
-
-	bNoteToDo := tview.NewButton("[red::-]F4 [black::-]Note")
-	bNoteToDo.SetBackgroundColor(tcell.ColorLightGray)
-	bNoteToDo.SetSelectedFunc(lanes.CmdEditNote)
-
-	bArchiveToDo := tview.NewButton("[red::-]F5 [black::-]Archive")
-	bArchiveToDo.SetBackgroundColor(tcell.ColorLightGray)
-	bArchiveToDo.SetSelectedFunc(lanes.CmdArchiveNote)
-
-	bSelectToDo := tview.NewButton("[red::-]F6 [black::-]Select")
-	bSelectToDo.SetBackgroundColor(tcell.ColorLightGray)
-	bSelectToDo.SetSelectedFunc(lanes.CmdSelectNote)
+// statusButton is a button of the status bar, showing a key and the
+// description of its command.
+type statusButton struct {
+	key     string
+	text    string
+	mainKey bool
+	button  *tview.Button
+}
 
-	bLanesCommands := tview.NewButton("[red::-]F7 [black::-]Lane")
-	bLanesCommands.SetBackgroundColor(tcell.ColorLightGray)
-	bLanesCommands.SetSelectedFunc(lanes.CmdLanesCmds)
+func (b statusButton) label(t *ui.Theme) string {
+	keyColor := t.StatusKey
+	if b.mainKey {
+		keyColor = t.StatusMainKey
+	}
+	return "[" + keyColor + "::-]" + b.key + " [" + t.StatusText + "::-]" + b.text
+}
 
-	bExit := tview.NewButton("[brown::-]F10 [black::-]Exit")
-	bExit.SetBackgroundColor(tcell.ColorLightGray)
-	bExit.SetSelectedFunc(lanes.CmdExit)
+func getStatusBar(lanes *ui.Lanes, mode string) *tview.Flex {
+	buttons := []statusButton{
+		{"F1", "About", true, tview.NewButton("").SetSelectedFunc(lanes.CmdAbout)},
+		{"F2", "Add Task", false, tview.NewButton("").SetSelectedFunc(lanes.CmdAddTask)},
+		{"F3", "Edit", false, tview.NewButton("").SetSelectedFunc(lanes.CmdEditTask)},
+		{"F4", "Note", false, tview.NewButton("").SetSelectedFunc(lanes.CmdEditNote)},
+		{"F5", "Archive", false, tview.NewButton("").SetSelectedFunc(lanes.CmdArchiveNote)},
+		{"F6", "Select", false, tview.NewButton("").SetSelectedFunc(lanes.CmdSelectNote)},
+		{"F7", "Lane", false, tview.NewButton("").SetSelectedFunc(lanes.CmdLanesCmds)},
+		{"F10", "Exit", true, tview.NewButton("").SetSelectedFunc(lanes.CmdExit)},
+	}
+	widths := []int{10, 13, 9, 9, 13, 10, 9, 10}
 
-	bMode := tview.NewButton("[blue::-]" + mode)
-	bMode.SetBackgroundColor(tcell.ColorLightGray)
+	bMode := tview.NewButton("")
 	bMode.SetSelectedFunc(lanes.CmdSelectModeDialog)
+	current := mode
 
 	bMoveHelp := tview.NewButton("")
-	bMoveHelp.SetBackgroundColor(tcell.ColorLightGray)
 	lanes.SetMoveHelpButton(bMoveHelp)
 
 	tvClock := tview.NewTextView()
-	tvClock.SetBackgroundColor(tcell.ColorLightGray)
 	tvClock.SetTextAlign(tview.AlignRight)
 	lanes.SetClock(tvClock)
 
-	defaultStatusBarMenuItems := tview.NewFlex().SetDirection(tview.FlexColumn).
-		AddItem(bAbout, 10, 1, false).
-		AddItem(bAddToDo, 13, 1, false).
-		AddItem(bEditToDo, 9, 1, false).
-		AddItem(bNoteToDo, 9, 1, false).
-		AddItem(bArchiveToDo, 13, 1, false).
-		AddItem(bSelectToDo, 10, 1, false).
-		AddItem(bLanesCommands, 9, 1, false).
-		AddItem(bExit, 10, 1, false).
+	defaultStatusBarMenuItems := tview.NewFlex().SetDirection(tview.FlexColumn)
+	for i, b := range buttons {
+		defaultStatusBarMenuItems.AddItem(b.button, widths[i], 1, false)
+	}
+	defaultStatusBarMenuItems.
 		AddItem(bMode, 2+len(mode), 1, false).
 		AddItem(bMoveHelp, 38, 1, false).
 		AddItem(tvClock, 20, 1, false)
 
-	defaultStatusBarMenuItems.SetBackgroundColor(tcell.ColorLightGray)
+	lanes.AddThemeFunc(func(t *ui.Theme) {
+		bg := tcell.GetColor(t.StatusBar)
+		for _, b := range buttons {
+			b.button.SetLabel(b.label(t))
+			b.button.SetBackgroundColor(bg)
+		}
+		bMode.SetLabel("[" + t.StatusMode + "::-]" + current)
+		bMode.SetBackgroundColor(bg)
+		bMoveHelp.SetBackgroundColor(bg)
+		tvClock.SetBackgroundColor(bg)
+		tvClock.SetTextColor(tcell.GetColor(t.StatusText))
+		defaultStatusBarMenuItems.SetBackgroundColor(bg)
+	})
+	lanes.AddModeFunc(func(mode string, _ *model.ToDoContent) error {
+		current = mode
+		bMode.SetLabel("[" + lanes.Theme().StatusMode + "::-]" + mode)
+		defaultStatusBarMenuItems.ResizeItem(bMode, 2+len(mode), 1)
+		return nil
+	})
 
 	return defaultStatusBarMenuItems
 }
 
-func JsonWatcher(watcher *fsnotify.Watcher, content *model.ToDoContent, lanes *ui.Lanes, app *tview.Application) {
+// removeTimeout is the time to wait for todo.json to be recreated after it
+// was removed, before the mode is considered deleted.
+const removeTimeout = 2 * time.Second
+
+// boardWatcher holds the board monitored by JsonWatcher. It is changed when
+// the user switches modes.
+type boardWatcher struct {
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+	dir     string
+	content *model.ToDoContent
+	mode    string
+}
+
+// set monitors the directory of another board.
+func (w *boardWatcher) set(content *model.ToDoContent, mode string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.content, w.mode = content, mode
+	dir := filepath.Dir(content.FileName())
+	if dir == w.dir {
+		return nil
+	}
+	if w.dir != "" {
+		// fails if the directory was deleted, which ends the watch anyway
+		w.watcher.Remove(w.dir)
+	}
+	w.dir = dir
+	return w.watcher.Add(dir)
+}
+
+func (w *boardWatcher) current() (*model.ToDoContent, string, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.content, w.mode, w.dir
+}
+
+func JsonWatcher(w *boardWatcher, lanes *ui.Lanes, app *tview.Application, store *model.ModeStore) {
 	appLocked := false
+	unlock := func() {
+		if appLocked {
+			app.Unlock()
+			appLocked = false
+		}
+	}
+	var removed <-chan time.Time
+	// closed is the directory of a mode which was merged, renamed or
+	// removed, its remaining events are ignored
+	closed := ""
+	closeMode := func(mode, dir string) {
+		unlock()
+		removed = nil
+		closed = dir
+		next := store.MovedTo(mode)
+		app.QueueUpdateDraw(func() { lanes.CloseMode(next) })
+	}
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case event, ok := <-w.watcher.Events:
 			if !ok {
 				return
 			}
+			content, mode, dir := w.current()
+			if filepath.Dir(event.Name) != dir || dir == closed {
+				continue
+			}
+
+			if filepath.Base(event.Name) == model.MovedMarker && event.Has(fsnotify.Create) {
+				// the mode was merged, renamed or removed
+				closeMode(mode, dir)
+				continue
+			}
 
 			if filepath.Base(event.Name) == "todo.json" && (event.Has(fsnotify.Remove)) {
 				if !appLocked {
 					app.Lock()
 					appLocked = true
 				}
+				removed = time.After(removeTimeout)
 			}
 
 			if filepath.Base(event.Name) == "todo.json" && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
-				if appLocked {
-					app.Unlock()
-					appLocked = false
-				}
+				unlock()
+				removed = nil
 
 				err := content.Read()
 				if err != nil {
@@ -191,10 +245,22 @@ func JsonWatcher(watcher *fsnotify.Watcher, content *model.ToDoContent, lanes *u
 					log.Fatal(err)
 				}
 
-				lanes.RedrawAllLanes()
-				app.ForceDraw()
+				app.QueueUpdateDraw(func() {
+					if lanes.Content() == content {
+						lanes.RedrawAllLanes()
+					}
+				})
 			}
-		case err, ok := <-watcher.Errors:
+		case <-removed:
+			removed = nil
+			content, mode, dir := w.current()
+			if _, err := os.Stat(content.FileName()); errors.Is(err, os.ErrNotExist) {
+				// the mode directory was deleted
+				closeMode(mode, dir)
+				continue
+			}
+			unlock()
+		case err, ok := <-w.watcher.Errors:
 			if !ok {
 				return
 			}
@@ -205,58 +271,184 @@ func JsonWatcher(watcher *fsnotify.Watcher, content *model.ToDoContent, lanes *u
 	}
 }
 
-func launchGui(todoDir, todoDirModes, mode string, nextModeLaneFocus int) (string, int, error) {
+// openLocalMode opens the board of a mode, a new mode is created.
+func openLocalMode(home, mode string) (*model.ToDoContent, error) {
+	content, err := openMode(home, mode)
+	if err != nil {
+		return nil, fmt.Errorf("could not open mode '%v': %w", mode, err)
+	}
+	if err := content.Save(); err != nil {
+		return nil, fmt.Errorf("could not save todos in '%v': %w", content.FileName(), err)
+	}
+	return content, nil
+}
+
+func launchGui(todoDirModes, mode string) error {
 	usr, errU := user.Current()
 	if errU != nil {
 		log.Fatal(errU)
 	}
 
-	archiveDir, err := CreateDir(path.Join(usr.HomeDir, todoDir, "archive"))
+	content, err := openLocalMode(usr.HomeDir, mode)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	backupDir, err := CreateDir(path.Join(usr.HomeDir, todoDir, "backup"))
-	if err != nil {
-		log.Fatal(err)
+	return runGui(content, mode, path.Join(usr.HomeDir, todoDirModes), func(lanes *ui.Lanes, app *tview.Application) func() {
+		store := newModeStore(usr.HomeDir)
+		lanes.SetModeStore(store)
+		templates, err := model.LoadTemplates(templatesDir(usr.HomeDir))
+		if err != nil {
+			log.Printf("could not load templates: %v", err)
+		}
+		lanes.SetTemplates(templates)
+		lanes.SetModeOpener(func(mode string) (c *model.ToDoContent, err error) {
+			open := func() { c, err = openLocalMode(usr.HomeDir, mode) }
+			if !passphraseRequired(usr.HomeDir, mode) {
+				open()
+				return c, err
+			}
+			// ask for the passphrase on the terminal
+			app.Suspend(func() {
+				passphrasePrompt = true
+				open()
+				passphrasePrompt = false
+			})
+			return c, err
+		})
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := &boardWatcher{watcher: watcher}
+
+		// monitor changes to todo.json in background
+		go JsonWatcher(w, lanes, app, store)
+
+		// watch the directory of the active mode
+		lanes.AddModeFunc(func(mode string, content *model.ToDoContent) error {
+			return w.set(content, mode)
+		})
+		return func() { watcher.Close() }
+	})
+}
+
+// openRemoteMode loads the board of a mode served by "todo serve", a new
+// board is created for unknown modes.
+func openRemoteMode(cl *client.Client) (*model.ToDoContent, error) {
+	content := new(model.ToDoContent)
+	content.SetRemote(cl)
+	err := content.Read()
+	if errors.Is(err, client.ErrNotFound) {
+		content.InitializeNew()
+		err = content.Save()
 	}
+	return content, err
+}
 
-	_, err = CreateDir(path.Join(usr.HomeDir, todoDir, "mode"))
+// launchRemoteGui shows the board of a mode served by "todo serve". Instead
+// of monitoring todo.json, change events of the server trigger reloads.
+func launchRemoteGui(serverURL, token, mode string) error {
+	cl := client.New(serverURL, token, mode)
+	content, err := openRemoteMode(cl)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatal(fmt.Errorf("could not load mode '%v' from '%v': %w", mode, serverURL, err))
 	}
 
-	fname := path.Join(usr.HomeDir, todoDir, "todo.json")
+	return runGui(content, mode, "", func(lanes *ui.Lanes, app *tview.Application) func() {
+		lanes.SetModeLister(cl.Modes)
+		lanes.SetModeOpener(func(mode string) (*model.ToDoContent, error) {
+			return openRemoteMode(client.New(serverURL, token, mode))
+		})
 
-	content := new(model.ToDoContent)
-	err = content.ReadFromFile(fname)
+		cancel := func() {}
+		lanes.AddModeFunc(func(mode string, content *model.ToDoContent) error {
+			cancel()
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go client.New(serverURL, token, mode).Watch(ctx, func() {
+				if err := content.Read(); err != nil {
+					return
+				}
+				app.QueueUpdateDraw(func() {
+					if lanes.Content() == content {
+						lanes.RedrawAllLanes()
+					}
+				})
+			})
+			return nil
+		})
+		return func() { cancel() }
+	})
+}
+
+// loadSettings offers the built-in and user themes, applies the settings of
+// the active mode and stores the settings changed by the user.
+func loadSettings(lanes *ui.Lanes) {
+	usr, err := user.Current()
 	if err != nil {
-		content.InitializeNew()
+		return
 	}
-
-	content.SetFileName(fname, archiveDir, backupDir)
-	err = content.Save()
+	themes, err := ui.LoadThemes(path.Join(usr.HomeDir, ".todo", "themes"))
 	if err != nil {
-		log.Fatal(fmt.Errorf("could not save todos in '%v': %w", fname, err))
+		log.Printf("could not load themes: %v", err)
 	}
+	lanes.SetThemes(themes)
+	lanes.SetThemeChangedFunc(func(name string) {
+		if err := config.SetSetting(usr.HomeDir, "", "theme", name); err != nil {
+			log.Print(err)
+		}
+	})
+	lanes.SetSettingsChangedFunc(func(values map[string]string, modeOnly bool) error {
+		settingsMode := ""
+		if modeOnly {
+			settingsMode = lanes.Mode()
+		}
+		for key, value := range values {
+			if err := config.SetSetting(usr.HomeDir, settingsMode, key, value); err != nil {
+				return err
+			}
+		}
+		settings, err := config.LoadSettings(usr.HomeDir, lanes.Mode())
+		if err != nil {
+			return err
+		}
+		return lanes.ApplySettings(settings)
+	})
 
+	lanes.AddModeFunc(func(mode string, _ *model.ToDoContent) error {
+		settings, err := config.LoadSettings(usr.HomeDir, mode)
+		if err != nil {
+			err = fmt.Errorf("could not read settings: %w", err)
+		}
+		if errApply := lanes.ApplySettings(settings); errApply != nil && err == nil {
+			err = errApply
+		}
+		return err
+	})
+}
+
+// runGui runs the application for the given board until it is stopped. The
+// watch function starts monitoring external changes and returns a function
+// stopping it.
+func runGui(content *model.ToDoContent, mode, todoDirModes string, watch func(lanes *ui.Lanes, app *tview.Application) func()) error {
 	app := tview.NewApplication()
-	lanes := ui.NewLanes(content, app, mode, path.Join(usr.HomeDir, todoDirModes), AppVersion)
+	lanes := ui.NewLanes(content, app, mode, todoDirModes, AppVersion)
 
-	// lanes.active = nextModeLaneFocus
-	// lanes.lastActive = nextModeLaneFocus
+	defaultStatusBarMenuItems := getStatusBar(lanes, mode)
+	loadSettings(lanes)
+	lanes.FocusDefaultLane()
 
 	for idx, list := range lanes.Lists() {
 		if lanes.ActiveIndex() == idx {
-			list.SetSelectedBackgroundColor(tcell.ColorLightBlue)
-			list.SetSelectedTextColor(tcell.ColorBlack)
+			list.SetSelectedBackgroundColor(tcell.GetColor(lanes.Theme().Selection))
+			list.SetSelectedTextColor(tcell.GetColor(lanes.Theme().SelectionText))
 		} else {
 			list.SetSelectedStyle(tcell.StyleDefault)
 		}
 	}
 
-	defaultStatusBarMenuItems := getStatusBar(lanes, mode)
-
 	layout := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(lanes.GetUi(), 0, 1, true).
@@ -265,26 +457,31 @@ func launchGui(todoDir, todoDirModes, mode string, nextModeLaneFocus int) (strin
 
 	lanes.StartClock()
 
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Fatal(err)
-	}
+	stop := watch(lanes, app)
+	defer stop()
 
-	// monitor changes to todo.json in background
-	defer watcher.Close()
-	go JsonWatcher(watcher, content, lanes, app)
+	// store the selected mode for future runs
+	started := false
+	lanes.AddModeFunc(func(mode string, _ *model.ToDoContent) error {
+		if !started {
+			started = true
+			return nil
+		}
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		return config.SaveLastModeToSettings(usr.HomeDir, mode)
+	})
 
-	// watch directory
-	err = watcher.Add(filepath.Dir(fname))
+	passphrasePrompt = false
+	err := app.Run()
+	passphrasePrompt = true
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	if err := app.Run(); err != nil {
 		log.Fatalf("Error running application: %v\n", err)
 	}
 
-	return lanes.NextMode(), lanes.NextLaneFocus(), content.Save()
+	return lanes.Content().Save()
 }
 
 var rootCmd = &cobra.Command{
@@ -297,8 +494,12 @@ var rootCmd = &cobra.Command{
 	Args:         cobra.RangeArgs(0, 1),
 }
 
+var serverURL string
+var serverToken string
+
 func init() {
-	// empty
+	rootCmd.Flags().StringVar(&serverURL, "server", "", "URL of a 'todo serve' instance to use instead of the local files")
+	rootCmd.Flags().StringVar(&serverToken, "token", "", "access token for --server (default: token from ~/.todo/server.json)")
 }
 
 func Execute() {
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os/user"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/server"
+)
+
+var serveListen string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "share the boards over HTTP",
+	Long: `serves all modes over a REST and server-sent-events API, so other instances
+can connect with 'todo --server URL'. The access token and the listen address
+are read from ~/.todo/server.json, e.g. {"listen": ":8080", "token": "secret"}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.LoadServerConfig(usr.HomeDir)
+		if err != nil {
+			return err
+		}
+		if len(cfg.Token) == 0 {
+			return errors.New("no access token configured in ~/.todo/server.json")
+		}
+		if len(serveListen) > 0 {
+			cfg.Listen = serveListen
+		}
+
+		srv := server.New(usr.HomeDir, cfg.Token)
+		srv.SetCipherFunc(func(mode string) (model.Cipher, error) {
+			return modeCipher(usr.HomeDir, mode)
+		})
+		defer srv.Close()
+		fmt.Printf("Serving boards on %v\n", cfg.Listen)
+		return http.ListenAndServe(cfg.Listen, srv.Handler())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&serveListen, "listen", "l", "", "listen address (default from ~/.todo/server.json or ':8080')")
+}
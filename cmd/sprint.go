@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+var (
+	sprintMode    string
+	sprintDays    int
+	sprintStart   string
+	sprintLanes   []string
+	sprintNext    string
+	sprintBacklog string
+	sprintFormat  string
+)
+
+// printSprintReport writes the progress of a sprint per day and its tasks as
+// text, or the report as JSON.
+func printSprintReport(w io.Writer, r model.SprintReport, format string) error {
+	switch format {
+	case "text":
+		// tasks are counted without unit in the table
+		unit, suffix := r.Unit, ""
+		if unit == "tasks" {
+			unit, suffix = "", " tasks"
+		}
+		amount := func(v float64) string {
+			return model.FormatEstimate(math.Round(v*10)/10, unit)
+		}
+		fmt.Fprintf(w, "sprint %v (%v - %v", r.Name, r.Start, r.End)
+		if r.Closed != "" {
+			fmt.Fprintf(w, ", closed %v", r.Closed)
+		}
+		fmt.Fprintf(w, ")\nscope %v, done %v, remaining %v%v\n\n", amount(r.Scope), amount(r.Done), amount(r.Remaining), suffix)
+		fmt.Fprintf(w, "%-10s  %9s  %9s  %9s  %9s\n", "date", "scope", "done", "remaining", "ideal")
+		for _, d := range r.Days {
+			fmt.Fprintf(w, "%-10s  %9v  %9v  %9v  %9v\n", d.Date, amount(d.Scope), amount(d.Done), amount(d.Remaining), amount(d.Ideal))
+		}
+		fmt.Fprintln(w)
+		for _, task := range r.Tasks {
+			done := task.Done
+			if done == "" {
+				done = "-"
+			}
+			estimate := ""
+			if task.Estimate > 0 {
+				estimate = " [" + amount(task.Estimate) + "]"
+			}
+			fmt.Fprintf(w, "%-10s  %v%v (%v)\n", done, task.Title, estimate, task.Lane)
+		}
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(r)
+	default:
+		return fmt.Errorf("invalid format '%v', use text or json", format)
+	}
+	return nil
+}
+
+// sprintBoard returns the store and the mode of the sprint commands.
+func sprintBoard() (*model.ModeStore, string, string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return nil, "", "", err
+	}
+	mode, err := lastMode(usr.HomeDir, sprintMode)
+	if err != nil {
+		return nil, "", "", err
+	}
+	store := newModeStore(usr.HomeDir)
+	if !store.Exists(mode) {
+		return nil, "", "", fmt.Errorf("mode '%v' not found", mode)
+	}
+	return store, mode, usr.HomeDir, nil
+}
+
+var sprintCmd = &cobra.Command{
+	Use:   "sprint",
+	Short: "start, close and report sprints",
+	Long: `a sprint commits tasks of a mode for a fixed time. Tasks in the last lane or
+archived are finished. 'S' shows the burndown chart of the sprint in the
+program.`,
+}
+
+var sprintStartCmd = &cobra.Command{
+	Use:   "start <name>",
+	Short: "start a sprint",
+	Long: `starts a sprint of a mode (default: the last used mode). The tasks of the given
+lanes are committed to it, by default all tasks not in the last lane.`,
+	Example: `  todo sprint start "Sprint 12"
+  todo sprint start "Sprint 12" --days 10 --lane "Sprint Backlog"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, mode, _, err := sprintBoard()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		start, err := parseSince(sprintStart, now)
+		if err != nil {
+			return err
+		}
+		if sprintDays < 1 {
+			return fmt.Errorf("invalid number of days %v", sprintDays)
+		}
+		var count int
+		err = store.Update(mode, func(c *model.ToDoContent) error {
+			var lanes []int
+			for _, arg := range sprintLanes {
+				lane, err := laneIndex(c, arg)
+				if err != nil {
+					return err
+				}
+				lanes = append(lanes, lane)
+			}
+			guids := c.OpenTasks(lanes...)
+			count = len(guids)
+			return c.StartSprint(args[0], start, start.AddDate(0, 0, sprintDays-1), guids)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("sprint '%v' started with %v tasks\n", args[0], count)
+		return nil
+	},
+}
+
+var sprintCloseCmd = &cobra.Command{
+	Use:   "close",
+	Short: "close the running sprint",
+	Long: `closes the running sprint of a mode (default: the last used mode). Unfinished
+tasks are committed to the next sprint given with --next, which starts today,
+or else moved to the backlog lane (default: the first lane).`,
+	Example: `  todo sprint close --next "Sprint 13"
+  todo sprint close --backlog Backlog`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, mode, _, err := sprintBoard()
+		if err != nil {
+			return err
+		}
+		if sprintNext != "" && sprintDays < 1 {
+			return fmt.Errorf("invalid number of days %v", sprintDays)
+		}
+		now := time.Now()
+		var open []string
+		err = store.Update(mode, func(c *model.ToDoContent) error {
+			backlog := -1
+			if sprintNext == "" && sprintBacklog == "" {
+				backlog = 0
+			} else if sprintNext == "" {
+				lane, err := laneIndex(c, sprintBacklog)
+				if err != nil {
+					return err
+				}
+				backlog = lane
+			}
+			var err error
+			if open, err = c.CloseSprint(now, backlog); err != nil {
+				return err
+			}
+			if sprintNext != "" {
+				today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+				return c.StartSprint(sprintNext, today, today.AddDate(0, 0, sprintDays-1), open)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if sprintNext != "" {
+			fmt.Printf("sprint closed, %v unfinished tasks moved to sprint '%v'\n", len(open), sprintNext)
+		} else {
+			fmt.Printf("sprint closed, %v unfinished tasks moved to the backlog\n", len(open))
+		}
+		return nil
+	},
+}
+
+var sprintReportCmd = &cobra.Command{
+	Use:   "report [name]",
+	Short: "show the progress of a sprint",
+	Long: `shows the work committed, done and remaining per day of a sprint (default: the
+running or the last sprint) and its tasks. The work is measured in the
+estimates of the tasks, or in tasks if none is estimated.`,
+	Example: `  todo sprint report
+  todo sprint report "Sprint 12" --format json`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, mode, home, err := sprintBoard()
+		if err != nil {
+			return err
+		}
+		c, err := store.Open(mode)
+		if err != nil {
+			return err
+		}
+		c.SetEstimateUnit(estimateUnit(home, mode))
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		}
+		r, err := c.SprintReport(name, time.Now())
+		if err != nil {
+			return err
+		}
+		return printSprintReport(os.Stdout, r, sprintFormat)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sprintCmd)
+	sprintCmd.AddCommand(sprintStartCmd, sprintCloseCmd, sprintReportCmd)
+	sprintCmd.PersistentFlags().StringVarP(&sprintMode, "mode", "m", "", "mode of the sprint (default: the last used mode)")
+	f := sprintStartCmd.Flags()
+	f.IntVar(&sprintDays, "days", 14, "length of the sprint in days")
+	f.StringVar(&sprintStart, "start", "today", "start of the sprint: a date (yyyy-mm-dd) or today")
+	f.StringSliceVarP(&sprintLanes, "lane", "l", nil, "lanes with the tasks to commit (default: all but the last lane)")
+	f = sprintCloseCmd.Flags()
+	f.StringVar(&sprintNext, "next", "", "name of the next sprint, started with the unfinished tasks")
+	f.IntVar(&sprintDays, "days", 14, "length of the next sprint in days")
+	f.StringVar(&sprintBacklog, "backlog", "", "lane the unfinished tasks are moved to without next sprint (default: the first lane)")
+	sprintReportCmd.Flags().StringVar(&sprintFormat, "format", "text", "output format: text or json")
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestPrintSprintReport(t *testing.T) {
+	r := model.SprintReport{
+		Name: "s1", Start: "2024-05-06", End: "2024-05-10", Unit: "pt",
+		Scope: 8, Done: 3, Remaining: 5, Length: 5,
+		Tasks: []model.SprintTask{
+			{Title: "review", Lane: "Done", Estimate: 3, Done: "2024-05-07"},
+			{Title: "deploy", Lane: "Doing", Estimate: 5},
+		},
+		Days: []model.SprintDay{
+			{Date: "2024-05-06", Scope: 8, Remaining: 8, Ideal: 8},
+			{Date: "2024-05-07", Scope: 8, Done: 3, Remaining: 5, Ideal: 6},
+		},
+	}
+	var buf bytes.Buffer
+	if err := printSprintReport(&buf, r, "text"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"scope 8pt, done 3pt, remaining 5pt\n", "2024-05-07        8pt        3pt        5pt        6pt\n",
+		"2024-05-07  review [3pt] (Done)\n", "-           deploy [5pt] (Doing)\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%v", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := printSprintReport(&buf, r, "json"); err != nil || !strings.Contains(buf.String(), `"remaining": 5`) {
+		t.Fatalf("json %v: %v", err, buf.String())
+	}
+	if err := printSprintReport(&buf, r, "csv"); err == nil {
+		t.Fatal("expected error for invalid format")
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+var (
+	timeMode     string
+	timeAllModes bool
+	timeSince    string
+	timeBy       string
+	timeFormat   string
+)
+
+// parseSince returns the start of a report: a date, "today", or a number of
+// days, weeks or months before today like 7d, 2w or 1m. An empty text is no
+// limit.
+func parseSince(text string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case text == "":
+		return time.Time{}, nil
+	case text == "today":
+		return today, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", text, now.Location()); err == nil {
+		return t, nil
+	}
+	if len(text) > 1 {
+		if n, err := strconv.Atoi(text[:len(text)-1]); err == nil && n >= 0 {
+			switch text[len(text)-1] {
+			case 'd':
+				return today.AddDate(0, 0, -n), nil
+			case 'w':
+				return today.AddDate(0, 0, -7*n), nil
+			case 'm':
+				return today.AddDate(0, -n, 0), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid start '%v', use a date (yyyy-mm-dd), today, or e.g. 7d, 2w or 1m", text)
+}
+
+// printTimeReport writes the totals as a table, as CSV or as JSON.
+func printTimeReport(w io.Writer, totals []model.TimeTotal, by, format string) error {
+	var total time.Duration
+	for _, t := range totals {
+		total += t.Duration
+	}
+	hours := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+	}
+	switch format {
+	case "text":
+		for _, t := range totals {
+			fmt.Fprintf(w, "%-40s %8s\n", t.Group, model.FormatDuration(t.Duration))
+		}
+		fmt.Fprintf(w, "%-40s %8s\n", "total", model.FormatDuration(total))
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{by, "hours"})
+		for _, t := range totals {
+			cw.Write([]string{t.Group, hours(t.Duration)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		type group struct {
+			Group   string  `json:"group"`
+			Hours   float64 `json:"hours"`
+			Seconds int64   `json:"seconds"`
+		}
+		res := struct {
+			By     string  `json:"by"`
+			Groups []group `json:"groups"`
+			Hours  float64 `json:"hours"`
+		}{By: by, Groups: []group{}, Hours: total.Hours()}
+		for _, t := range totals {
+			res.Groups = append(res.Groups, group{Group: t.Group, Hours: t.Duration.Hours(), Seconds: int64(t.Duration.Seconds())})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(res)
+	default:
+		return fmt.Errorf("invalid format '%v', use text, csv or json", format)
+	}
+	return nil
+}
+
+var timeCmd = &cobra.Command{
+	Use:   "time",
+	Short: "report the time tracked for tasks",
+	Long: `time is tracked per task in the program: 'T' starts or stops the timer of the
+current task, 'w' edits its time entries.`,
+}
+
+var timeReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "show the time tracked per task, lane, day or tag",
+	Long: `shows the time tracked for the tasks of a mode (default: the last used mode),
+or with --all-modes of all modes, including archived tasks. Running timers
+count until now.`,
+	Example: `  todo time report --since 2026-10-01 --by day
+  todo time report --since 1m --by tag --format csv
+  todo time report --all-modes --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usr, err := user.Current()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		since, err := parseSince(timeSince, now)
+		if err != nil {
+			return err
+		}
+		store := newModeStore(usr.HomeDir)
+		var modes []string
+		if timeAllModes {
+			if modes, err = store.Modes(); err != nil {
+				return err
+			}
+		} else {
+			mode, err := lastMode(usr.HomeDir, timeMode)
+			if err != nil {
+				return err
+			}
+			if !store.Exists(mode) {
+				return fmt.Errorf("mode '%v' not found", mode)
+			}
+			modes = []string{mode}
+		}
+
+		var records []model.TimeRecord
+		for _, mode := range modes {
+			c, err := store.Open(mode)
+			if err != nil {
+				return fmt.Errorf("mode '%v': %w", mode, err)
+			}
+			name := ""
+			if len(modes) > 1 {
+				name = mode
+			}
+			r, err := c.TimeRecords(name, since, now)
+			if err != nil {
+				return err
+			}
+			records = append(records, r...)
+		}
+		by := strings.ToLower(timeBy)
+		totals, err := model.GroupTime(records, by)
+		if err != nil {
+			return err
+		}
+		return printTimeReport(os.Stdout, totals, by, timeFormat)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(timeCmd)
+	timeCmd.AddCommand(timeReportCmd)
+	f := timeReportCmd.Flags()
+	f.StringVarP(&timeMode, "mode", "m", "", "mode of the tasks (default: the last used mode)")
+	f.BoolVarP(&timeAllModes, "all-modes", "a", false, "report all modes")
+	f.StringVar(&timeSince, "since", "", "start of the report: a date (yyyy-mm-dd), today, or e.g. 7d, 2w or 1m (default: all)")
+	f.StringVar(&timeBy, "by", model.GroupByItem, "grouping: "+strings.Join(model.TimeGroupings, ", "))
+	f.StringVar(&timeFormat, "format", "text", "output format: text, csv or json")
+}
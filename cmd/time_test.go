@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 8, 15, 30, 0, 0, time.UTC)
+	for text, want := range map[string]time.Time{
+		"":           {},
+		"today":      time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC),
+		"2024-04-30": time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+		"3d":         time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC),
+		"2w":         time.Date(2024, 4, 24, 0, 0, 0, 0, time.UTC),
+		"1m":         time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC),
+	} {
+		got, err := parseSince(text, now)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("%q: %v %v", text, got, err)
+		}
+	}
+	for _, invalid := range []string{"yesterday", "d", "-1d", "3y"} {
+		if _, err := parseSince(invalid, now); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestPrintTimeReport(t *testing.T) {
+	totals := []model.TimeTotal{{Group: "deploy", Duration: 90 * time.Minute}, {Group: "review, docs", Duration: 15 * time.Minute}}
+
+	var buf bytes.Buffer
+	if err := printTimeReport(&buf, totals, "item", "csv"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "item,hours\ndeploy,1.50\n\"review, docs\",0.25\n"; buf.String() != want {
+		t.Fatalf("csv:\n%v", buf.String())
+	}
+
+	buf.Reset()
+	if err := printTimeReport(&buf, totals, "item", "json"); err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		By     string
+		Groups []struct {
+			Group   string
+			Seconds int64
+		}
+		Hours float64
+	}
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.By != "item" || len(res.Groups) != 2 || res.Groups[0].Seconds != 5400 || res.Hours != 1.75 {
+		t.Fatalf("json: %+v", res)
+	}
+
+	if err := printTimeReport(&buf, totals, "item", "xml"); err == nil {
+		t.Fatal("expected error for invalid format")
+	}
+}
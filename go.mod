@@ -6,7 +6,12 @@ replace github.com/rivo/tview v0.0.0-20220307222120-9994674d60a8 => github.com/c
 
 require github.com/spf13/cobra v1.6.1
 
-require github.com/google/uuid v1.6.0
+require (
+	filippo.io/age v1.1.1
+	github.com/google/uuid v1.6.0
+)
+
+require golang.org/x/crypto v0.4.0 // indirect
 
 require (
 	github.com/flytam/filenamify v1.1.2
@@ -20,6 +25,6 @@ require (
 	github.com/rivo/uniseg v0.4.3 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/sys v0.3.0 // indirect
-	golang.org/x/term v0.3.0 // indirect
+	golang.org/x/term v0.3.0
 	golang.org/x/text v0.5.0 // indirect
 )
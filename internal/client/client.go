@@ -0,0 +1,170 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// ErrNotFound is returned when the requested mode does not exist on the
+// server.
+var ErrNotFound = errors.New("not found on server")
+
+// Client talks to a board served by "todo serve". It implements
+// model.Remote, so a ToDoContent can be loaded from and saved to the server.
+type Client struct {
+	baseURL string
+	token   string
+	mode    string
+	http    *http.Client
+}
+
+func New(baseURL, token, mode string) *Client {
+	if mode == "" {
+		mode = "main"
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), token: token, mode: mode, http: &http.Client{}}
+}
+
+// Mode returns the mode the client is connected to.
+func (c *Client) Mode() string {
+	return c.mode
+}
+
+func (c *Client) modeURL(elem ...string) string {
+	return c.baseURL + "/api/modes/" + url.PathEscape(c.mode) + "/" + strings.Join(elem, "/")
+}
+
+func (c *Client) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode == http.StatusConflict {
+		resp.Body.Close()
+		return nil, model.ErrConflict
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%v %v: %v (%v)", method, url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+// Modes returns the modes available on the server.
+func (c *Client) Modes() ([]string, error) {
+	resp, err := c.do(context.Background(), http.MethodGet, c.baseURL+"/api/modes", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var modes []string
+	err = json.NewDecoder(resp.Body).Decode(&modes)
+	return modes, err
+}
+
+// Load returns the JSON document of the board.
+func (c *Client) Load() ([]byte, error) {
+	resp, err := c.do(context.Background(), http.MethodGet, c.modeURL("board"), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Store replaces the board on the server. The mode is created if needed. It
+// fails with model.ErrConflict if the board was changed since the revision
+// given in data.
+func (c *Client) Store(data []byte) error {
+	resp, err := c.do(context.Background(), http.MethodPut, c.modeURL("board"), data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Archive archives the item with the given GUID on the server.
+func (c *Client) Archive(guid string) error {
+	resp, err := c.do(context.Background(), http.MethodPost, c.modeURL("items", url.PathEscape(guid), "archive"), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Watch calls changed for every change event of the board until ctx is
+// cancelled. Lost connections are re-established after a short delay.
+func (c *Client) Watch(ctx context.Context, changed func()) {
+	delay := time.Second
+	for {
+		err := c.watchOnce(ctx, changed)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			delay = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+func (c *Client) watchOnce(ctx context.Context, changed func()) error {
+	resp, err := c.do(ctx, http.MethodGet, c.modeURL("events"), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case line == "":
+			if event == "changed" {
+				changed()
+			}
+			event = ""
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/server"
+)
+
+func TestRemoteContentRoundTrip(t *testing.T) {
+	srv := server.New(t.TempDir(), "secret")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	defer srv.Close()
+
+	c := new(model.ToDoContent)
+	c.SetRemote(New(ts.URL, "secret", "work"))
+	if err := c.Read(); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for new mode got %v", err)
+	}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	if err := c.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	other := new(model.ToDoContent)
+	cl := New(ts.URL, "secret", "work")
+	other.SetRemote(cl)
+	if err := other.Read(); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(other.Items[0]) != 1 || other.Items[0][0].Title != "task" {
+		t.Fatalf("unexpected items: %#v", other.Items)
+	}
+
+	if err := other.ArchiveItem(0, 0); err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+	// the archive counts as a save, the board of c is outdated now
+	if err := other.Save(); err != nil {
+		t.Fatalf("save after archive failed: %v", err)
+	}
+	if err := c.Save(); err != model.ErrConflict {
+		t.Fatalf("expected conflict for outdated board got %v", err)
+	}
+	if err := c.Read(); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(c.Items[0]) != 0 {
+		t.Fatalf("archived item still on server")
+	}
+
+	modes, err := cl.Modes()
+	if err != nil {
+		t.Fatalf("listing modes failed: %v", err)
+	}
+	if len(modes) != 2 || modes[1] != "work" {
+		t.Fatalf("unexpected modes: %v", modes)
+	}
+}
+
+func TestWrongToken(t *testing.T) {
+	srv := server.New(t.TempDir(), "secret")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	defer srv.Close()
+
+	if _, err := New(ts.URL, "wrong", "main").Modes(); err == nil {
+		t.Fatalf("expected error for wrong token")
+	}
+}
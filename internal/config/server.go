@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+)
+
+// ServerConfig holds the settings for sharing boards over HTTP. The same
+// file is used by "todo serve" (Listen, Token) and by clients connecting to
+// a server given with --server (Token).
+type ServerConfig struct {
+	Listen string `json:"listen"`
+	Token  string `json:"token"`
+}
+
+// LoadServerConfig reads $HOME/.todo/server.json. A missing file results in
+// an empty configuration with the default listen address.
+func LoadServerConfig(home string) (ServerConfig, error) {
+	cfg := ServerConfig{Listen: ":8080"}
+	data, err := os.ReadFile(path.Join(home, ".todo", "server.json"))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.Listen == "" {
+		cfg.Listen = ":8080"
+	}
+	return cfg, nil
+}
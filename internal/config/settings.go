@@ -1,16 +1,406 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cklukas/todo/internal/model"
 )
 
+// KeyList is a list of key names. In the settings file it may be given as a
+// single string or as a list of strings.
+type KeyList []string
+
+func (k *KeyList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*k = KeyList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*k = list
+	return nil
+}
+
+// KeymapSettings is the "keymap" section of the settings file. Bindings
+// replace the keys of the given actions of the preset.
+type KeymapSettings struct {
+	Preset   string             `json:"preset,omitempty"`
+	Bindings map[string]KeyList `json:"bindings,omitempty"`
+}
+
+// Settings contains the user settings of $HOME/.todo/settings.json, merged
+// with the overrides of the active mode.
+type Settings struct {
+	Mode            string         `json:"mode,omitempty"`
+	Editor          string         `json:"editor,omitempty"`
+	NoteEditor      string         `json:"noteEditor,omitempty"`
+	DateFormat      string         `json:"dateFormat,omitempty"`
+	Clock           *bool          `json:"clock,omitempty"`
+	ClockFormat     string         `json:"clockFormat,omitempty"`
+	DefaultPriority int            `json:"defaultPriority,omitempty"`
+	DefaultLane     string         `json:"defaultLane,omitempty"`
+	ConfirmDelete   *bool          `json:"confirmDelete,omitempty"`
+	BackupDays      int            `json:"backupDays,omitempty"`
+	Theme           string         `json:"theme,omitempty"`
+	DetailPane      bool           `json:"detailPane,omitempty"`
+	DetailPosition  string         `json:"detailPosition,omitempty"`
+	PomodoroWork    int            `json:"pomodoroWork,omitempty"`
+	PomodoroBreak   int            `json:"pomodoroBreak,omitempty"`
+	PomodoroLane    string         `json:"pomodoroLane,omitempty"`
+	PomodoroCommand string         `json:"pomodoroCommand,omitempty"`
+	EstimateUnit    string         `json:"estimateUnit,omitempty"`
+	ShowNumbers     bool           `json:"showNumbers,omitempty"`
+	Keymap          KeymapSettings `json:"keymap,omitempty"`
+}
+
+// DateFormats are the supported values of the "dateFormat" setting.
+var DateFormats = []string{"dd.mm.yyyy", "mm/dd/yyyy", "dd/mm/yyyy", "yyyy-mm-dd"}
+
+// ClockFormats are the supported values of the "clockFormat" setting.
+var ClockFormats = []string{"24h", "12h"}
+
+// NoteEditors are the supported values of the "noteEditor" setting.
+var NoteEditors = []string{"external", "builtin"}
+
+// DetailPositions are the supported values of the "detailPosition" setting.
+var DetailPositions = []string{"right", "bottom"}
+
+// ClockEnabled returns whether the clock is shown in the status bar.
+func (s Settings) ClockEnabled() bool {
+	return s.Clock == nil || *s.Clock
+}
+
+// ConfirmDeleteEnabled returns whether deleting a task has to be confirmed.
+func (s Settings) ConfirmDeleteEnabled() bool {
+	return s.ConfirmDelete == nil || *s.ConfirmDelete
+}
+
+// BuiltinNoteEditor returns whether notes are edited with the built-in
+// editor instead of an external one.
+func (s Settings) BuiltinNoteEditor() bool {
+	return s.NoteEditor == "builtin"
+}
+
+// DetailPaneAt returns where the detail pane is shown.
+func (s Settings) DetailPaneAt() string {
+	if s.DetailPosition == "bottom" {
+		return s.DetailPosition
+	}
+	return DetailPositions[0]
+}
+
+// PomodoroWorkTime returns the length of the focus phase of a pomodoro.
+func (s Settings) PomodoroWorkTime() time.Duration {
+	if s.PomodoroWork <= 0 {
+		return 25 * time.Minute
+	}
+	return time.Duration(s.PomodoroWork) * time.Minute
+}
+
+// PomodoroBreakTime returns the length of the break after a pomodoro.
+func (s Settings) PomodoroBreakTime() time.Duration {
+	if s.PomodoroBreak <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(s.PomodoroBreak) * time.Minute
+}
+
+// Priority returns the priority of new tasks.
+func (s Settings) Priority() int {
+	if s.DefaultPriority < 1 || s.DefaultPriority > 4 {
+		return 2
+	}
+	return s.DefaultPriority
+}
+
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindBool
+	kindInt
+	kindJSON
+)
+
+// SettingInfo describes a setting which can be changed with "todo config".
+type SettingInfo struct {
+	Key         string
+	Description string
+	Values      []string
+	kind        settingKind
+}
+
+var settingInfos = []SettingInfo{
+	{Key: "editor", Description: "command used to edit notes (default: $VISUAL or $EDITOR)"},
+	{Key: "noteEditor", Description: "editor for notes, external uses the editor setting (default: external)", Values: NoteEditors},
+	{Key: "dateFormat", Description: "format of due dates (default: from the system locale)", Values: DateFormats},
+	{Key: "clock", Description: "show the clock in the status bar (default: true)", kind: kindBool},
+	{Key: "clockFormat", Description: "format of the clock (default: 24h)", Values: ClockFormats},
+	{Key: "defaultPriority", Description: "priority of new tasks, 1 (high) to 4 (idle) (default: 2)", Values: []string{"1", "2", "3", "4"}, kind: kindInt},
+	{Key: "defaultLane", Description: "title of the lane focused at start (default: first lane)"},
+	{Key: "confirmDelete", Description: "ask before deleting a task (default: true)", kind: kindBool},
+	{Key: "backupDays", Description: "days daily backups are kept, 0 keeps all (default: 0)", kind: kindInt},
+	{Key: "theme", Description: "color theme (default: default)"},
+	{Key: "detailPane", Description: "show the details of the selected task (default: false)", kind: kindBool},
+	{Key: "detailPosition", Description: "position of the detail pane (default: right)", Values: DetailPositions},
+	{Key: "pomodoroWork", Description: "minutes of a pomodoro focus phase (default: 25)", kind: kindInt},
+	{Key: "pomodoroBreak", Description: "minutes of the break after a pomodoro (default: 5)", kind: kindInt},
+	{Key: "pomodoroLane", Description: "title of the lane a task is moved to when a pomodoro starts (default: none)"},
+	{Key: "pomodoroCommand", Description: "command run at the end of a pomodoro phase with the message as argument (default: terminal bell)"},
+	{Key: "estimateUnit", Description: "unit of task estimates, e.g. pt or h (default: pt)"},
+	{Key: "showNumbers", Description: "show the numbers of the tasks, e.g. #42, on the cards (default: false)", kind: kindBool},
+	{Key: "keymap", Description: `key bindings as JSON, e.g. {"preset": "vim"}`, kind: kindJSON},
+}
+
+// SettingInfos returns the settings which can be changed with "todo config".
+func SettingInfos() []SettingInfo {
+	return settingInfos
+}
+
+func findSettingInfo(key string) (SettingInfo, error) {
+	for _, info := range settingInfos {
+		if strings.EqualFold(info.Key, key) {
+			return info, nil
+		}
+	}
+	return SettingInfo{}, fmt.Errorf("unknown setting '%v'", key)
+}
+
+func settingsFileName(home string) string {
+	return path.Join(home, ".todo", "settings.json")
+}
+
+// ModeSettingsFileName returns the settings file of a mode, which overrides
+// the user settings. For the main mode this is the user settings file.
+func ModeSettingsFileName(home, mode string) (string, error) {
+	dir, err := model.ModeDir(mode)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(home, dir, "settings.json"), nil
+}
+
+// readSettingsFile returns the top level entries of a settings file. A
+// missing file results in an empty map.
+func readSettingsFile(fname string) (map[string]json.RawMessage, error) {
+	res := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(fname)
+	if errors.Is(err, os.ErrNotExist) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("invalid settings file '%v': %w", fname, err)
+	}
+	return res, nil
+}
+
+// updateSettingsFile sets a top level entry of a settings file, other
+// entries are kept. A nil value removes the entry.
+func updateSettingsFile(fname, key string, value json.RawMessage) error {
+	if err := os.MkdirAll(path.Dir(fname), os.ModePerm); err != nil {
+		return err
+	}
+	settings, err := readSettingsFile(fname)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		delete(settings, key)
+	} else {
+		settings[key] = value
+	}
+	data, err := json.MarshalIndent(settings, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fname, data, 0644)
+}
+
+// loadRawSettings returns the user settings merged with the overrides of
+// the given mode.
+func loadRawSettings(home, mode string) (map[string]json.RawMessage, map[string]bool, error) {
+	settings, err := readSettingsFile(settingsFileName(home))
+	if err != nil {
+		return nil, nil, err
+	}
+	overridden := make(map[string]bool)
+	modeFile, err := ModeSettingsFileName(home, mode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if modeFile == settingsFileName(home) {
+		return settings, overridden, nil
+	}
+	modeSettings, err := readSettingsFile(modeFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, value := range modeSettings {
+		if key == "mode" {
+			continue
+		}
+		settings[key] = value
+		overridden[key] = true
+	}
+	return settings, overridden, nil
+}
+
+// LoadSettings returns the user settings of $HOME/.todo/settings.json with
+// the overrides of $HOME/.todo/mode/<mode>/settings.json applied.
+func LoadSettings(home, mode string) (Settings, error) {
+	var s Settings
+	raw, _, err := loadRawSettings(home, mode)
+	if err != nil {
+		return s, err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+// SettingValue is the effective value of a setting.
+type SettingValue struct {
+	SettingInfo
+	Value string
+	// Mode is true if the value is set in the settings file of the mode.
+	Mode bool
+}
+
+// ListSettings returns the effective values of all settings for a mode. The
+// value of settings which are not set is empty.
+func ListSettings(home, mode string) ([]SettingValue, error) {
+	raw, overridden, err := loadRawSettings(home, mode)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]SettingValue, len(settingInfos))
+	for i, info := range settingInfos {
+		res[i] = SettingValue{SettingInfo: info, Value: formatSetting(info, raw[info.Key]), Mode: overridden[info.Key]}
+	}
+	return res, nil
+}
+
+// GetSetting returns the effective value of a setting for a mode, or an
+// empty string if it is not set.
+func GetSetting(home, mode, key string) (string, error) {
+	info, err := findSettingInfo(key)
+	if err != nil {
+		return "", err
+	}
+	raw, _, err := loadRawSettings(home, mode)
+	if err != nil {
+		return "", err
+	}
+	return formatSetting(info, raw[info.Key]), nil
+}
+
+// SetSetting changes a setting in the user settings, or in the settings of
+// the given mode (empty for the user settings). An empty value removes the
+// setting, so the default or the user setting applies again.
+func SetSetting(home, mode, key, value string) error {
+	info, err := findSettingInfo(key)
+	if err != nil {
+		return err
+	}
+	fname := settingsFileName(home)
+	if mode != "" {
+		if fname, err = ModeSettingsFileName(home, mode); err != nil {
+			return err
+		}
+	}
+	if value == "" {
+		return updateSettingsFile(fname, info.Key, nil)
+	}
+	raw, err := parseSetting(info, value)
+	if err != nil {
+		return err
+	}
+	return updateSettingsFile(fname, info.Key, raw)
+}
+
+func parseSetting(info SettingInfo, value string) (json.RawMessage, error) {
+	if len(info.Values) > 0 {
+		valid := false
+		for _, v := range info.Values {
+			valid = valid || v == value
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid value '%v' for '%v', use one of: %v", value, info.Key, strings.Join(info.Values, ", "))
+		}
+	}
+	switch info.kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%v' for '%v', use true or false", value, info.Key)
+		}
+		return json.Marshal(b)
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid value '%v' for '%v', a number is required", value, info.Key)
+		}
+		return json.Marshal(n)
+	case kindJSON:
+		var km KeymapSettings
+		if err := json.Unmarshal([]byte(value), &km); err != nil {
+			return nil, fmt.Errorf("invalid value for '%v': %w", info.Key, err)
+		}
+		return json.RawMessage(value), nil
+	default:
+		return json.Marshal(value)
+	}
+}
+
+func formatSetting(info SettingInfo, raw json.RawMessage) string {
+	if raw == nil {
+		return ""
+	}
+	if info.kind == kindString {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// SettingKeys returns the names of all settings.
+func SettingKeys() []string {
+	keys := make([]string, len(settingInfos))
+	for i, info := range settingInfos {
+		keys[i] = info.Key
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // loadLastModeFromSettings loads the last UI selected mode from
 // $HOME/.todo/settings.json. It returns the mode or an error.
 func LoadLastModeFromSettings(home string) (string, error) {
-	fname := path.Join(home, ".todo", "settings.json")
-	data, err := os.ReadFile(fname)
+	data, err := os.ReadFile(settingsFileName(home))
 	if err != nil {
 		return "", err
 	}
@@ -29,16 +419,9 @@ func LoadLastModeFromSettings(home string) (string, error) {
 // saveLastModeToSettings writes the provided mode to
 // $HOME/.todo/settings.json.
 func SaveLastModeToSettings(home, mode string) error {
-	dir := path.Join(home, ".todo")
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return err
-	}
-	fname := path.Join(dir, "settings.json")
-	data, err := json.Marshal(struct {
-		Mode string `json:"mode"`
-	}{Mode: mode})
+	raw, err := json.Marshal(mode)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fname, data, 0644)
+	return updateSettingsFile(settingsFileName(home), "mode", raw)
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestSaveLoadLastMode(t *testing.T) {
@@ -27,3 +28,137 @@ func TestSaveLoadLastMode(t *testing.T) {
 		t.Fatalf("settings file not created: %v", err)
 	}
 }
+
+func TestKeymapSettingsKeptOnModeChange(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".todo"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"mode": "main", "keymap": {"preset": "vim", "bindings": {"delete": "x", "add": ["F2", "a"]}}}`
+	if err := os.WriteFile(filepath.Join(dir, ".todo", "settings.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveLastModeToSettings(dir, "work"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	settings, err := LoadSettings(dir, "main")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	km := settings.Keymap
+	if km.Preset != "vim" {
+		t.Fatalf("preset lost: %#v", km)
+	}
+	if len(km.Bindings["delete"]) != 1 || km.Bindings["delete"][0] != "x" {
+		t.Fatalf("single key binding wrong: %#v", km.Bindings)
+	}
+	if len(km.Bindings["add"]) != 2 {
+		t.Fatalf("key list binding wrong: %#v", km.Bindings)
+	}
+}
+
+func TestSetSettingKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+
+	if theme, err := GetSetting(dir, "", "theme"); err != nil || theme != "" {
+		t.Fatalf("expected no theme, got '%s' (%v)", theme, err)
+	}
+	if err := SaveLastModeToSettings(dir, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting(dir, "", "theme", "solarized"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	theme, err := GetSetting(dir, "", "theme")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if theme != "solarized" {
+		t.Fatalf("expected solarized got %s", theme)
+	}
+	if mode, _ := LoadLastModeFromSettings(dir); mode != "work" {
+		t.Fatalf("mode lost, got %s", mode)
+	}
+}
+
+func TestModeSettingsOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := SetSetting(dir, "", "defaultPriority", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting(dir, "", "clock", "false"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting(dir, "work", "defaultPriority", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".todo", "mode", "work", "settings.json")); err != nil {
+		t.Fatalf("mode settings file not created: %v", err)
+	}
+
+	s, err := LoadSettings(dir, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Priority() != 1 || s.ClockEnabled() {
+		t.Fatalf("unexpected work settings: %#v", s)
+	}
+	if s, _ := LoadSettings(dir, "private"); s.Priority() != 3 {
+		t.Fatalf("user setting not used without override, got %d", s.Priority())
+	}
+
+	values, err := ListSettings(dir, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range values {
+		if v.Key == "defaultPriority" && (v.Value != "1" || !v.Mode) {
+			t.Fatalf("unexpected list entry %#v", v)
+		}
+		if v.Key == "clock" && (v.Value != "false" || v.Mode) {
+			t.Fatalf("unexpected list entry %#v", v)
+		}
+	}
+
+	// removing the override restores the user setting
+	if err := SetSetting(dir, "work", "defaultPriority", ""); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := LoadSettings(dir, "work"); s.Priority() != 3 {
+		t.Fatalf("override not removed, got %d", s.Priority())
+	}
+}
+
+func TestSetSettingValidation(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"unknown":         "x",
+		"clock":           "maybe",
+		"defaultPriority": "7",
+		"backupDays":      "-1",
+		"dateFormat":      "yyyy.dd.mm",
+		"keymap":          "{",
+		"detailPosition":  "left",
+		"pomodoroWork":    "soon",
+	}
+	for key, value := range cases {
+		if err := SetSetting(dir, "", key, value); err == nil {
+			t.Fatalf("expected error for %s=%s", key, value)
+		}
+	}
+	if err := SetSetting(dir, "", "keymap", `{"preset": "vim"}`); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := LoadSettings(dir, ""); s.Keymap.Preset != "vim" {
+		t.Fatalf("keymap not stored: %#v", s.Keymap)
+	}
+	if err := SetSetting(dir, "", "pomodoroWork", "50"); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := LoadSettings(dir, ""); s.PomodoroWorkTime() != 50*time.Minute || s.PomodoroBreakTime() != 5*time.Minute {
+		t.Fatalf("pomodoro times %v, %v", s.PomodoroWorkTime(), s.PomodoroBreakTime())
+	}
+}
@@ -0,0 +1,261 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ConfigFile is the name of the file in a mode directory which marks the
+// mode as encrypted.
+const ConfigFile = "encryption.json"
+
+const (
+	MethodPassphrase = "passphrase"
+	MethodAge        = "age"
+)
+
+// Config describes how the files of a mode are encrypted. With the
+// passphrase method, a generated age key is stored in Key, protected by the
+// passphrase. With the age method, the key is read from the Identity file.
+type Config struct {
+	Method   string `json:"method"`
+	Key      string `json:"key,omitempty"`
+	Identity string `json:"identity,omitempty"`
+}
+
+// Cipher encrypts and decrypts files with an age X25519 key.
+type Cipher struct {
+	identity *age.X25519Identity
+}
+
+// IsEncrypted reports whether data is an age encrypted file.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("age-encryption.org/"))
+}
+
+func (c *Cipher) Encrypt(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, c.identity.Recipient())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(data), c.identity)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// LoadConfig reads the encryption settings of the given mode directory. It
+// returns nil if the mode is not encrypted.
+func LoadConfig(dir string) (*Config, error) {
+	data, err := os.ReadFile(path.Join(dir, ConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(Config)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Save writes the encryption settings into the given mode directory.
+func (cfg *Config) Save(dir string) error {
+	data, err := json.MarshalIndent(cfg, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, ConfigFile), data, 0600)
+}
+
+// NewPassphraseConfig generates a new key protected by the passphrase.
+func NewPassphraseConfig(passphrase string) (*Config, *Cipher, error) {
+	if len(passphrase) == 0 {
+		return nil, nil, errors.New("empty passphrase")
+	}
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, nil, err
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.WriteString(w, identity.String()); err != nil {
+		return nil, nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return &Config{Method: MethodPassphrase, Key: buf.String()}, &Cipher{identity: identity}, nil
+}
+
+// NewKeyFileConfig uses the age identity stored in the given file. A new
+// identity is generated if the file does not exist.
+func NewKeyFileConfig(identityFile string) (*Config, *Cipher, error) {
+	if _, err := os.Stat(identityFile); errors.Is(err, os.ErrNotExist) {
+		identity, err := age.GenerateX25519Identity()
+		if err != nil {
+			return nil, nil, err
+		}
+		content := fmt.Sprintf("# public key: %v\n%v\n", identity.Recipient(), identity)
+		if err := os.WriteFile(identityFile, []byte(content), 0600); err != nil {
+			return nil, nil, err
+		}
+	}
+	cfg := &Config{Method: MethodAge, Identity: identityFile}
+	c, err := cfg.Open(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, c, nil
+}
+
+// Open returns the cipher described by the configuration. For the passphrase
+// method, prompt is called to ask for the passphrase.
+func (cfg *Config) Open(prompt func() (string, error)) (*Cipher, error) {
+	switch cfg.Method {
+	case MethodPassphrase:
+		if prompt == nil {
+			return nil, errors.New("passphrase required")
+		}
+		passphrase, err := prompt()
+		if err != nil {
+			return nil, err
+		}
+		identity, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(cfg.Key)), identity)
+		if err != nil {
+			return nil, fmt.Errorf("wrong passphrase: %w", err)
+		}
+		key, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return parseIdentity(string(key))
+	case MethodAge:
+		f, err := os.Open(cfg.Identity)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		identities, err := age.ParseIdentities(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range identities {
+			if x, ok := id.(*age.X25519Identity); ok {
+				return &Cipher{identity: x}, nil
+			}
+		}
+		return nil, fmt.Errorf("no X25519 identity found in '%v'", cfg.Identity)
+	default:
+		return nil, fmt.Errorf("unknown encryption method '%v'", cfg.Method)
+	}
+}
+
+func parseIdentity(s string) (*Cipher, error) {
+	identity, err := age.ParseX25519Identity(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{identity: identity}, nil
+}
+
+// modeFiles returns todo.json and all backup and archive files of a mode.
+func modeFiles(dir string) ([]string, error) {
+	files := []string{path.Join(dir, "todo.json")}
+	for _, sub := range []string{"backup", "archive"} {
+		matches, err := filepath.Glob(path.Join(dir, sub, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// EncryptMode encrypts todo.json, the backups and the archived items of the
+// given mode directory. Files which are already encrypted are skipped.
+func EncryptMode(dir string, c *Cipher) error {
+	return convertMode(dir, 0600, func(data []byte) ([]byte, error) {
+		if IsEncrypted(data) {
+			return data, nil
+		}
+		return c.Encrypt(data)
+	})
+}
+
+// DecryptMode converts all encrypted files of the given mode directory back
+// into plain JSON.
+func DecryptMode(dir string, c *Cipher) error {
+	return convertMode(dir, 0644, func(data []byte) ([]byte, error) {
+		if !IsEncrypted(data) {
+			return data, nil
+		}
+		return c.Decrypt(data)
+	})
+}
+
+func convertMode(dir string, perm os.FileMode, convert func([]byte) ([]byte, error)) error {
+	files, err := modeFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, fname := range files {
+		data, err := os.ReadFile(fname)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		res, err := convert(data)
+		if err != nil {
+			return fmt.Errorf("'%v': %w", fname, err)
+		}
+		if err := os.WriteFile(fname, res, perm); err != nil {
+			return err
+		}
+		if err := os.Chmod(fname, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package crypt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestPassphraseConfig(t *testing.T) {
+	cfg, c, err := NewPassphraseConfig("secret")
+	if err != nil {
+		t.Fatalf("creating config failed: %v", err)
+	}
+	enc, err := c.Encrypt([]byte("hello"))
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("output not recognized as encrypted")
+	}
+
+	dir := t.TempDir()
+	if err := cfg.Save(dir); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	loaded, err := LoadConfig(dir)
+	if err != nil || loaded == nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if _, err := loaded.Open(func() (string, error) { return "wrong", nil }); err == nil {
+		t.Fatalf("expected error for wrong passphrase")
+	}
+	opened, err := loaded.Open(func() (string, error) { return "secret", nil })
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	dec, err := opened.Decrypt(enc)
+	if err != nil || string(dec) != "hello" {
+		t.Fatalf("decrypt failed: %q %v", dec, err)
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	cfg, err := LoadConfig(t.TempDir())
+	if err != nil || cfg != nil {
+		t.Fatalf("expected no config got %v %v", cfg, err)
+	}
+}
+
+func TestEncryptDecryptMode(t *testing.T) {
+	home := t.TempDir()
+	content, err := model.OpenMode(home, "work", nil)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	content.AddItem(0, 0, "task", "", 2, "", "")
+	content.AddItem(0, 1, "old", "", 2, "", "")
+	if err := content.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := content.ArchiveItem(0, 1); err != nil {
+		t.Fatalf("archive failed: %v", err)
+	}
+	if err := content.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	dir := filepath.Join(home, ".todo", "mode", "work")
+	_, c, err := NewKeyFileConfig(filepath.Join(home, "key.txt"))
+	if err != nil {
+		t.Fatalf("creating key failed: %v", err)
+	}
+	if err := EncryptMode(dir, c); err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	files, _ := modeFiles(dir)
+	if len(files) != 3 {
+		t.Fatalf("expected todo.json, backup and archive file got %v", files)
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if !IsEncrypted(data) {
+			t.Fatalf("file %v not encrypted", f)
+		}
+	}
+
+	if _, err := model.OpenMode(home, "work", nil); err != model.ErrEncrypted {
+		t.Fatalf("expected ErrEncrypted got %v", err)
+	}
+	encrypted, err := model.OpenMode(home, "work", c)
+	if err != nil {
+		t.Fatalf("open encrypted failed: %v", err)
+	}
+	if len(encrypted.Items[0]) != 1 || encrypted.Items[0][0].Title != "task" {
+		t.Fatalf("unexpected items: %#v", encrypted.Items)
+	}
+
+	if err := DecryptMode(dir, c); err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	if _, err := model.OpenMode(home, "work", nil); err != nil {
+		t.Fatalf("open decrypted failed: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// LaneCount is the number of tasks in a lane and the sum of their
+// estimates.
+type LaneCount struct {
+	Title    string
+	Count    int
+	Estimate float64
+}
+
+// AgendaItem is a task listed in the overview, together with its position.
+type AgendaItem struct {
+	Mode      string
+	Lane      int
+	LaneTitle string
+	Item      Item
+}
+
+// ModeSummary is the overview of a mode: the number of tasks and estimates
+// per lane, the tasks which are overdue or due today, the waiting tasks to
+// follow up and the tasks with high priority.
+type ModeSummary struct {
+	Mode         string
+	EstimateUnit string
+	Lanes        []LaneCount
+	Overdue      []AgendaItem
+	DueToday     []AgendaItem
+	FollowUp     []AgendaItem
+	Top          []AgendaItem
+	Err          error
+}
+
+// DueDays returns the number of days until the due date, negative if it is
+// overdue. The second result is false if no valid due date is set.
+func DueDays(due string, now time.Time) (int, bool) {
+	if len(due) == 0 {
+		return 0, false
+	}
+	d, err := time.Parse("2006-01-02", due)
+	if err != nil {
+		return 0, false
+	}
+	d = d.In(now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return int(d.Sub(today).Hours() / 24), true
+}
+
+// Summarize returns the overview of the board of a mode.
+func Summarize(mode string, c *ToDoContent, now time.Time) ModeSummary {
+	s := ModeSummary{Mode: mode, EstimateUnit: c.EstimateUnit(), Lanes: make([]LaneCount, len(c.Titles))}
+	for lane, title := range c.Titles {
+		items := c.GetLaneItems(lane)
+		s.Lanes[lane] = LaneCount{Title: title, Count: len(items), Estimate: c.LaneEstimate(lane)}
+		for _, item := range items {
+			ai := AgendaItem{Mode: mode, Lane: lane, LaneTitle: title, Item: item}
+			if days, ok := DueDays(item.Due, now); ok && days < 0 {
+				s.Overdue = append(s.Overdue, ai)
+			} else if ok && days == 0 {
+				s.DueToday = append(s.DueToday, ai)
+			}
+			if item.FollowUpDue(now) {
+				s.FollowUp = append(s.FollowUp, ai)
+			}
+			if item.Priority == 1 {
+				s.Top = append(s.Top, ai)
+			}
+		}
+	}
+	sort.SliceStable(s.Overdue, func(i, j int) bool {
+		return s.Overdue[i].Item.Due < s.Overdue[j].Item.Due
+	})
+	return s
+}
+
+// Summaries returns the overview of all modes. Modes which can not be read,
+// e.g. encrypted modes without passphrase, are reported with their error.
+func (s *ModeStore) Summaries(now time.Time) ([]ModeSummary, error) {
+	modes, err := s.Modes()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]ModeSummary, 0, len(modes))
+	for _, mode := range modes {
+		if !s.Exists(mode) {
+			continue
+		}
+		c, err := s.Open(mode)
+		if err != nil {
+			res = append(res, ModeSummary{Mode: mode, Err: err})
+			continue
+		}
+		res = append(res, Summarize(mode, c, now))
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "late", "", 2, "2024-05-08", "")
+	c.AddItem(0, 1, "today", "", 1, "2024-05-10", "")
+	c.AddItem(1, 0, "later", "", 2, "2024-05-20", "")
+	c.AddItem(1, 1, "urgent", "", 1, "", "")
+
+	s := Summarize("work", c, now)
+	if s.Mode != "work" || len(s.Lanes) != 3 || s.Lanes[0].Count != 2 || s.Lanes[1].Count != 2 || s.Lanes[2].Count != 0 {
+		t.Fatalf("unexpected lane counts %+v", s.Lanes)
+	}
+	if len(s.Overdue) != 1 || s.Overdue[0].Item.Title != "late" {
+		t.Fatalf("overdue=%+v", s.Overdue)
+	}
+	if len(s.DueToday) != 1 || s.DueToday[0].Item.Title != "today" {
+		t.Fatalf("due today=%+v", s.DueToday)
+	}
+	if len(s.Top) != 2 || s.Top[1].Item.Title != "urgent" || s.Top[1].LaneTitle != "Doing" {
+		t.Fatalf("top=%+v", s.Top)
+	}
+}
+
+func TestSummaries(t *testing.T) {
+	s := NewModeStore(t.TempDir(), nil)
+	newTestMode(t, s, "main")
+	newTestMode(t, s, "work", "Open", "Closed")
+
+	summaries, err := s.Summaries(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || summaries[0].Mode != "main" || summaries[1].Mode != "work" {
+		t.Fatalf("summaries=%+v", summaries)
+	}
+	if len(summaries[1].Lanes) != 2 || summaries[1].Lanes[1].Title != "Closed" {
+		t.Fatalf("lanes=%+v", summaries[1].Lanes)
+	}
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// AssignedTo returns whether the user is one of the assignees of the item.
+func (item *Item) AssignedTo(user string) bool {
+	for _, a := range item.Assignees {
+		if a == user {
+			return true
+		}
+	}
+	return false
+}
+
+// KnownMembers returns the members of the board: the stored member list and
+// the users seen in the data, who created, changed, were assigned to or
+// tracked time for a task. The result is sorted.
+func (c *ToDoContent) KnownMembers() []string {
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name = strings.TrimSpace(name); name != "" {
+			seen[name] = true
+		}
+	}
+	for _, m := range c.Members {
+		add(m)
+	}
+	for _, lane := range c.Items {
+		for _, item := range lane {
+			add(item.UserName)
+			add(item.UpdatedByName)
+			for _, a := range item.Assignees {
+				add(a)
+			}
+			for _, e := range item.Time {
+				add(e.User)
+			}
+		}
+	}
+	res := make([]string, 0, len(seen))
+	for name := range seen {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// AddMembers adds users to the member list of the board, if they are not
+// members yet.
+func (c *ToDoContent) AddMembers(names ...string) {
+	for _, name := range names {
+		found := false
+		for _, m := range c.Members {
+			found = found || m == name
+		}
+		if !found && name != "" {
+			c.Members = append(c.Members, name)
+		}
+	}
+}
+
+// ParseAssignees splits a list of user names separated by commas or spaces,
+// duplicates are removed.
+func ParseAssignees(text string) []string {
+	var res []string
+	for _, name := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		found := false
+		for _, a := range res {
+			found = found || a == name
+		}
+		if !found {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// Initials returns the initials of a user name: the first letters of its
+// parts separated by dots, dashes, underscores or spaces, or the first two
+// letters of a name of one part.
+func Initials(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var res []rune
+	switch {
+	case len(parts) == 0:
+		return ""
+	case len(parts) == 1:
+		res = []rune(parts[0])
+		if len(res) > 2 {
+			res = res[:2]
+		}
+	default:
+		res = []rune{[]rune(parts[0])[0], []rune(parts[1])[0]}
+	}
+	return strings.ToUpper(string(res))
+}
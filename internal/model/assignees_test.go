@@ -0,0 +1,50 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKnownMembers(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.Items[0][0].UserName, c.Items[0][0].UpdatedByName = "ann", "bob"
+	c.Items[0][0].Assignees = []string{"carl"}
+	c.Items[0][0].Time = []TimeEntry{{Start: "2024-05-06T09:00:00Z", User: "dana"}}
+	c.AddMembers("eve", "ann", "eve")
+
+	if want := []string{"ann", "bob", "carl", "dana", "eve"}; !reflect.DeepEqual(c.KnownMembers(), want) {
+		t.Fatalf("members %v", c.KnownMembers())
+	}
+	if !reflect.DeepEqual(c.Members, []string{"eve", "ann"}) {
+		t.Fatalf("stored members %v", c.Members)
+	}
+	if !c.Items[0][0].AssignedTo("carl") || c.Items[0][0].AssignedTo("ann") {
+		t.Fatal("AssignedTo")
+	}
+}
+
+func TestParseAssignees(t *testing.T) {
+	if got := ParseAssignees(" ann, bob carl,,ann "); !reflect.DeepEqual(got, []string{"ann", "bob", "carl"}) {
+		t.Fatalf("assignees %v", got)
+	}
+	if got := ParseAssignees(""); got != nil {
+		t.Fatalf("assignees %v", got)
+	}
+}
+
+func TestInitials(t *testing.T) {
+	for name, want := range map[string]string{
+		"cklukas":    "CK",
+		"anna.smith": "AS",
+		"bob_e_lee":  "BE",
+		"x":          "X",
+		"ülrich":     "ÜL",
+		"":           "",
+	} {
+		if got := Initials(name); got != want {
+			t.Fatalf("initials of %q: %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"os/user"
+	"time"
+)
+
+// guidSet returns the GUIDs as set.
+func guidSet(guids []string) map[string]bool {
+	set := make(map[string]bool, len(guids))
+	for _, g := range guids {
+		set[g] = true
+	}
+	return set
+}
+
+// UpdateItems applies f to the tasks with the given GUIDs and records the
+// modification. It returns the number of tasks changed.
+func (c *ToDoContent) UpdateItems(guids []string, f func(item *Item)) int {
+	set := guidSet(guids)
+	now := time.Now().UTC().Format(time.RFC3339)
+	userName := ""
+	if usr, err := user.Current(); err == nil {
+		userName = usr.Username
+	}
+	n := 0
+	for lane := range c.Items {
+		for i := range c.Items[lane] {
+			item := &c.Items[lane][i]
+			if !set[item.Guid] {
+				continue
+			}
+			f(item)
+			item.LastUpdate = now
+			if userName != "" {
+				item.UpdatedByName = userName
+			}
+			n++
+		}
+	}
+	return n
+}
+
+// RemoveItems removes the tasks with the given GUIDs from the board and
+// returns them in the order of the board. Links to the tasks are removed and
+// their subtasks no longer belong to them.
+func (c *ToDoContent) RemoveItems(guids []string) []Item {
+	removed := c.takeItems(guids)
+	for _, item := range removed {
+		c.resolveLinks(item.Guid)
+	}
+	return removed
+}
+
+// takeItems removes the tasks with the given GUIDs from the lanes, keeping
+// the references to them.
+func (c *ToDoContent) takeItems(guids []string) []Item {
+	set := guidSet(guids)
+	var removed []Item
+	for lane := range c.Items {
+		kept := c.Items[lane][:0]
+		for _, item := range c.Items[lane] {
+			if set[item.Guid] {
+				removed = append(removed, item)
+			} else {
+				kept = append(kept, item)
+			}
+		}
+		c.Items[lane] = kept
+	}
+	return removed
+}
+
+// MoveItems moves the tasks with the given GUIDs to the end of a lane,
+// keeping their order. It returns the number of tasks moved.
+func (c *ToDoContent) MoveItems(guids []string, lane int) int {
+	if lane < 0 || lane >= len(c.Items) {
+		return 0
+	}
+	from := make(map[string]int, len(guids))
+	for _, guid := range guids {
+		from[guid], _, _ = c.FindItem(guid)
+	}
+	removed := c.takeItems(guids)
+	now := time.Now()
+	for i := range removed {
+		c.updateDone(&removed[i], from[removed[i].Guid], lane, now)
+	}
+	c.Items[lane] = append(c.Items[lane], removed...)
+	return len(removed)
+}
+
+// ArchiveItems archives the tasks with the given GUIDs. Tasks which could
+// not be archived stay on the board, the first error is returned.
+func (c *ToDoContent) ArchiveItems(guids []string) (int, error) {
+	set := guidSet(guids)
+	n := 0
+	var firstErr error
+	for lane := range c.Items {
+		for i := 0; i < len(c.Items[lane]); {
+			if !set[c.Items[lane][i].Guid] {
+				i++
+				continue
+			}
+			if err := c.ArchiveItem(lane, i); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				i++
+				continue
+			}
+			n++
+		}
+	}
+	return n, firstErr
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestBulkOperations(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "a", "", 2, "", "")
+	c.AddItem(0, 1, "b", "", 2, "", "")
+	c.AddItem(1, 0, "c", "", 2, "", "")
+	c.AddItem(1, 1, "d", "", 2, "", "")
+	guids := []string{c.Items[0][1].Guid, c.Items[1][0].Guid, "unknown"}
+
+	if n := c.UpdateItems(guids, func(item *Item) { item.Priority = 1 }); n != 2 || c.Items[0][1].Priority != 1 || c.Items[1][1].Priority != 2 {
+		t.Fatalf("update changed %v tasks: %+v", n, c.Items)
+	}
+	if n := c.MoveItems(guids, 2); n != 2 || len(c.Items[0]) != 1 || len(c.Items[1]) != 1 || c.Items[2][0].Title != "b" || c.Items[2][1].Title != "c" {
+		t.Fatalf("move changed %v tasks: %+v", n, c.Items)
+	}
+	if removed := c.RemoveItems(guids); len(removed) != 2 || len(c.Items[2]) != 0 {
+		t.Fatalf("removed %+v", removed)
+	}
+
+	dir := t.TempDir()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	if n, err := c.ArchiveItems([]string{c.Items[0][0].Guid, c.Items[1][0].Guid}); err != nil || n != 2 || len(c.Items[0])+len(c.Items[1]) != 0 {
+		t.Fatalf("archived %v tasks, %v: %+v", n, err, c.Items)
+	}
+}
+
+func TestBulkRemoveResolvesLinks(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	for i, title := range []string{"epic", "sub", "other", "epic2", "sub2", "blocked"} {
+		c.AddItem(0, i, title, "", 2, "", "")
+	}
+	guid := func(i int) string { return c.Items[0][i].Guid }
+	epic, sub, other, epic2, sub2, blocked := guid(0), guid(1), guid(2), guid(3), guid(4), guid(5)
+	if err := c.SetParent(sub, epic); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetParent(sub2, epic2); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LinkItems(blocked, other, LinkBlockedBy); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LinkItems(blocked, epic2, LinkRelates); err != nil {
+		t.Fatal(err)
+	}
+
+	c.MoveItems([]string{epic, other}, 1)
+	if c.item(sub).Parent != epic || len(c.item(blocked).Links) != 2 {
+		t.Fatalf("move changed references: %+v", c.Items)
+	}
+	c.RemoveItems([]string{epic, other})
+	if c.item(sub).Parent != "" || len(c.item(blocked).Links) != 1 {
+		t.Fatalf("references to removed tasks kept: %+v", c.Items)
+	}
+
+	dir := t.TempDir()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	if _, err := c.ArchiveItems([]string{epic2}); err != nil {
+		t.Fatal(err)
+	}
+	if c.item(sub2).Parent != "" || len(c.item(blocked).Links) != 0 {
+		t.Fatalf("references to archived tasks kept: %+v", c.Items)
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Comment is an entry of the comment thread of an item. Comments are only
+// added, so concurrent comments of several users do not replace each other
+// like changes of the note.
+type Comment struct {
+	Author string
+	Time   string
+	Text   string
+}
+
+// AddComment appends a comment of the author to the thread of an item.
+func (c *ToDoContent) AddComment(guid, author, text string, now time.Time) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("the comment is empty")
+	}
+	item := c.item(guid)
+	if item == nil {
+		return fmt.Errorf("task not found")
+	}
+	item.Comments = append(item.Comments, Comment{Author: author, Time: now.UTC().Format(time.RFC3339), Text: text})
+	return nil
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddComment(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	guid := c.Items[0][0].Guid
+	now := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	if err := c.AddComment(guid, "ann", " looks good ", now); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddComment(guid, "bob", "ship it", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	comments := c.Items[0][0].Comments
+	if len(comments) != 2 || comments[0] != (Comment{Author: "ann", Time: "2024-05-06T09:00:00Z", Text: "looks good"}) || comments[1].Author != "bob" {
+		t.Fatalf("comments %+v", comments)
+	}
+	if err := c.AddComment(guid, "ann", "  ", now); err == nil {
+		t.Fatal("expected error for empty comment")
+	}
+	if err := c.AddComment("unknown", "ann", "text", now); err == nil {
+		t.Fatal("expected error for unknown task")
+	}
+
+	// comments are kept in the archive
+	dir := t.TempDir()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	if err := c.ArchiveItem(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	archived, err := c.ArchivedItems()
+	if err != nil || len(archived) != 1 || len(archived[0].Item.Comments) != 2 {
+		t.Fatalf("archived items %+v, %v", archived, err)
+	}
+}
@@ -1,12 +1,15 @@
 package model
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/user"
 	"path"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,17 +32,61 @@ type Item struct {
 	UserName      string
 	UpdatedByName string
 	Mode          string
+	Tags          []string    `json:",omitempty"`
+	Links         []Link      `json:",omitempty"`
+	Time          []TimeEntry `json:",omitempty"`
+	Pomodoros     int         `json:",omitempty"`
+	Assignees     []string    `json:",omitempty"`
+	Comments      []Comment   `json:",omitempty"`
+	Waiting       *WaitingFor `json:",omitempty"`
+	Estimate      float64     `json:",omitempty"`
+	Done          string      `json:",omitempty"`
+	Parent        string      `json:",omitempty"`
+	Number        int         `json:",omitempty"`
 }
 
+// Remote is implemented by storage backends which keep the board on a server
+// instead of the local todo.json file. Store fails with ErrConflict if the
+// board on the server is not the revision the data is based on.
+type Remote interface {
+	Load() ([]byte, error)
+	Store(data []byte) error
+	Archive(guid string) error
+}
+
+// Cipher is implemented by the encryption of a mode. If a cipher is set,
+// todo.json, the backups and the archived items are stored encrypted.
+type Cipher interface {
+	Encrypt(data []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// ErrEncrypted is returned when an encrypted file is read without cipher.
+var ErrEncrypted = errors.New("data is encrypted, passphrase or key required")
+
+// ErrConflict is returned when a board is saved which was changed by another
+// instance since it was read.
+var ErrConflict = errors.New("the board was changed by another instance")
+
 type ToDoContent struct {
 	Titles         []string
 	Items          [][]Item
 	SortModes      []string
 	LaneColors     []string
-	fname          string     `json:"-"`
-	archiveFolder  string     `json:"-"`
-	backupFolder   string     `json:"-"`
-	readWriteMutex sync.Mutex `json:"-"`
+	WipLimits      []int          `json:",omitempty"`
+	ItemTemplates  []ItemTemplate `json:",omitempty"`
+	Members        []string       `json:",omitempty"`
+	Sprints        []Sprint       `json:",omitempty"`
+	LastNumber     int            `json:",omitempty"`
+	Revision       int            `json:",omitempty"` // counts the saves of the board
+	fname          string         `json:"-"`
+	archiveFolder  string         `json:"-"`
+	backupFolder   string         `json:"-"`
+	backupDays     int            `json:"-"`
+	estimateUnit   string         `json:"-"`
+	remote         Remote         `json:"-"`
+	cipher         Cipher         `json:"-"`
+	readWriteMutex sync.Mutex     `json:"-"`
 }
 
 func (c *ToDoContent) Lock() {
@@ -55,29 +102,64 @@ func (c *ToDoContent) InitializeNew() {
 	c.Items = make([][]Item, 3)
 	c.SortModes = make([]string, 3)
 	c.LaneColors = make([]string, 3)
+	c.WipLimits = make([]int, 3)
 }
 
 func (c *ToDoContent) ReadFromFile(fname string) error {
-	f, err := os.OpenFile(fname, os.O_RDONLY, os.FileMode(int(0600)))
+	data, err := c.readFile(fname)
 	if err != nil {
 		return err
 	}
+	return c.decode(data)
+}
 
-	err = json.NewDecoder(f).Decode(c)
-	f.Close()
+// readFile returns the content of a data file, decrypted if needed.
+func (c *ToDoContent) readFile(fname string) ([]byte, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte("age-encryption.org/")) {
+		return data, nil
+	}
+	if c.cipher == nil {
+		return nil, ErrEncrypted
+	}
+	return c.cipher.Decrypt(data)
+}
+
+// writeFile writes a data file, encrypted if a cipher is set.
+func (c *ToDoContent) writeFile(fname string, data []byte) error {
+	if c.cipher == nil {
+		return os.WriteFile(fname, data, 0644)
+	}
+	enc, err := c.cipher.Encrypt(data)
 	if err != nil {
 		return err
 	}
-	c.normalize()
-	return nil
+	return os.WriteFile(fname, enc, 0600)
+}
+
+// SetCipher sets the encryption used for reading and writing the files.
+func (c *ToDoContent) SetCipher(cipher Cipher) {
+	c.cipher = cipher
 }
 
 func (c *ToDoContent) GetNumLanes() int {
 	return len(c.Titles)
 }
 
+// GetLaneTitle returns the title of a lane with the number of its tasks, the
+// WIP limit and the sum of the estimates, e.g. " Doing (4/5 · 13pt) ".
 func (c *ToDoContent) GetLaneTitle(idx int) string {
-	return fmt.Sprintf(" %v (%v) ", c.Titles[idx], len(c.Items[idx]))
+	count := fmt.Sprint(len(c.Items[idx]))
+	if limit := c.GetWipLimit(idx); limit > 0 {
+		count += fmt.Sprintf("/%v", limit)
+	}
+	if sum := c.LaneEstimate(idx); sum > 0 {
+		count += " · " + FormatEstimate(sum, c.EstimateUnit())
+	}
+	return fmt.Sprintf(" %v (%v) ", c.Titles[idx], count)
 }
 
 func (c *ToDoContent) SetLaneTitle(idx int, title string) {
@@ -97,6 +179,9 @@ func (c *ToDoContent) RemoveLane(lane int) {
 	if len(c.LaneColors) > lane {
 		c.LaneColors = append(c.LaneColors[:lane], c.LaneColors[lane+1:]...)
 	}
+	if len(c.WipLimits) > lane {
+		c.WipLimits = append(c.WipLimits[:lane], c.WipLimits[lane+1:]...)
+	}
 }
 
 func (c *ToDoContent) InsertNewLane(addToLeft bool, laneTitle string, relativeToLaneIdx int) int {
@@ -111,17 +196,32 @@ func (c *ToDoContent) InsertNewLane(addToLeft bool, laneTitle string, relativeTo
 	c.Titles = append(c.Titles[:i], append([]string{laneTitle}, c.Titles[i:]...)...)
 	c.SortModes = append(c.SortModes[:i], append([]string{""}, c.SortModes[i:]...)...)
 	c.LaneColors = append(c.LaneColors[:i], append([]string{""}, c.LaneColors[i:]...)...)
+	c.padWipLimits(len(c.Titles) - 1)
+	c.WipLimits = append(c.WipLimits[:i], append([]int{0}, c.WipLimits[i:]...)...)
 
 	return i
 }
 
 func (c *ToDoContent) MoveItem(fromlane, fromidx, tolane, toidx int) {
 	item := c.Items[fromlane][fromidx]
+	c.updateDone(&item, fromlane, tolane, time.Now())
 	// https://github.com/golang/go/wiki/SliceTricks
 	c.Items[fromlane] = append(c.Items[fromlane][:fromidx], c.Items[fromlane][fromidx+1:]...)
 	c.Items[tolane] = append(c.Items[tolane][:toidx], append([]Item{item}, c.Items[tolane][toidx:]...)...)
 }
 
+// FindItem returns the lane and position of the item with the given GUID.
+func (c *ToDoContent) FindItem(guid string) (int, int, bool) {
+	for li := range c.Items {
+		for ii := range c.Items[li] {
+			if c.Items[li][ii].Guid == guid {
+				return li, ii, true
+			}
+		}
+	}
+	return -1, -1, false
+}
+
 func (c *ToDoContent) SetLaneSort(idx int, mode string) {
 	if idx >= 0 && idx < len(c.SortModes) {
 		c.SortModes[idx] = mode
@@ -141,6 +241,38 @@ func (c *ToDoContent) GetLaneColor(idx int) string {
 	return ""
 }
 
+// GetWipLimit returns the maximum number of tasks of a lane, 0 if the lane
+// has no limit.
+func (c *ToDoContent) GetWipLimit(idx int) int {
+	if idx >= 0 && idx < len(c.WipLimits) {
+		return c.WipLimits[idx]
+	}
+	return 0
+}
+
+func (c *ToDoContent) SetWipLimit(idx int, limit int) {
+	c.padWipLimits(len(c.Titles))
+	if idx >= 0 && idx < len(c.WipLimits) {
+		c.WipLimits[idx] = limit
+	}
+}
+
+// padWipLimits sizes the limits to n lanes, keeping the existing ones. Lanes
+// without an entry have no limit.
+func (c *ToDoContent) padWipLimits(n int) {
+	if len(c.WipLimits) != n {
+		limits := make([]int, n)
+		copy(limits, c.WipLimits)
+		c.WipLimits = limits
+	}
+}
+
+// OverWipLimit returns whether a lane has more tasks than its limit allows.
+func (c *ToDoContent) OverWipLimit(idx int) bool {
+	limit := c.GetWipLimit(idx)
+	return limit > 0 && len(c.Items[idx]) > limit
+}
+
 func (c *ToDoContent) SortLane(idx int) {
 	if idx >= 0 && idx < len(c.Items) {
 		sortItems(c.Items[idx], c.SortModes[idx])
@@ -148,33 +280,68 @@ func (c *ToDoContent) SortLane(idx int) {
 }
 
 func (c *ToDoContent) DelItem(lane, idx int) {
+	guid := c.Items[lane][idx].Guid
 	c.Items[lane] = append(c.Items[lane][:idx], c.Items[lane][idx+1:]...)
+	c.resolveLinks(guid)
 }
 
+// ArchiveItem moves an item to the archive, links of other items to it are
+// removed.
 func (c *ToDoContent) ArchiveItem(lane, idx int) error {
+	guid := c.Items[lane][idx].Guid
+	if c.remote != nil {
+		if err := c.remote.Archive(guid); err != nil {
+			return err
+		}
+		// the server saved the board once
+		c.Revision++
+		c.Items[lane] = append(c.Items[lane][:idx], c.Items[lane][idx+1:]...)
+		c.resolveLinks(guid)
+		return nil
+	}
+
+	if err := c.archive(c.Items[lane][idx], c.Titles[lane]); err != nil {
+		return err
+	}
+	c.Items[lane] = append(c.Items[lane][:idx], c.Items[lane][idx+1:]...)
+	c.resolveLinks(guid)
+	return nil
+}
+
+// archive writes an item to the archive folder, the file name contains the
+// time and the title of the lane the item was archived from.
+func (c *ToDoContent) archive(item Item, laneTitle string) error {
 	now := time.Now()
-	saveName, err := filenamify.FilenamifyV2(c.Titles[lane], func(options *filenamify.Options) {
+	saveName, err := filenamify.FilenamifyV2(laneTitle, func(options *filenamify.Options) {
 		options.Replacement = "_"
 	})
 	if err != nil {
 		return err
 	}
-	archiveItemFileName := fmt.Sprintf("%v.%v.json", now.Format("2006-01-02 15_04_05.000"), saveName)
+	archiveItemFileName := path.Join(c.archiveFolder, fmt.Sprintf("%v.%v.json", now.Format("2006-01-02 15_04_05.000"), saveName))
+	if _, err := os.Stat(archiveItemFileName); err == nil {
+		// several items archived within the same millisecond
+		archiveItemFileName = strings.TrimSuffix(archiveItemFileName, ".json") + "." + item.Guid + ".json"
+	}
 
-	item := c.Items[lane][idx]
 	item.IsArchived = true
 	item.LastUpdate = now.UTC().Format(time.RFC3339)
+	// timers do not run in the archive
+	item.Time = append([]TimeEntry{}, item.Time...)
+	for i := range item.Time {
+		if item.Time[i].End == "" {
+			item.Time[i].End = item.LastUpdate
+		}
+	}
 	if usr, errU := user.Current(); errU == nil {
 		item.UpdatedByName = usr.Username
 	}
 
-	cnt, _ := json.MarshalIndent(item, "", " ")
-	err = os.WriteFile(path.Join(c.archiveFolder, archiveItemFileName), cnt, 0644)
+	cnt, err := json.MarshalIndent(item, "", " ")
 	if err != nil {
 		return err
 	}
-	c.Items[lane] = append(c.Items[lane][:idx], c.Items[lane][idx+1:]...)
-	return nil
+	return c.writeFile(archiveItemFileName, cnt)
 }
 
 func (c *ToDoContent) AddItem(lane, idx int, title string, secondary string, priority int, due, color string) {
@@ -199,6 +366,7 @@ func (c *ToDoContent) AddItem(lane, idx int, title string, secondary string, pri
 		UserName:      userName,
 		UpdatedByName: userName,
 		Mode:          "",
+		Number:        c.nextNumber(),
 	}
 
 	c.Items[lane] = append(c.Items[lane][:idx], append([]Item{newItem}, c.Items[lane][idx:]...)...)
@@ -218,6 +386,7 @@ func (c *ToDoContent) normalize() {
 	if len(c.LaneColors) != len(c.Titles) {
 		c.LaneColors = make([]string, len(c.Titles))
 	}
+	c.padWipLimits(len(c.Titles))
 
 	for li := range c.Items {
 		for ii := range c.Items[li] {
@@ -252,23 +421,54 @@ func (c *ToDoContent) normalize() {
 			}
 		}
 	}
+	c.numberItems()
 }
 
 func (c *ToDoContent) Read() error {
 	c.readWriteMutex.Lock()
 	defer c.readWriteMutex.Unlock()
-	f, err := os.OpenFile(c.fname, os.O_RDONLY, os.FileMode(int(0600)))
-	if err == nil {
-		decoder := json.NewDecoder(f)
-		if err := decoder.Decode(c); err != nil {
-			f.Close()
+	if c.remote != nil {
+		data, err := c.remote.Load()
+		if err != nil {
 			return err
 		}
-		f.Close()
-		c.normalize()
-		return nil
+		return c.decode(data)
 	}
-	return err
+	data, err := c.readFile(c.fname)
+	if err != nil {
+		return err
+	}
+	return c.decode(data)
+}
+
+// Decode replaces the board with the given JSON document.
+func (c *ToDoContent) Decode(data []byte) error {
+	c.readWriteMutex.Lock()
+	defer c.readWriteMutex.Unlock()
+	return c.decode(data)
+}
+
+// decode replaces the exported fields of the board with the ones of the JSON
+// document. The document is read into a new board first, as Unmarshal keeps
+// the values of fields and items missing in the document.
+func (c *ToDoContent) decode(data []byte) error {
+	var fresh ToDoContent
+	if err := json.Unmarshal(data, &fresh); err != nil {
+		return err
+	}
+	dst, src := reflect.ValueOf(c).Elem(), reflect.ValueOf(&fresh).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Type().Field(i).IsExported() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	c.normalize()
+	return nil
+}
+
+// FileName returns the path of the todo.json file.
+func (c *ToDoContent) FileName() string {
+	return c.fname
 }
 
 func (c *ToDoContent) SetFileName(fname, archiveFolder, backupFolder string) {
@@ -277,25 +477,120 @@ func (c *ToDoContent) SetFileName(fname, archiveFolder, backupFolder string) {
 	c.backupFolder = backupFolder
 }
 
+// SetBackupDays sets the number of days the daily backups are kept. Older
+// backups are removed when the first backup of a day is written. With 0 all
+// backups are kept.
+func (c *ToDoContent) SetBackupDays(days int) {
+	c.backupDays = days
+}
+
+func (c *ToDoContent) pruneBackups(now time.Time) {
+	if c.backupDays <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(c.backupFolder)
+	if err != nil {
+		return
+	}
+	limit := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -c.backupDays)
+	for _, e := range entries {
+		day, err := time.Parse("2006-01-02.json", e.Name())
+		if err != nil || !day.Before(limit) {
+			continue
+		}
+		os.Remove(path.Join(c.backupFolder, e.Name()))
+	}
+}
+
+// SetRemote directs Read, Save and ArchiveItem to the given backend instead
+// of the local files.
+func (c *ToDoContent) SetRemote(r Remote) {
+	c.remote = r
+}
+
 func (c *ToDoContent) Save() error {
 	c.readWriteMutex.Lock()
 	defer c.readWriteMutex.Unlock()
 
-	cnt, _ := json.MarshalIndent(c, "", " ")
-
-	now := time.Now()
-	dayFileName := path.Join(c.backupFolder, fmt.Sprintf("%v.json", now.Format("2006-01-02")))
-	if _, err := os.Stat(dayFileName); errors.Is(err, os.ErrNotExist) {
-		err = os.WriteFile(dayFileName, cnt, 0644)
+	if c.remote != nil {
+		// the server stores the board as the next revision
+		cnt, err := json.MarshalIndent(c, "", " ")
 		if err != nil {
 			return err
 		}
+		if err := c.remote.Store(cnt); err != nil {
+			return err
+		}
+		c.Revision++
+		return nil
 	}
 
-	err := os.WriteFile(c.fname, cnt, 0644)
+	if c.fname == "" {
+		return errors.New("no file name set for saving the tasks")
+	}
+	unlock, err := lockFile(c.fname)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	return c.saveFile()
+}
 
+// saveFile writes todo.json as the next revision of the board and the daily
+// backup, the caller holds the lock of the file. Without backup folder no
+// backup is written. If another instance saved the board since it was read,
+// e.g. tasks moved here from another mode, ErrConflict is returned.
+func (c *ToDoContent) saveFile() error {
+	stored, err := c.storedRevision()
+	if err != nil {
+		return err
+	}
+	if stored >= 0 && stored != c.Revision {
+		return ErrConflict
+	}
+	c.Revision++
+	cnt, err := json.MarshalIndent(c, "", " ")
+	if err == nil {
+		err = c.writeFiles(cnt)
+	}
+	if err != nil {
+		c.Revision--
+		return err
+	}
 	return nil
 }
+
+// storedRevision returns the revision of todo.json, -1 if there is no file
+// or it cannot be parsed and will be replaced.
+func (c *ToDoContent) storedRevision() (int, error) {
+	data, err := c.readFile(c.fname)
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var stored struct{ Revision int }
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return -1, nil
+	}
+	return stored.Revision, nil
+}
+
+// writeFiles writes the daily backup, unless written before today, and
+// todo.json.
+func (c *ToDoContent) writeFiles(cnt []byte) error {
+	now := time.Now()
+	if c.backupFolder != "" {
+		dayFileName := path.Join(c.backupFolder, fmt.Sprintf("%v.json", now.Format("2006-01-02")))
+		if _, err := os.Stat(dayFileName); errors.Is(err, os.ErrNotExist) {
+			err = c.writeFile(dayFileName, cnt)
+			if err != nil {
+				return err
+			}
+			c.pruneBackups(now)
+		}
+	}
+
+	return c.writeFile(c.fname, cnt)
+}
@@ -1,7 +1,11 @@
 package model
 
 import (
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/cklukas/todo/internal/util"
 )
@@ -21,6 +25,21 @@ func TestInsertNewLane(t *testing.T) {
 	}
 }
 
+func TestInsertNewLaneKeepsWipLimits(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.InsertNewLane(false, "Review", 2)
+	c.WipLimits = []int{0, 3}
+	c.InsertNewLane(false, "Test", 3)
+	if len(c.WipLimits) != 5 || c.GetWipLimit(1) != 3 || c.GetWipLimit(4) != 0 {
+		t.Fatalf("unexpected limits %v", c.WipLimits)
+	}
+	c.InsertNewLane(true, "Ready", 1)
+	if len(c.WipLimits) != 6 || c.GetWipLimit(1) != 0 || c.GetWipLimit(2) != 3 {
+		t.Fatalf("unexpected limits %v", c.WipLimits)
+	}
+}
+
 func TestMoveItem(t *testing.T) {
 	c := &ToDoContent{}
 	c.InitializeNew()
@@ -130,3 +149,88 @@ func TestNormalizeLaneColors(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	backup := filepath.Join(dir, "backup")
+	if err := os.MkdirAll(backup, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	old := now.AddDate(0, 0, -10).Format("2006-01-02") + ".json"
+	recent := now.AddDate(0, 0, -2).Format("2006-01-02") + ".json"
+	for _, name := range []string{old, recent, "notes.json"} {
+		if err := os.WriteFile(filepath.Join(backup, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetFileName(filepath.Join(dir, "todo.json"), filepath.Join(dir, "archive"), backup)
+	c.SetBackupDays(5)
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(backup, old)); !os.IsNotExist(err) {
+		t.Fatalf("old backup not removed")
+	}
+	for _, name := range []string{recent, "notes.json", now.Format("2006-01-02") + ".json"} {
+		if _, err := os.Stat(filepath.Join(backup, name)); err != nil {
+			t.Fatalf("backup %s removed: %v", name, err)
+		}
+	}
+}
+
+func TestSaveWithoutBackupFolder(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetFileName(filepath.Join(t.TempDir(), "todo.json"), "", "")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(time.Now().Format("2006-01-02") + ".json"); !os.IsNotExist(err) {
+		t.Fatalf("backup written to the working directory")
+	}
+}
+
+func TestSaveInvalidBoard(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	fname := filepath.Join(t.TempDir(), "todo.json")
+	c.SetFileName(fname, "", "")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := os.ReadFile(fname)
+	c.Items[0][0].Estimate = math.Inf(1)
+	if err := c.Save(); err == nil {
+		t.Fatal("board which can not be encoded saved")
+	}
+	if data, _ := os.ReadFile(fname); string(data) != string(saved) {
+		t.Fatalf("todo.json changed to %q", data)
+	}
+}
+
+func TestDecodeClearedFields(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	item := &c.Items[0][0]
+	item.Tags = []string{"bug"}
+	item.Estimate = 3
+	item.Parent = "epic"
+	item.Links = []Link{{Type: LinkBlocks, Guid: "other"}}
+	guid := item.Guid
+
+	// another instance cleared the fields, they are omitted in its document
+	data := []byte(`{"Titles": ["To Do", "Doing", "Done"], "Items": [[{"Title": "task", "Guid": "` + guid + `", "Number": 1}], [], []]}`)
+	if err := c.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	if item := c.Items[0][0]; len(item.Tags) != 0 || item.Estimate != 0 || item.Parent != "" || len(item.Links) != 0 {
+		t.Fatalf("cleared fields restored: %+v", item)
+	}
+}
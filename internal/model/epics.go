@@ -0,0 +1,77 @@
+package model
+
+import "fmt"
+
+// Progress is the number of finished child tasks of an epic, which are in
+// the last lane, and the number of all its children on the board.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// Children returns the child tasks of a task with their lanes, in the order
+// of the board.
+func (c *ToDoContent) Children(guid string) []AgendaItem {
+	var res []AgendaItem
+	for lane, title := range c.Titles {
+		for _, item := range c.GetLaneItems(lane) {
+			if item.Parent == guid {
+				res = append(res, AgendaItem{Lane: lane, LaneTitle: title, Item: item})
+			}
+		}
+	}
+	return res
+}
+
+// OpenChildren returns the GUIDs of the child tasks of a task which are not
+// finished.
+func (c *ToDoContent) OpenChildren(guid string) []string {
+	var res []string
+	for _, child := range c.Children(guid) {
+		if child.Lane != c.DoneLane() {
+			res = append(res, child.Item.Guid)
+		}
+	}
+	return res
+}
+
+// EpicProgress returns the progress of all tasks with children, by GUID.
+func (c *ToDoContent) EpicProgress() map[string]Progress {
+	res := make(map[string]Progress)
+	for lane := range c.Items {
+		for _, item := range c.Items[lane] {
+			if item.Parent == "" {
+				continue
+			}
+			p := res[item.Parent]
+			p.Total++
+			if lane == c.DoneLane() {
+				p.Done++
+			}
+			res[item.Parent] = p
+		}
+	}
+	return res
+}
+
+// SetParent makes a task a child of the epic with the given GUID, an empty
+// GUID removes it from its epic. A task can not be a child of itself or of
+// one of its children.
+func (c *ToDoContent) SetParent(guid, parent string) error {
+	item := c.item(guid)
+	if item == nil {
+		return fmt.Errorf("task not found")
+	}
+	for p := parent; p != ""; {
+		if p == guid {
+			return fmt.Errorf("'%v' can not be part of its own epic", item.Title)
+		}
+		epic := c.item(p)
+		if epic == nil {
+			return fmt.Errorf("epic not found")
+		}
+		p = epic.Parent
+	}
+	item.Parent = parent
+	return nil
+}
@@ -0,0 +1,44 @@
+package model
+
+import "testing"
+
+func TestEpics(t *testing.T) {
+	dir := t.TempDir()
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	for i, title := range []string{"epic", "design", "build"} {
+		c.AddItem(0, i, title, "", 2, "", "")
+	}
+	c.AddItem(2, 0, "spec", "", 2, "", "")
+	epic := c.Items[0][0].Guid
+	for _, guid := range []string{c.Items[0][1].Guid, c.Items[0][2].Guid, c.Items[2][0].Guid} {
+		if err := c.SetParent(guid, epic); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.SetParent(epic, c.Items[0][1].Guid); err == nil {
+		t.Fatal("expected error for epic in its own child")
+	}
+	if err := c.SetParent(epic, epic); err == nil {
+		t.Fatal("expected error for task in itself")
+	}
+
+	if p := c.EpicProgress()[epic]; p.Done != 1 || p.Total != 3 {
+		t.Fatalf("progress %+v", p)
+	}
+	if children := c.Children(epic); len(children) != 3 || children[2].LaneTitle != "Done" {
+		t.Fatalf("children %+v", children)
+	}
+	if open := c.OpenChildren(epic); len(open) != 2 {
+		t.Fatalf("open children %v", open)
+	}
+
+	// children of an archived epic leave it
+	if err := c.ArchiveItem(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.EpicProgress()) != 0 || c.Items[0][0].Parent != "" {
+		t.Fatalf("children still in archived epic: %+v", c.Items[0])
+	}
+}
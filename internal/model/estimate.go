@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultEstimateUnit is the unit of estimates if none is configured.
+const DefaultEstimateUnit = "pt"
+
+// SetEstimateUnit sets the unit of the estimates of the board, e.g. "pt" or
+// "h". An empty unit selects the default.
+func (c *ToDoContent) SetEstimateUnit(unit string) {
+	c.estimateUnit = unit
+}
+
+// EstimateUnit returns the unit of the estimates of the board.
+func (c *ToDoContent) EstimateUnit() string {
+	if c.estimateUnit == "" {
+		return DefaultEstimateUnit
+	}
+	return c.estimateUnit
+}
+
+// LaneEstimate returns the sum of the estimates of the tasks of a lane.
+func (c *ToDoContent) LaneEstimate(idx int) float64 {
+	var sum float64
+	for _, item := range c.GetLaneItems(idx) {
+		sum += item.Estimate
+	}
+	return sum
+}
+
+// FormatEstimate returns an estimate with its unit, e.g. "13pt" or "2.5h".
+func FormatEstimate(estimate float64, unit string) string {
+	return strconv.FormatFloat(estimate, 'f', -1, 64) + unit
+}
+
+// ParseEstimate parses an estimate, optionally followed by the unit. An
+// empty text is no estimate (0).
+func ParseEstimate(text, unit string) (float64, error) {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), unit))
+	if text == "" {
+		return 0, nil
+	}
+	estimate, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	if err != nil || estimate < 0 || math.IsNaN(estimate) || math.IsInf(estimate, 0) {
+		return 0, fmt.Errorf("invalid estimate '%v'", text)
+	}
+	return estimate, nil
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimates(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(1, 0, "review", "", 2, "", "")
+	c.AddItem(1, 1, "deploy", "", 2, "", "")
+	if title := c.GetLaneTitle(1); title != " Doing (2) " {
+		t.Fatalf("title without estimates %q", title)
+	}
+	c.Items[1][0].Estimate = 5
+	c.Items[1][1].Estimate = 8
+	c.SetWipLimit(1, 3)
+	if title := c.GetLaneTitle(1); title != " Doing (2/3 · 13pt) " {
+		t.Fatalf("title %q", title)
+	}
+	c.SetEstimateUnit("h")
+	if s := Summarize("work", c, time.Now()); s.Lanes[1].Estimate != 13 || s.EstimateUnit != "h" {
+		t.Fatalf("summary %+v", s)
+	}
+
+	for text, want := range map[string]float64{"": 0, "3": 3, " 2.5h": 2.5, "1,5": 1.5} {
+		if got, err := ParseEstimate(text, "h"); err != nil || got != want {
+			t.Fatalf("%q: %v, %v", text, got, err)
+		}
+	}
+	for _, invalid := range []string{"abc", "-1", "3pt", "NaN", "Inf", "+Inf", "-inf"} {
+		if _, err := ParseEstimate(invalid, "h"); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+	if s := FormatEstimate(2.5, "h"); s != "2.5h" {
+		t.Fatalf("format %q", s)
+	}
+}
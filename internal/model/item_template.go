@@ -0,0 +1,128 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ItemTemplate is a named preset for new tasks of a mode. In Title, "{}" is
+// replaced by the text entered for the task; without "{}" the text is
+// appended. Due is an offset from the day the task is added, e.g. "+2d",
+// "+1w" or "+1m".
+type ItemTemplate struct {
+	Name      string
+	Title     string
+	Secondary string
+	Note      string
+	Priority  int
+	Color     string
+	Due       string
+}
+
+// TaskTitle returns the title of a task added with the template.
+func (t ItemTemplate) TaskTitle(text string) string {
+	switch {
+	case strings.Contains(t.Title, "{}"):
+		return strings.ReplaceAll(t.Title, "{}", text)
+	case text == "":
+		return t.Title
+	case t.Title == "":
+		return text
+	}
+	return t.Title + " " + text
+}
+
+// TaskPriority returns the priority of a task added with the template.
+func (t ItemTemplate) TaskPriority() int {
+	if t.Priority < 1 || t.Priority > 4 {
+		return 2
+	}
+	return t.Priority
+}
+
+// DueFromOffset returns the ISO date of a due offset such as "+2d", "+1w" or
+// "+1m", counted from now. "today" and "+0d" are today, an empty offset
+// returns an empty date.
+func DueFromOffset(offset string, now time.Time) (string, error) {
+	offset = strings.ToLower(strings.TrimSpace(offset))
+	if offset == "" {
+		return "", nil
+	}
+	if offset == "today" {
+		offset = "+0d"
+	}
+	if len(offset) < 3 || offset[0] != '+' {
+		return "", fmt.Errorf("invalid due offset '%v', use e.g. +2d, +1w or +1m", offset)
+	}
+	n, err := strconv.Atoi(offset[1 : len(offset)-1])
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("invalid due offset '%v', use e.g. +2d, +1w or +1m", offset)
+	}
+	switch offset[len(offset)-1] {
+	case 'd':
+		now = now.AddDate(0, 0, n)
+	case 'w':
+		now = now.AddDate(0, 0, 7*n)
+	case 'm':
+		now = now.AddDate(0, n, 0)
+	default:
+		return "", fmt.Errorf("invalid due offset '%v', use e.g. +2d, +1w or +1m", offset)
+	}
+	return now.Format("2006-01-02"), nil
+}
+
+// FindItemTemplate returns the item template with the given name (ignoring
+// case), or nil.
+func (c *ToDoContent) FindItemTemplate(name string) *ItemTemplate {
+	for i := range c.ItemTemplates {
+		if strings.EqualFold(c.ItemTemplates[i].Name, name) {
+			return &c.ItemTemplates[i]
+		}
+	}
+	return nil
+}
+
+// SetItemTemplate adds an item template or replaces the one with the same
+// name.
+func (c *ToDoContent) SetItemTemplate(t ItemTemplate) {
+	if existing := c.FindItemTemplate(t.Name); existing != nil {
+		*existing = t
+		return
+	}
+	c.ItemTemplates = append(c.ItemTemplates, t)
+}
+
+// RemoveItemTemplate removes an item template. It returns false if there is
+// no template with this name.
+func (c *ToDoContent) RemoveItemTemplate(name string) bool {
+	for i, t := range c.ItemTemplates {
+		if strings.EqualFold(t.Name, name) {
+			c.ItemTemplates = append(c.ItemTemplates[:i], c.ItemTemplates[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ItemTemplateNames returns the names of the item templates of the board.
+func (c *ToDoContent) ItemTemplateNames() []string {
+	names := make([]string, len(c.ItemTemplates))
+	for i, t := range c.ItemTemplates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// AddFromTemplate inserts a task created from an item template with the
+// given text at position idx of a lane.
+func (c *ToDoContent) AddFromTemplate(lane, idx int, t *ItemTemplate, text string, now time.Time) error {
+	due, err := DueFromOffset(t.Due, now)
+	if err != nil {
+		return err
+	}
+	c.AddItem(lane, idx, t.TaskTitle(text), t.Secondary, t.TaskPriority(), due, t.Color)
+	c.Items[lane][idx].Note = t.Note
+	return nil
+}
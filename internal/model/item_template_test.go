@@ -0,0 +1,57 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDueFromOffset(t *testing.T) {
+	now := time.Date(2024, 1, 31, 10, 0, 0, 0, time.Local)
+	for offset, want := range map[string]string{"": "", "today": "2024-01-31", "+2d": "2024-02-02", "+1w": "2024-02-07", "+1M": "2024-03-02"} {
+		if got, err := DueFromOffset(offset, now); err != nil || got != want {
+			t.Fatalf("offset %q: got %q, %v, want %q", offset, got, err, want)
+		}
+	}
+	for _, offset := range []string{"2d", "+d", "+2x", "+-1d"} {
+		if _, err := DueFromOffset(offset, now); err == nil {
+			t.Fatalf("offset %q accepted", offset)
+		}
+	}
+}
+
+func TestItemTemplates(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetItemTemplate(ItemTemplate{Name: "review", Title: "Code review: {}", Priority: 1, Color: "red", Due: "+2d", Note: "- [ ] tests"})
+	c.SetItemTemplate(ItemTemplate{Name: "call", Title: "Call"})
+	c.SetItemTemplate(ItemTemplate{Name: "Call", Title: "Phone"})
+	if len(c.ItemTemplates) != 2 || c.FindItemTemplate("CALL").Title != "Phone" {
+		t.Fatalf("unexpected templates %+v", c.ItemTemplates)
+	}
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+	if err := c.AddFromTemplate(1, 0, c.FindItemTemplate("review"), "PR 123", now); err != nil {
+		t.Fatal(err)
+	}
+	item := c.Items[1][0]
+	if item.Title != "Code review: PR 123" || item.Priority != 1 || item.Color != "red" || item.Due != "2024-05-12" || item.Note != "- [ ] tests" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+	if got := c.FindItemTemplate("call").TaskTitle("Bob"); got != "Phone Bob" {
+		t.Fatalf("title %q", got)
+	}
+
+	if !c.RemoveItemTemplate("call") || c.RemoveItemTemplate("call") || len(c.ItemTemplates) != 1 {
+		t.Fatalf("unexpected templates after removal %+v", c.ItemTemplates)
+	}
+
+	// a reload must drop templates removed meanwhile
+	data, _ := json.Marshal(&ToDoContent{Titles: []string{"To Do"}, Items: [][]Item{nil}})
+	if err := c.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ItemTemplates) != 0 {
+		t.Fatalf("stale templates %+v", c.ItemTemplates)
+	}
+}
@@ -0,0 +1,150 @@
+package model
+
+import "fmt"
+
+// Types of links between items.
+const (
+	LinkBlocks    = "blocks"
+	LinkBlockedBy = "blocked-by"
+	LinkRelates   = "relates-to"
+)
+
+// LinkTypes are the types of links between items.
+var LinkTypes = []string{LinkBlocks, LinkBlockedBy, LinkRelates}
+
+// Link connects an item to another item of the board, given by its GUID.
+// Links are stored on both items, with the inverse type on the other one.
+type Link struct {
+	Type string
+	Guid string
+}
+
+func inverseLinkType(t string) string {
+	switch t {
+	case LinkBlocks:
+		return LinkBlockedBy
+	case LinkBlockedBy:
+		return LinkBlocks
+	default:
+		return t
+	}
+}
+
+// item returns the item with the given GUID, or nil.
+func (c *ToDoContent) item(guid string) *Item {
+	if lane, idx, found := c.FindItem(guid); found {
+		return &c.Items[lane][idx]
+	}
+	return nil
+}
+
+// withoutLink returns the links without the ones to the given GUID.
+func withoutLink(links []Link, guid string) []Link {
+	var res []Link
+	for _, link := range links {
+		if link.Guid != guid {
+			res = append(res, link)
+		}
+	}
+	return res
+}
+
+// LinkItems links two items of the board, e.g. with LinkBlocks the first one
+// blocks the other one. A previous link between them is replaced.
+func (c *ToDoContent) LinkItems(guid, other, linkType string) error {
+	valid := false
+	for _, t := range LinkTypes {
+		valid = valid || t == linkType
+	}
+	if !valid {
+		return fmt.Errorf("invalid link type '%v', use one of: %v", linkType, LinkTypes)
+	}
+	if guid == other {
+		return fmt.Errorf("a task cannot be linked to itself")
+	}
+	from, to := c.item(guid), c.item(other)
+	if from == nil || to == nil {
+		return fmt.Errorf("task not found")
+	}
+	from.Links = append(withoutLink(from.Links, other), Link{Type: linkType, Guid: other})
+	to.Links = append(withoutLink(to.Links, guid), Link{Type: inverseLinkType(linkType), Guid: guid})
+	return nil
+}
+
+// UnlinkItems removes the link between two items. It returns false if they
+// were not linked.
+func (c *ToDoContent) UnlinkItems(guid, other string) bool {
+	found := false
+	for _, pair := range [][2]string{{guid, other}, {other, guid}} {
+		if item := c.item(pair[0]); item != nil {
+			links := withoutLink(item.Links, pair[1])
+			found = found || len(links) != len(item.Links)
+			item.Links = links
+		}
+	}
+	return found
+}
+
+// resolveLinks removes the links to an item which leaves the board, its
+// children leave the epic.
+func (c *ToDoContent) resolveLinks(guid string) {
+	for lane := range c.Items {
+		for i := range c.Items[lane] {
+			item := &c.Items[lane][i]
+			if len(item.Links) > 0 {
+				item.Links = withoutLink(item.Links, guid)
+			}
+			if item.Parent == guid {
+				item.Parent = ""
+			}
+		}
+	}
+}
+
+// LinkedItems returns the links of an item to items on the board and these
+// items. Links to items which are no longer on the board are skipped.
+func (c *ToDoContent) LinkedItems(item *Item) ([]Link, []Item) {
+	var links []Link
+	var items []Item
+	for _, link := range item.Links {
+		if other := c.item(link.Guid); other != nil {
+			links = append(links, link)
+			items = append(items, *other)
+		}
+	}
+	return links, items
+}
+
+// Blockers returns the items on the board which block the given item.
+func (c *ToDoContent) Blockers(item *Item) []Item {
+	var res []Item
+	links, items := c.LinkedItems(item)
+	for i, link := range links {
+		if link.Type == LinkBlockedBy {
+			res = append(res, items[i])
+		}
+	}
+	return res
+}
+
+// BlockedItems returns the GUIDs of the items which are blocked by other
+// items on the board.
+func (c *ToDoContent) BlockedItems() map[string]bool {
+	onBoard := make(map[string]bool)
+	for lane := range c.Items {
+		for _, item := range c.Items[lane] {
+			onBoard[item.Guid] = true
+		}
+	}
+	res := make(map[string]bool)
+	for lane := range c.Items {
+		for _, item := range c.Items[lane] {
+			for _, link := range item.Links {
+				if link.Type == LinkBlockedBy && onBoard[link.Guid] {
+					res[item.Guid] = true
+				}
+			}
+		}
+	}
+	return res
+}
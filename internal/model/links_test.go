@@ -0,0 +1,60 @@
+package model
+
+import "testing"
+
+func TestLinkItems(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.AddItem(0, 1, "deploy", "", 2, "", "")
+	c.AddItem(1, 0, "docs", "", 2, "", "")
+	review, deploy, docs := c.Items[0][0].Guid, c.Items[0][1].Guid, c.Items[1][0].Guid
+
+	if err := c.LinkItems(review, deploy, LinkBlocks); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LinkItems(docs, deploy, LinkRelates); err != nil {
+		t.Fatal(err)
+	}
+	for _, invalid := range [][3]string{{review, review, LinkBlocks}, {review, "unknown", LinkBlocks}, {review, docs, "needs"}} {
+		if err := c.LinkItems(invalid[0], invalid[1], invalid[2]); err == nil {
+			t.Fatalf("expected error for %v", invalid)
+		}
+	}
+	if blockers := c.Blockers(&c.Items[0][1]); len(blockers) != 1 || blockers[0].Guid != review {
+		t.Fatalf("blockers %+v", blockers)
+	}
+	if blocked := c.BlockedItems(); len(blocked) != 1 || !blocked[deploy] {
+		t.Fatalf("blocked %v", blocked)
+	}
+	links, items := c.LinkedItems(&c.Items[0][1])
+	if len(links) != 2 || links[0].Type != LinkBlockedBy || items[1].Title != "docs" || links[1].Type != LinkRelates {
+		t.Fatalf("links of deploy %+v", links)
+	}
+
+	// linking again replaces the link
+	if err := c.LinkItems(deploy, review, LinkRelates); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Items[0][0].Links) != 1 || c.Items[0][0].Links[0].Type != LinkRelates || len(c.BlockedItems()) != 0 {
+		t.Fatalf("link not replaced: %+v", c.Items[0][0].Links)
+	}
+	if !c.UnlinkItems(review, deploy) || c.UnlinkItems(review, deploy) || len(c.Items[0][0].Links) != 0 {
+		t.Fatalf("unlink failed: %+v", c.Items[0][0].Links)
+	}
+
+	// archiving resolves the links to the item
+	c.LinkItems(review, deploy, LinkBlocks)
+	dir := t.TempDir()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	if err := c.ArchiveItem(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if links := c.Items[0][0].Links; len(links) != 1 || links[0].Guid != docs {
+		t.Fatalf("links after archiving %+v", links)
+	}
+	c.DelItem(1, 0)
+	if links := c.Items[0][0].Links; len(links) != 0 {
+		t.Fatalf("links after deleting %+v", links)
+	}
+}
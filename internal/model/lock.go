@@ -0,0 +1,42 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockTimeout is the maximum time to wait for a lock, lockStale the age
+// after which a lock file is considered left over from a crashed process.
+// The timeout is longer, so a left over lock is taken over while waiting.
+const (
+	lockTimeout = 15 * time.Second
+	lockStale   = 10 * time.Second
+)
+
+// lockFile creates fname + ".lock" exclusively, waiting while another
+// process holds the lock. The returned function releases the lock.
+func lockFile(fname string) (func(), error) {
+	lockName := fname + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockName) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lockName); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockName)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("'%v' is locked by another process", fname)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
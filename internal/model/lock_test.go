@@ -0,0 +1,28 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileTakesOverStaleLock(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "todo.json")
+	// left over by a crashed process, stale in a second
+	if err := os.WriteFile(fname+".lock", []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Now().Add(-lockStale + time.Second)
+	if err := os.Chtimes(fname+".lock", created, created); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := lockFile(fname)
+	if err != nil {
+		t.Fatalf("stale lock not taken over: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(fname + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("lock not released: %v", err)
+	}
+}
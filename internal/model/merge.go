@@ -0,0 +1,97 @@
+package model
+
+import "strings"
+
+// matchLines returns for each line of a the index of the same line in b
+// according to a longest common subsequence, or -1.
+func matchLines(a, b []string) []int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for i < len(a) {
+		switch {
+		case j < len(b) && a[i] == b[j]:
+			match[i] = j
+			i++
+			j++
+		case j < len(b) && lcs[i][j+1] > lcs[i+1][j]:
+			match[i] = -1
+			j++
+		default:
+			match[i] = -1
+			i++
+		}
+	}
+	return match
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeText merges the changes of two versions of a text, mine and theirs,
+// both derived from base. Lines changed differently in both versions are
+// kept with conflict markers, the second result is true in this case.
+func MergeText(base, mine, theirs string) (string, bool) {
+	b := strings.Split(base, "\n")
+	m := strings.Split(mine, "\n")
+	t := strings.Split(theirs, "\n")
+	matchM, matchT := matchLines(b, m), matchLines(b, t)
+
+	var out []string
+	conflict := false
+	i, im, it := 0, 0, 0
+	for {
+		// the next base line kept in both versions
+		next := i
+		for next < len(b) && (matchM[next] < 0 || matchT[next] < 0) {
+			next++
+		}
+		endM, endT := len(m), len(t)
+		if next < len(b) {
+			endM, endT = matchM[next], matchT[next]
+		}
+		chunkB, chunkM, chunkT := b[i:next], m[im:endM], t[it:endT]
+		switch {
+		case equalLines(chunkM, chunkB):
+			out = append(out, chunkT...)
+		case equalLines(chunkT, chunkB), equalLines(chunkM, chunkT):
+			out = append(out, chunkM...)
+		default:
+			conflict = true
+			out = append(out, "<<<<<<< yours")
+			out = append(out, chunkM...)
+			out = append(out, "=======")
+			out = append(out, chunkT...)
+			out = append(out, ">>>>>>> other")
+		}
+		if next == len(b) {
+			break
+		}
+		out = append(out, b[next])
+		i, im, it = next+1, endM+1, endT+1
+	}
+	return strings.Join(out, "\n"), conflict
+}
@@ -0,0 +1,25 @@
+package model
+
+import "testing"
+
+func TestMergeText(t *testing.T) {
+	cases := []struct {
+		name, base, mine, theirs, want string
+		conflict                       bool
+	}{
+		{"unchanged", "a\nb", "a\nb", "a\nb", "a\nb", false},
+		{"only mine", "a\nb\nc", "a\nB\nc", "a\nb\nc", "a\nB\nc", false},
+		{"only theirs", "a\nb\nc", "a\nb\nc", "a\nb\nc\nd", "a\nb\nc\nd", false},
+		{"both", "a\nb\nc\nd", "A\nb\nc\nd", "a\nb\nc\nD", "A\nb\nc\nD", false},
+		{"same change", "a\nb", "a\nx", "a\nx", "a\nx", false},
+		{"insert and delete", "- [ ] one\n- [ ] two\n- [ ] three\n- [ ] four", "- [x] one\n- [ ] two\n- [ ] three\n- [ ] four\n- [ ] five",
+			"- [ ] one\n- [ ] two\n- [ ] four", "- [x] one\n- [ ] two\n- [ ] four\n- [ ] five", false},
+		{"conflict", "a\nb\nc", "a\nmine\nc", "a\ntheirs\nc", "a\n<<<<<<< yours\nmine\n=======\ntheirs\n>>>>>>> other\nc", true},
+	}
+	for _, c := range cases {
+		got, conflict := MergeText(c.base, c.mine, c.theirs)
+		if got != c.want || conflict != c.conflict {
+			t.Errorf("%v: got %q (conflict %v), want %q (conflict %v)", c.name, got, conflict, c.want, c.conflict)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/flytam/filenamify"
+)
+
+// ModeDir returns the data directory of the given mode relative to the home
+// directory. The default mode "main" is stored directly in ".todo", all other
+// modes below ".todo/mode".
+func ModeDir(mode string) (string, error) {
+	if mode == "" || mode == "main" {
+		return ".todo", nil
+	}
+	saveName, err := filenamify.FilenamifyV2(mode, func(options *filenamify.Options) {
+		options.Replacement = "_"
+	})
+	if err != nil {
+		return "", err
+	}
+	return path.Join(".todo", "mode", saveName), nil
+}
+
+// ListValidModes returns "main" followed by all mode directories below
+// todoDirModes which contain a todo.json file. The second result is the index
+// of activeMode within the returned list.
+func ListValidModes(todoDirModes, activeMode string) ([]string, int, error) {
+	activeModeIndex := 0
+	modes := make([]string, 0)
+	modes = append(modes, "main")
+	dirEntries, err := os.ReadDir(todoDirModes)
+	if err != nil {
+		return modes, 0, err
+	}
+
+	idx := 0
+	for _, di := range dirEntries {
+		if di.Name() == "main" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(todoDirModes, di.Name(), "todo.json")); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if di.IsDir() && !strings.HasPrefix(di.Name(), ".") {
+			modes = append(modes, di.Name())
+			idx++
+			if di.Name() == activeMode {
+				activeModeIndex = idx
+			}
+		}
+	}
+
+	return modes, activeModeIndex, nil
+}
+
+// OpenMode reads the todo.json file of the given mode below home, using the
+// cipher for encrypted modes (may be nil). Missing directories are created
+// and a new board is initialized if the mode does not exist yet. The file
+// names of the returned content are set, so it can be saved directly.
+func OpenMode(home, mode string, cipher Cipher) (*ToDoContent, error) {
+	todoDir, err := ModeDir(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveDir := path.Join(home, todoDir, "archive")
+	backupDir := path.Join(home, todoDir, "backup")
+	for _, dir := range []string{archiveDir, backupDir, path.Join(home, todoDir, "mode")} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+
+	fname := path.Join(home, todoDir, "todo.json")
+	content := new(ToDoContent)
+	content.SetCipher(cipher)
+	if err := content.ReadFromFile(fname); err != nil {
+		if errors.Is(err, ErrEncrypted) || (cipher != nil && !errors.Is(err, os.ErrNotExist)) {
+			return nil, err
+		}
+		content.InitializeNew()
+	}
+	content.SetFileName(fname, archiveDir, backupDir)
+	return content, nil
+}
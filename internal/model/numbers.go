@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ref returns the short reference of an item, e.g. "#42", empty if the item
+// has no number yet.
+func (item *Item) Ref() string {
+	if item.Number <= 0 {
+		return ""
+	}
+	return "#" + strconv.Itoa(item.Number)
+}
+
+// lastNumber returns the highest number known for the board.
+func (c *ToDoContent) lastNumber() int {
+	last := c.LastNumber
+	for lane := range c.Items {
+		for _, item := range c.Items[lane] {
+			if item.Number > last {
+				last = item.Number
+			}
+		}
+	}
+	return last
+}
+
+// nextNumber hands out the next number of the board. The last number is
+// stored with the board, an instance which handed out the same number saves
+// a board changed since it was read and gets ErrConflict; it adds the task
+// again to the reloaded board, which gives the next free number.
+func (c *ToDoContent) nextNumber() int {
+	c.LastNumber = c.lastNumber() + 1
+	return c.LastNumber
+}
+
+// numberItems numbers the items without number in the order of their
+// creation. The numbers given stay unchanged.
+func (c *ToDoContent) numberItems() {
+	var items []*Item
+	for lane := range c.Items {
+		for i := range c.Items[lane] {
+			if c.Items[lane][i].Number <= 0 {
+				items = append(items, &c.Items[lane][i])
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Created < items[j].Created
+	})
+	c.LastNumber = c.lastNumber()
+	for _, item := range items {
+		item.Number = c.nextNumber()
+	}
+}
+
+// FindRef returns the lane and position of the item with the given
+// reference: its number ("42" or "#42"), its GUID or a unique prefix of at
+// least four characters of the GUID.
+func (c *ToDoContent) FindRef(ref string) (int, int, bool) {
+	ref = strings.TrimSpace(ref)
+	if n, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		for lane := range c.Items {
+			for i, item := range c.Items[lane] {
+				if item.Number == n {
+					return lane, i, true
+				}
+			}
+		}
+		return -1, -1, false
+	}
+	if lane, i, found := c.FindItem(ref); found || len(ref) < 4 {
+		return lane, i, found
+	}
+	lane, pos, matches := -1, -1, 0
+	for l := range c.Items {
+		for i, item := range c.Items[l] {
+			if strings.HasPrefix(item.Guid, ref) {
+				lane, pos = l, i
+				matches++
+			}
+		}
+	}
+	if matches != 1 {
+		return -1, -1, false
+	}
+	return lane, pos, true
+}
+
+// ResolveRef returns the GUID of the item with the given reference, see
+// FindRef.
+func (c *ToDoContent) ResolveRef(ref string) (string, error) {
+	lane, pos, found := c.FindRef(ref)
+	if !found {
+		return "", fmt.Errorf("task '%v' not found", ref)
+	}
+	return c.Items[lane][pos].Guid, nil
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAddItemNumbers(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "first", "", 2, "", "")
+	c.AddItem(1, 0, "second", "", 2, "", "")
+	if c.Items[0][0].Number != 1 || c.Items[1][0].Number != 2 || c.Items[1][0].Ref() != "#2" {
+		t.Fatalf("unexpected numbers %v, %v", c.Items[0][0].Number, c.Items[1][0].Number)
+	}
+	// numbers are not reused when tasks leave the board
+	c.Items[1] = nil
+	c.AddItem(1, 0, "third", "", 2, "", "")
+	if c.Items[1][0].Number != 3 {
+		t.Fatalf("number reused: %v", c.Items[1][0].Number)
+	}
+}
+
+func TestNumberItems(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.Items[0] = []Item{
+		{Guid: "c", Title: "newest", Created: "2024-05-03T10:00:00Z"},
+		{Guid: "a", Title: "oldest", Created: "2024-05-01T10:00:00Z"},
+	}
+	c.Items[1] = []Item{{Guid: "b", Title: "middle", Created: "2024-05-02T10:00:00Z"}}
+	c.normalize()
+	if c.Items[0][1].Number != 1 || c.Items[1][0].Number != 2 || c.Items[0][0].Number != 3 || c.LastNumber != 3 {
+		t.Fatalf("not numbered in creation order: %+v", c.Items)
+	}
+
+	// numbers given are kept, only tasks without number are numbered
+	c.Items[1][0].Number = 1
+	c.Items[1] = append(c.Items[1], Item{Guid: "d", Title: "added", Created: "2024-04-01T10:00:00Z"})
+	c.normalize()
+	if c.Items[0][1].Number != 1 || c.Items[1][0].Number != 1 || c.Items[0][0].Number != 3 || c.Items[1][1].Number != 4 {
+		t.Fatalf("numbers changed: %+v", c.Items)
+	}
+}
+
+func TestNumbersOfInstances(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "todo.json")
+	first, second := &ToDoContent{}, &ToDoContent{}
+	first.InitializeNew()
+	first.SetFileName(fname, "", "")
+	if err := first.Save(); err != nil {
+		t.Fatal(err)
+	}
+	second.SetFileName(fname, "", "")
+	if err := second.Read(); err != nil {
+		t.Fatal(err)
+	}
+	// both instances add a task before seeing the other one
+	first.AddItem(0, 0, "first", "", 2, "", "")
+	second.AddItem(0, 0, "second", "", 2, "", "")
+	if err := first.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Save(); err != ErrConflict {
+		t.Fatalf("second instance saved a number handed out twice: %v", err)
+	}
+	if err := second.Read(); err != nil {
+		t.Fatal(err)
+	}
+	second.AddItem(0, 0, "second", "", 2, "", "")
+	if second.Items[0][0].Number != 2 || second.Items[0][1].Number != 1 {
+		t.Fatalf("unexpected numbers %+v", second.Items[0])
+	}
+}
+
+func TestFindRef(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.Items[0] = []Item{{Guid: "abcd-1", Number: 7}, {Guid: "abcd-2", Number: 8}, {Guid: "efgh-3", Number: 9}}
+	for ref, want := range map[string]int{"7": 0, "#8": 1, " 9 ": 2, "abcd-2": 1, "efgh": 2} {
+		if lane, pos, found := c.FindRef(ref); !found || lane != 0 || pos != want {
+			t.Fatalf("FindRef(%q) = %v, %v, %v", ref, lane, pos, found)
+		}
+	}
+	for _, ref := range []string{"", "10", "#x", "abcd", "ef"} {
+		if _, _, found := c.FindRef(ref); found {
+			t.Fatalf("FindRef(%q) found a task", ref)
+		}
+	}
+	if guid, err := c.ResolveRef("#9"); err != nil || guid != "efgh-3" {
+		t.Fatalf("ResolveRef: %v, %v", guid, err)
+	}
+	if _, err := c.ResolveRef("#10"); err == nil {
+		t.Fatalf("unknown number resolved")
+	}
+}
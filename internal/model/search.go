@@ -0,0 +1,28 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Matches returns whether an item matches a search text: its number ("42" or
+// "#42") or a part of its title, note or a tag, ignoring case.
+func (item *Item) Matches(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return true
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(text, "#")); err == nil && n == item.Number {
+		return true
+	}
+	text = strings.ToLower(text)
+	if strings.Contains(strings.ToLower(item.Title), text) || strings.Contains(strings.ToLower(item.Note), text) {
+		return true
+	}
+	for _, tag := range item.Tags {
+		if strings.Contains(strings.ToLower("#"+tag), text) {
+			return true
+		}
+	}
+	return false
+}
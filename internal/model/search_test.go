@@ -0,0 +1,17 @@
+package model
+
+import "testing"
+
+func TestItemMatches(t *testing.T) {
+	item := &Item{Title: "Fix Login", Note: "see ticket", Tags: []string{"bug"}, Number: 42}
+	for _, text := range []string{"", "42", "#42", " #42 ", "login", "TICKET", "#bug", "bu"} {
+		if !item.Matches(text) {
+			t.Fatalf("%q does not match", text)
+		}
+	}
+	for _, text := range []string{"4", "#4", "43", "logout", "#fix"} {
+		if item.Matches(text) {
+			t.Fatalf("%q matches", text)
+		}
+	}
+}
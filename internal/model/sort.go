@@ -12,6 +12,7 @@ const (
 	SortCreated  = "created"
 	SortModified = "modified"
 	SortPriority = "priority"
+	SortEstimate = "estimate"
 )
 
 func PriorityMark(p int) string {
@@ -21,7 +22,7 @@ func PriorityMark(p int) string {
 	case 3:
 		return "↓"
 	case 4:
-		return "⌛"
+		return "⇊"
 	default:
 		return ""
 	}
@@ -61,6 +62,11 @@ func sortItems(items []Item, mode string) {
 		sort.SliceStable(items, func(i, j int) bool {
 			return items[i].Priority < items[j].Priority
 		})
+	case SortEstimate:
+		// tasks without estimate come last
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Estimate > 0 && (items[j].Estimate == 0 || items[i].Estimate < items[j].Estimate)
+		})
 	}
 }
 
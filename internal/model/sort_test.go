@@ -22,3 +22,15 @@ func TestSortByDue(t *testing.T) {
 		t.Fatalf("due sort failed: %#v", items)
 	}
 }
+
+func TestSortByEstimate(t *testing.T) {
+	items := []Item{
+		{Title: "t1", Estimate: 5},
+		{Title: "t2"},
+		{Title: "t3", Estimate: 0.5},
+	}
+	sortItems(items, SortEstimate)
+	if items[0].Title != "t3" || items[1].Title != "t1" || items[2].Title != "t2" {
+		t.Fatalf("estimate sort failed: %#v", items)
+	}
+}
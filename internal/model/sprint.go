@@ -0,0 +1,273 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sprint is an iteration of a board: the tasks committed to it (by GUID)
+// and its start and end date (ISO dates). Closed is the date the sprint was
+// closed, empty while it runs.
+type Sprint struct {
+	Name   string
+	Start  string
+	End    string
+	Items  []string `json:",omitempty"`
+	Closed string   `json:",omitempty"`
+}
+
+// SprintTask is a committed task in the report of a sprint. Done is the date
+// the task was finished, empty if it is not finished.
+type SprintTask struct {
+	Guid     string  `json:"guid"`
+	Title    string  `json:"title"`
+	Lane     string  `json:"lane"`
+	Archived bool    `json:"archived,omitempty"`
+	Estimate float64 `json:"estimate"`
+	Done     string  `json:"done,omitempty"`
+}
+
+// SprintDay is the state of a sprint at the end of a day: the committed
+// work, the work done, the work remaining and the remaining work of an even
+// burndown.
+type SprintDay struct {
+	Date      string  `json:"date"`
+	Scope     float64 `json:"scope"`
+	Done      float64 `json:"done"`
+	Remaining float64 `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+// SprintReport is the progress of a sprint. The work is measured in the
+// estimate unit, or in tasks if no committed task is estimated. Days lists
+// the days from the start until today or the end of the sprint, Length is the
+// number of days of the whole sprint.
+type SprintReport struct {
+	Name      string       `json:"name"`
+	Start     string       `json:"start"`
+	End       string       `json:"end"`
+	Closed    string       `json:"closed,omitempty"`
+	Unit      string       `json:"unit"`
+	Scope     float64      `json:"scope"`
+	Done      float64      `json:"done"`
+	Remaining float64      `json:"remaining"`
+	Length    int          `json:"length"`
+	Tasks     []SprintTask `json:"tasks"`
+	Days      []SprintDay  `json:"days"`
+}
+
+// DoneLane returns the last lane of the board, the tasks there are finished.
+func (c *ToDoContent) DoneLane() int {
+	return len(c.Titles) - 1
+}
+
+// updateDone records when a task moved to the last lane, and clears the
+// time when it moved back.
+func (c *ToDoContent) updateDone(item *Item, from, to int, now time.Time) {
+	switch {
+	case to != c.DoneLane():
+		item.Done = ""
+	case from != to || item.Done == "":
+		item.Done = now.UTC().Format(time.RFC3339)
+	}
+}
+
+// ActiveSprint returns the running sprint, nil if there is none.
+func (c *ToDoContent) ActiveSprint() *Sprint {
+	for i := len(c.Sprints) - 1; i >= 0; i-- {
+		if c.Sprints[i].Closed == "" {
+			return &c.Sprints[i]
+		}
+	}
+	return nil
+}
+
+// FindSprint returns the sprint with the given name, or for an empty name the
+// running sprint or else the last one.
+func (c *ToDoContent) FindSprint(name string) (*Sprint, error) {
+	if name == "" {
+		if s := c.ActiveSprint(); s != nil {
+			return s, nil
+		}
+		if len(c.Sprints) == 0 {
+			return nil, fmt.Errorf("no sprint found")
+		}
+		return &c.Sprints[len(c.Sprints)-1], nil
+	}
+	for i := range c.Sprints {
+		if c.Sprints[i].Name == name {
+			return &c.Sprints[i], nil
+		}
+	}
+	return nil, fmt.Errorf("sprint '%v' not found", name)
+}
+
+// OpenTasks returns the GUIDs of the tasks not in the last lane, or if lanes
+// are given of the tasks in these lanes.
+func (c *ToDoContent) OpenTasks(lanes ...int) []string {
+	var res []string
+	for lane := range c.Titles {
+		selected := len(lanes) == 0 && lane != c.DoneLane()
+		for _, l := range lanes {
+			selected = selected || l == lane
+		}
+		if !selected {
+			continue
+		}
+		for _, item := range c.GetLaneItems(lane) {
+			res = append(res, item.Guid)
+		}
+	}
+	return res
+}
+
+// StartSprint starts a sprint with the given tasks. Only one sprint runs at a
+// time.
+func (c *ToDoContent) StartSprint(name string, start, end time.Time, guids []string) error {
+	if name == "" {
+		return fmt.Errorf("the sprint needs a name")
+	}
+	if s := c.ActiveSprint(); s != nil {
+		return fmt.Errorf("sprint '%v' is still running", s.Name)
+	}
+	for _, s := range c.Sprints {
+		if s.Name == name {
+			return fmt.Errorf("sprint '%v' already exists", name)
+		}
+	}
+	if end.Before(start) {
+		return fmt.Errorf("the sprint ends before it starts")
+	}
+	c.Sprints = append(c.Sprints, Sprint{
+		Name:  name,
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+		Items: guids,
+	})
+	return nil
+}
+
+// CloseSprint closes the running sprint and returns the GUIDs of its
+// unfinished tasks. If backlog is a lane, these tasks are moved there.
+func (c *ToDoContent) CloseSprint(now time.Time, backlog int) ([]string, error) {
+	s := c.ActiveSprint()
+	if s == nil {
+		return nil, fmt.Errorf("no sprint is running")
+	}
+	s.Closed = now.Format("2006-01-02")
+	var open []string
+	for _, guid := range s.Items {
+		if lane, _, found := c.FindItem(guid); found && lane != c.DoneLane() {
+			open = append(open, guid)
+		}
+	}
+	if backlog >= 0 {
+		c.MoveItems(open, backlog)
+	}
+	return open, nil
+}
+
+// SprintReport returns the progress of a sprint, see FindSprint. Archived
+// tasks count as finished.
+func (c *ToDoContent) SprintReport(name string, now time.Time) (SprintReport, error) {
+	s, err := c.FindSprint(name)
+	if err != nil {
+		return SprintReport{}, err
+	}
+	loc := now.Location()
+	start, err := time.ParseInLocation("2006-01-02", s.Start, loc)
+	if err != nil {
+		return SprintReport{}, fmt.Errorf("sprint '%v': invalid start '%v'", s.Name, s.Start)
+	}
+	end, err := time.ParseInLocation("2006-01-02", s.End, loc)
+	if err != nil {
+		return SprintReport{}, fmt.Errorf("sprint '%v': invalid end '%v'", s.Name, s.End)
+	}
+	r := SprintReport{Name: s.Name, Start: s.Start, End: s.End, Closed: s.Closed, Unit: c.EstimateUnit(), Tasks: []SprintTask{}, Days: []SprintDay{}}
+
+	doneDate := func(item Item) string {
+		done := item.Done
+		if done == "" {
+			done = item.LastUpdate
+		}
+		if t, err := time.Parse(time.RFC3339, done); err == nil {
+			return t.In(loc).Format("2006-01-02")
+		}
+		return s.Start
+	}
+	committed := make(map[string]bool, len(s.Items))
+	for _, guid := range s.Items {
+		committed[guid] = true
+	}
+	found := make(map[string]SprintTask)
+	for lane, title := range c.Titles {
+		for _, item := range c.GetLaneItems(lane) {
+			if committed[item.Guid] {
+				task := SprintTask{Guid: item.Guid, Title: item.Title, Lane: title, Estimate: item.Estimate}
+				if lane == c.DoneLane() {
+					task.Done = doneDate(item)
+				}
+				found[item.Guid] = task
+			}
+		}
+	}
+	archived, err := c.ArchivedItems()
+	if err != nil {
+		return r, err
+	}
+	for _, a := range archived {
+		if _, ok := found[a.Item.Guid]; committed[a.Item.Guid] && !ok {
+			found[a.Item.Guid] = SprintTask{Guid: a.Item.Guid, Title: a.Item.Title, Lane: a.Lane, Archived: true, Estimate: a.Item.Estimate, Done: doneDate(a.Item)}
+		}
+	}
+	// tasks deleted from the board are not part of the sprint anymore
+	estimated := false
+	for _, guid := range s.Items {
+		if task, ok := found[guid]; ok {
+			r.Tasks = append(r.Tasks, task)
+			estimated = estimated || task.Estimate > 0
+		}
+	}
+	if !estimated {
+		r.Unit = "tasks"
+	}
+	work := func(task SprintTask) float64 {
+		if estimated {
+			return task.Estimate
+		}
+		return 1
+	}
+	for _, task := range r.Tasks {
+		r.Scope += work(task)
+		if task.Done != "" {
+			r.Done += work(task)
+		}
+	}
+	r.Remaining = r.Scope - r.Done
+
+	r.Length = int(end.Sub(start).Hours()/24+0.5) + 1
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if s.Closed != "" {
+		if closed, err := time.ParseInLocation("2006-01-02", s.Closed, loc); err == nil {
+			last = closed
+		}
+	}
+	if last.After(end) {
+		last = end
+	}
+	for i, day := 0, start; !day.After(last); i, day = i+1, day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		d := SprintDay{Date: date, Scope: r.Scope, Ideal: r.Scope}
+		if r.Length > 1 {
+			d.Ideal = r.Scope * float64(r.Length-1-i) / float64(r.Length-1)
+		}
+		for _, task := range r.Tasks {
+			if task.Done != "" && task.Done <= date {
+				d.Done += work(task)
+			}
+		}
+		d.Remaining = d.Scope - d.Done
+		r.Days = append(r.Days, d)
+	}
+	return r, nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSprints(t *testing.T) {
+	dir := t.TempDir()
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	for i, title := range []string{"review", "deploy", "docs", "release"} {
+		c.AddItem(0, i, title, "", 2, "", "")
+	}
+	c.AddItem(2, 0, "old", "", 2, "", "")
+	start := time.Date(2024, 5, 6, 0, 0, 0, 0, time.Local)
+	if err := c.StartSprint("s1", start, start.AddDate(0, 0, 4), c.OpenTasks()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.StartSprint("s2", start, start, nil); err == nil {
+		t.Fatal("expected error for second running sprint")
+	}
+	if s := c.ActiveSprint(); s == nil || len(s.Items) != 4 || s.End != "2024-05-10" {
+		t.Fatalf("active sprint %+v", s)
+	}
+
+	// review is finished on the second day, deploy is archived (now)
+	c.MoveItem(0, 0, 2, 0)
+	if c.Items[2][0].Done == "" {
+		t.Fatal("done time not set")
+	}
+	c.Items[2][0].Done = start.Add(26 * time.Hour).UTC().Format(time.RFC3339)
+	c.MoveItems([]string{c.Items[0][2].Guid}, 1)
+	if err := c.ArchiveItem(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	now := start.AddDate(0, 0, 2).Add(12 * time.Hour)
+	r, err := c.SprintReport("", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Unit != "tasks" || r.Scope != 4 || r.Done != 2 || r.Length != 5 || len(r.Days) != 3 {
+		t.Fatalf("report %+v", r)
+	}
+	if r.Days[0].Remaining != 4 || r.Days[1].Remaining != 3 || r.Days[2].Remaining != 3 || r.Days[1].Ideal != 3 {
+		t.Fatalf("days %+v", r.Days)
+	}
+
+	// with estimates the work is measured in the estimate unit
+	lane, pos, _ := c.FindItem(r.Tasks[2].Guid)
+	c.Items[lane][pos].Estimate = 5
+	if r, _ = c.SprintReport("s1", now); r.Unit != "pt" || r.Scope != 5 || r.Remaining != 5 {
+		t.Fatalf("estimated report %+v", r)
+	}
+
+	open, err := c.CloseSprint(now, 0)
+	if err != nil || len(open) != 2 || c.ActiveSprint() != nil {
+		t.Fatalf("open tasks %v, %v", open, err)
+	}
+	if len(c.Items[0]) != 2 || len(c.Items[1]) != 0 {
+		t.Fatalf("unfinished tasks not moved to the backlog: %+v", c.Items)
+	}
+	if _, err := c.FindSprint("s3"); err == nil {
+		t.Fatal("expected error for unknown sprint")
+	}
+}
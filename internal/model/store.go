@@ -0,0 +1,410 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MovedMarker is created in the directory of a mode which is merged into
+// another mode, renamed or removed, so running instances showing the mode
+// notice the change. The mode replacing it is returned by MovedTo.
+const MovedMarker = "moved_to"
+
+// movedFile records the modes which were merged, renamed or removed.
+const movedFile = "moved.json"
+
+// movedKeep is the time entries are kept in the moved file.
+const movedKeep = time.Hour
+
+// Lane targets for Merge besides the lane indices of the target mode.
+const (
+	// LaneNew adds the lane to the target mode.
+	LaneNew = -1
+	// LaneArchive archives the tasks of the lane.
+	LaneArchive = -2
+)
+
+// ModeStore gives access to the modes stored below a home directory.
+type ModeStore struct {
+	Home string
+	// Cipher returns the cipher of an encrypted mode and nil for plain
+	// modes. If not set, all modes are read without encryption.
+	Cipher func(mode string) (Cipher, error)
+}
+
+// NewModeStore returns the store for the modes below home.
+func NewModeStore(home string, cipher func(mode string) (Cipher, error)) *ModeStore {
+	return &ModeStore{Home: home, Cipher: cipher}
+}
+
+func isMainMode(mode string) bool {
+	return mode == "" || mode == "main"
+}
+
+// Dir returns the data directory of a mode.
+func (s *ModeStore) Dir(mode string) (string, error) {
+	dir, err := ModeDir(mode)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(s.Home, dir), nil
+}
+
+// Exists returns whether the mode has a todo.json file.
+func (s *ModeStore) Exists(mode string) bool {
+	dir, err := s.Dir(mode)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path.Join(dir, "todo.json"))
+	return err == nil
+}
+
+// Modes returns the names of all modes, starting with "main".
+func (s *ModeStore) Modes() ([]string, error) {
+	modes, _, err := ListValidModes(path.Join(s.Home, ".todo", "mode"), "")
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return modes, err
+}
+
+func (s *ModeStore) cipher(mode string) (Cipher, error) {
+	if s.Cipher == nil {
+		return nil, nil
+	}
+	return s.Cipher(mode)
+}
+
+// Open reads the board of a mode, a new board is created for unknown modes.
+func (s *ModeStore) Open(mode string) (*ToDoContent, error) {
+	cipher, err := s.cipher(mode)
+	if err != nil {
+		return nil, err
+	}
+	return OpenMode(s.Home, mode, cipher)
+}
+
+// Create adds a new mode with the lanes and tasks of a template, nil for the
+// default lanes.
+func (s *ModeStore) Create(mode string, t *BoardTemplate) error {
+	if s.Exists(mode) {
+		return fmt.Errorf("mode '%v' already exists", mode)
+	}
+	c, err := s.Open(mode)
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		c.ApplyTemplate(t)
+	}
+	return c.Save()
+}
+
+// Update reads the board of an existing mode, applies f and saves the
+// result. The file is locked meanwhile, so concurrent saves of running
+// instances are not lost; they reload the board when notified of the change.
+func (s *ModeStore) Update(mode string, f func(c *ToDoContent) error) error {
+	if !s.Exists(mode) {
+		return fmt.Errorf("mode '%v' not found", mode)
+	}
+	dir, err := s.Dir(mode)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path.Join(dir, "todo.json"))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, err := s.Open(mode)
+	if err != nil {
+		return err
+	}
+	if err := f(c); err != nil {
+		return err
+	}
+	return c.saveFile()
+}
+
+// TransferItem adds a task of the origin mode to the end of a lane of the
+// target mode and records the origin in its Mode field. A moved task keeps
+// its GUID and is removed from the origin, a copy gets a new one.
+func (s *ModeStore) TransferItem(item Item, origin, target string, lane int, copy bool) error {
+	return s.TransferItems([]Item{item}, origin, target, lane, copy)
+}
+
+// TransferItems adds several tasks in one update of the target mode, see
+// TransferItem. Moved tasks are then removed in one update of the origin,
+// running instances showing it reload the board. If they can not be removed,
+// they are taken out of the target again, so a task is never in both modes.
+func (s *ModeStore) TransferItems(items []Item, origin, target string, lane int, copy bool) error {
+	if origin == target {
+		return errors.New("the task is already in this mode")
+	}
+	guids := make([]string, len(items))
+	for i, item := range items {
+		guids[i] = item.Guid
+	}
+	err := s.Update(target, func(c *ToDoContent) error {
+		if lane < 0 || lane >= len(c.Items) {
+			return fmt.Errorf("invalid lane %v in mode '%v'", lane, target)
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		for _, item := range items {
+			item.Mode = origin
+			if copy {
+				item.Guid = uuid.NewString()
+				item.LastUpdate = now
+			}
+			item.Number = c.nextNumber()
+			c.Items[lane] = append(c.Items[lane], item)
+		}
+		return nil
+	})
+	if err != nil || copy {
+		return err
+	}
+	remove := func(c *ToDoContent) error {
+		c.RemoveItems(guids)
+		return nil
+	}
+	if err := s.Update(origin, remove); err != nil {
+		if errBack := s.Update(target, remove); errBack != nil {
+			return fmt.Errorf("%v, the tasks are also in mode '%v': %v", err, target, errBack)
+		}
+		return err
+	}
+	return nil
+}
+
+// MatchLanes returns for each source lane the index of the target lane with
+// the same title (ignoring case), or LaneNew if there is none.
+func MatchLanes(source, target []string) []int {
+	res := make([]int, len(source))
+	for i, title := range source {
+		res[i] = LaneNew
+		for j, t := range target {
+			if strings.EqualFold(strings.TrimSpace(title), strings.TrimSpace(t)) {
+				res[i] = j
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Merge moves all tasks and archived tasks of the source mode into the
+// target mode and removes the source mode. lanes gives the target of each
+// source lane: a lane index of the target mode, LaneNew or LaneArchive.
+// Moved tasks keep their GUID, their Mode field is set to the source mode.
+func (s *ModeStore) Merge(source, target string, lanes []int) error {
+	if isMainMode(source) {
+		return errors.New("the main mode can not be merged into another mode")
+	}
+	if source == target {
+		return errors.New("a mode can not be merged into itself")
+	}
+	if !s.Exists(source) {
+		return fmt.Errorf("mode '%v' not found", source)
+	}
+	src, err := s.Open(source)
+	if err != nil {
+		return err
+	}
+
+	err = s.Update(target, func(t *ToDoContent) error {
+		for i, title := range src.Titles {
+			if len(src.Items[i]) == 0 {
+				continue
+			}
+			dest := LaneNew
+			if i < len(lanes) {
+				dest = lanes[i]
+			}
+			if dest == LaneNew {
+				dest = t.InsertNewLane(false, title, len(t.Titles)-1)
+				t.SetLaneColor(dest, src.GetLaneColor(i))
+			}
+			for _, item := range src.Items[i] {
+				item.Mode = source
+				switch {
+				case dest == LaneArchive:
+					if err := t.archive(item, title); err != nil {
+						return err
+					}
+				case dest >= 0 && dest < len(t.Items):
+					item.Number = t.nextNumber()
+					t.Items[dest] = append(t.Items[dest], item)
+				default:
+					return fmt.Errorf("invalid target lane %v for lane '%v'", dest, title)
+				}
+			}
+		}
+		return moveArchive(src, t)
+	})
+	if err != nil {
+		return err
+	}
+	return s.remove(source, target)
+}
+
+// Remove archives all tasks of a mode in the archive of the main mode and
+// removes the mode.
+func (s *ModeStore) Remove(mode string) error {
+	if isMainMode(mode) {
+		return errors.New("the main mode can not be removed")
+	}
+	if !s.Exists(mode) {
+		return fmt.Errorf("mode '%v' not found", mode)
+	}
+	src, err := s.Open(mode)
+	if err != nil {
+		return err
+	}
+	lanes := make([]int, len(src.Titles))
+	for i := range lanes {
+		lanes[i] = LaneArchive
+	}
+	if !s.Exists("main") {
+		main, err := s.Open("main")
+		if err != nil {
+			return err
+		}
+		if err := main.Save(); err != nil {
+			return err
+		}
+	}
+	return s.Merge(mode, "main", lanes)
+}
+
+// Rename changes the name of a mode.
+func (s *ModeStore) Rename(oldName, newName string) error {
+	if isMainMode(oldName) || isMainMode(newName) {
+		return errors.New("the main mode can not be renamed")
+	}
+	if !s.Exists(oldName) {
+		return fmt.Errorf("mode '%v' not found", oldName)
+	}
+	oldDir, err := s.Dir(oldName)
+	if err != nil {
+		return err
+	}
+	newDir, err := s.Dir(newName)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(newDir); err == nil {
+		return fmt.Errorf("mode '%v' already exists", newName)
+	}
+	if err := s.recordMove(oldName, newName); err != nil {
+		return err
+	}
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return err
+	}
+	// instances watching the directory still receive events after the
+	// rename, the marker makes them switch to the new name
+	marker := path.Join(newDir, MovedMarker)
+	if err := os.WriteFile(marker, []byte(newName), 0644); err != nil {
+		return err
+	}
+	return os.Remove(marker)
+}
+
+// remove deletes the directory of a mode after its tasks were moved to the
+// target mode.
+func (s *ModeStore) remove(mode, target string) error {
+	dir, err := s.Dir(mode)
+	if err != nil {
+		return err
+	}
+	if err := s.recordMove(mode, target); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path.Join(dir, MovedMarker), []byte(target), 0644); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// moveArchive copies the archived tasks of src to the archive of dst, using
+// the encryption of dst.
+func moveArchive(src, dst *ToDoContent) error {
+	entries, err := os.ReadDir(src.archiveFolder)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := src.readFile(path.Join(src.archiveFolder, e.Name()))
+		if err != nil {
+			return err
+		}
+		fname := path.Join(dst.archiveFolder, e.Name())
+		if _, err := os.Stat(fname); err == nil {
+			fname = strings.TrimSuffix(fname, ".json") + ".1.json"
+		}
+		if err := dst.writeFile(fname, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type movedEntry struct {
+	To   string
+	Time time.Time
+}
+
+func (s *ModeStore) movedFileName() string {
+	return path.Join(s.Home, ".todo", movedFile)
+}
+
+func (s *ModeStore) readMoved() map[string]movedEntry {
+	moved := make(map[string]movedEntry)
+	if data, err := os.ReadFile(s.movedFileName()); err == nil {
+		json.Unmarshal(data, &moved)
+	}
+	return moved
+}
+
+// recordMove notes that mode was replaced by target. Entries older than an
+// hour are dropped.
+func (s *ModeStore) recordMove(mode, target string) error {
+	moved := s.readMoved()
+	for m, e := range moved {
+		if time.Since(e.Time) > movedKeep {
+			delete(moved, m)
+		}
+	}
+	moved[mode] = movedEntry{To: target, Time: time.Now()}
+	data, err := json.MarshalIndent(moved, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.movedFileName(), data, 0644)
+}
+
+// MovedTo returns the mode which replaced a merged, renamed or removed mode,
+// "main" if unknown.
+func (s *ModeStore) MovedTo(mode string) string {
+	e, ok := s.readMoved()[mode]
+	if !ok || e.To == "" || time.Since(e.Time) > movedKeep {
+		return "main"
+	}
+	return e.To
+}
@@ -0,0 +1,210 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestMode(t *testing.T, s *ModeStore, mode string, titles ...string) {
+	t.Helper()
+	c, err := s.Open(mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) > 0 {
+		c.Titles = titles
+		c.Items = make([][]Item, len(titles))
+		c.normalize()
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatchLanes(t *testing.T) {
+	got := MatchLanes([]string{"To Do", "review", "Done"}, []string{"done", "to do"})
+	want := []int{1, LaneNew, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("MatchLanes=%v want %v", got, want)
+		}
+	}
+}
+
+func TestMergeMode(t *testing.T) {
+	s := NewModeStore(t.TempDir(), nil)
+	newTestMode(t, s, "main")
+	newTestMode(t, s, "work", "To Do", "Review", "Old", "Done")
+	err := s.Update("work", func(c *ToDoContent) error {
+		c.AddItem(0, 0, "todo", "", 2, "", "")
+		c.AddItem(1, 0, "review", "", 2, "", "")
+		c.AddItem(2, 0, "old", "", 2, "", "")
+		return c.ArchiveItem(2, 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Update("work", func(c *ToDoContent) error {
+		c.AddItem(2, 0, "old 2", "", 2, "", "")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	work, _ := s.Open("work")
+	guid := work.Items[0][0].Guid
+
+	if err := s.Merge("work", "main", []int{0, LaneNew, LaneArchive, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Exists("work") {
+		t.Fatalf("source mode not removed")
+	}
+	if got := s.MovedTo("work"); got != "main" {
+		t.Fatalf("MovedTo=%v", got)
+	}
+
+	main, _ := s.Open("main")
+	if len(main.Titles) != 4 || main.Titles[3] != "Review" {
+		t.Fatalf("unmatched lane not added: %v", main.Titles)
+	}
+	if len(main.Items[0]) != 1 || main.Items[0][0].Guid != guid || main.Items[0][0].Mode != "work" {
+		t.Fatalf("task not moved: %#v", main.Items[0])
+	}
+	archived, _ := filepath.Glob(filepath.Join(s.Home, ".todo", "archive", "*.json"))
+	if len(archived) != 2 {
+		t.Fatalf("expected 2 archived tasks, got %v", archived)
+	}
+}
+
+func TestRenameAndRemoveMode(t *testing.T) {
+	s := NewModeStore(t.TempDir(), nil)
+	newTestMode(t, s, "work")
+	if err := s.Rename("work", "job"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Exists("work") || !s.Exists("job") {
+		t.Fatalf("mode not renamed")
+	}
+	if got := s.MovedTo("work"); got != "job" {
+		t.Fatalf("MovedTo=%v", got)
+	}
+	if _, err := os.Stat(filepath.Join(s.Home, ".todo", "mode", "job", MovedMarker)); !os.IsNotExist(err) {
+		t.Fatalf("marker left in renamed mode")
+	}
+	if err := s.Rename("main", "x"); err == nil {
+		t.Fatalf("main mode renamed")
+	}
+
+	if err := s.Update("job", func(c *ToDoContent) error {
+		c.AddItem(0, 0, "task", "", 2, "", "")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("job"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Exists("job") || !s.Exists("main") {
+		t.Fatalf("mode not removed")
+	}
+	archived, _ := filepath.Glob(filepath.Join(s.Home, ".todo", "archive", "*.json"))
+	if len(archived) != 1 {
+		t.Fatalf("task not archived in main mode: %v", archived)
+	}
+}
+
+func TestTransferItem(t *testing.T) {
+	s := NewModeStore(t.TempDir(), nil)
+	newTestMode(t, s, "main")
+	newTestMode(t, s, "work")
+	item := Item{Title: "task", Guid: "g1"}
+	if err := s.Update("main", func(c *ToDoContent) error {
+		c.Items[0] = append(c.Items[0], item)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.TransferItem(item, "main", "work", 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if main, err := s.Open("main"); err != nil || len(main.Items[0]) != 0 {
+		t.Fatalf("moved item kept in origin: %v %+v", err, main.Items)
+	}
+	if err := s.TransferItem(item, "main", "work", 2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.TransferItem(item, "work", "work", 0, false); err == nil {
+		t.Fatalf("expected error for transfer into the same mode")
+	}
+	if err := s.TransferItem(item, "main", "work", 5, false); err == nil {
+		t.Fatalf("expected error for invalid lane")
+	}
+	// the task is taken out of the target again if the origin can not be
+	// updated
+	if err := s.TransferItem(Item{Title: "lost", Guid: "g2"}, "gone", "work", 0, false); err == nil {
+		t.Fatalf("expected error for unknown origin")
+	}
+
+	c, err := s.Open("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, copied := c.Items[1], c.Items[2]
+	if len(c.Items[0]) != 0 {
+		t.Fatalf("task of failed move kept %+v", c.Items[0])
+	}
+	if len(moved) != 1 || moved[0].Guid != "g1" || moved[0].Mode != "main" {
+		t.Fatalf("moved item %+v", moved)
+	}
+	if len(copied) != 1 || copied[0].Guid == "g1" || copied[0].Mode != "main" || copied[0].Title != "task" {
+		t.Fatalf("copied item %+v", copied)
+	}
+}
+
+func TestTransferIntoOpenMode(t *testing.T) {
+	s := NewModeStore(t.TempDir(), nil)
+	newTestMode(t, s, "main")
+	newTestMode(t, s, "work")
+	running, err := s.Open("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.TransferItem(Item{Title: "task", Guid: "g1"}, "main", "work", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	// the running instance must not overwrite the transferred task
+	running.AddItem(1, 0, "local", "", 2, "", "")
+	if err := running.Save(); err != ErrConflict {
+		t.Fatalf("expected conflict got %v", err)
+	}
+	if err := running.Read(); err != nil {
+		t.Fatal(err)
+	}
+	if len(running.Items[0]) != 1 || running.Items[0][0].Guid != "g1" {
+		t.Fatalf("transferred task missing %+v", running.Items)
+	}
+	if err := running.Save(); err != nil {
+		t.Fatalf("save after reload failed: %v", err)
+	}
+}
+
+func TestCreateMode(t *testing.T) {
+	s := NewModeStore(t.TempDir(), nil)
+	if err := s.Create("bugs", FindTemplate(BuiltinTemplates(), "bug-triage")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create("bugs", nil); err == nil {
+		t.Fatalf("existing mode created again")
+	}
+	c, err := s.Open("bugs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Titles) != 5 || c.Titles[0] != "New" {
+		t.Fatalf("unexpected lanes %v", c.Titles)
+	}
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTemplateName is the template of new modes if none is selected.
+const DefaultTemplateName = "default"
+
+// TemplateItem is a task added to new boards.
+type TemplateItem struct {
+	Title     string `json:"title"`
+	Secondary string `json:"secondary,omitempty"`
+	Note      string `json:"note,omitempty"`
+	Priority  int    `json:"priority,omitempty"`
+	Color     string `json:"color,omitempty"`
+}
+
+// LaneTemplate defines a lane of a board template.
+type LaneTemplate struct {
+	Title    string         `json:"title"`
+	Color    string         `json:"color,omitempty"`
+	Sort     string         `json:"sort,omitempty"`
+	WipLimit int            `json:"wipLimit,omitempty"`
+	Items    []TemplateItem `json:"items,omitempty"`
+}
+
+// BoardTemplate defines the lanes and initial tasks of a new board. User
+// templates are stored as JSON files in $HOME/.todo/templates, named after
+// the file.
+type BoardTemplate struct {
+	Name  string         `json:"-"`
+	Lanes []LaneTemplate `json:"lanes"`
+}
+
+var builtinTemplates = []*BoardTemplate{
+	{Name: DefaultTemplateName, Lanes: []LaneTemplate{
+		{Title: "To Do"}, {Title: "Doing"}, {Title: "Done"},
+	}},
+	{Name: "kanban", Lanes: []LaneTemplate{
+		{Title: "Backlog", Sort: SortPriority},
+		{Title: "Ready"},
+		{Title: "In Progress", WipLimit: 3},
+		{Title: "Review", WipLimit: 2},
+		{Title: "Done", Sort: SortModified},
+	}},
+	{Name: "scrum-sprint", Lanes: []LaneTemplate{
+		{Title: "Product Backlog", Sort: SortPriority},
+		{Title: "Sprint Backlog"},
+		{Title: "In Progress", WipLimit: 4},
+		{Title: "Review"},
+		{Title: "Done", Sort: SortModified},
+	}},
+	{Name: "gtd", Lanes: []LaneTemplate{
+		{Title: "Inbox", Sort: SortCreated, Items: []TemplateItem{
+			{Title: "Collect everything here, process it later"},
+		}},
+		{Title: "Next Actions", Sort: SortPriority},
+		{Title: "Waiting For", Color: "darkblue"},
+		{Title: "Someday/Maybe", Color: "gray"},
+		{Title: "Done"},
+	}},
+	{Name: "bug-triage", Lanes: []LaneTemplate{
+		{Title: "New", Sort: SortCreated},
+		{Title: "Confirmed", Sort: SortPriority},
+		{Title: "In Progress", WipLimit: 3},
+		{Title: "Fixed"},
+		{Title: "Won't Fix", Color: "gray"},
+	}},
+}
+
+// BuiltinTemplates returns the board templates shipped with the program.
+func BuiltinTemplates() []*BoardTemplate {
+	return builtinTemplates
+}
+
+// LoadTemplates returns the built-in templates followed by the user templates
+// in dir. Invalid files are reported in the returned error, the other
+// templates are returned nevertheless.
+func LoadTemplates(dir string) ([]*BoardTemplate, error) {
+	templates := append([]*BoardTemplate{}, builtinTemplates...)
+	files, err := filepath.Glob(path.Join(dir, "*.json"))
+	if err != nil {
+		return templates, err
+	}
+	var errs []string
+	for _, fname := range files {
+		t, err := loadTemplate(fname)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		templates = append(templates, t)
+	}
+	if len(errs) > 0 {
+		return templates, errors.New(strings.Join(errs, "; "))
+	}
+	return templates, nil
+}
+
+func loadTemplate(fname string) (*BoardTemplate, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	t := &BoardTemplate{Name: strings.TrimSuffix(filepath.Base(fname), ".json")}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("invalid template '%v': %w", fname, err)
+	}
+	if len(t.Lanes) == 0 {
+		return nil, fmt.Errorf("template '%v' has no lanes", fname)
+	}
+	return t, nil
+}
+
+// FindTemplate returns the template with the given name, or nil.
+func FindTemplate(templates []*BoardTemplate, name string) *BoardTemplate {
+	for _, t := range templates {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// TemplateNames returns the names of the templates.
+func TemplateNames(templates []*BoardTemplate) []string {
+	names := make([]string, len(templates))
+	for i, t := range templates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// SaveTemplate stores a template as user template in dir.
+func SaveTemplate(dir string, t *BoardTemplate) error {
+	if FindTemplate(builtinTemplates, t.Name) != nil {
+		return fmt.Errorf("'%v' is a built-in template", t.Name)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(t, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path.Join(dir, t.Name+".json"), data, 0644)
+}
+
+// TemplateFromBoard returns a template with the lanes of a board. If
+// withItems is set, the tasks of the board are included.
+func TemplateFromBoard(name string, c *ToDoContent, withItems bool) *BoardTemplate {
+	t := &BoardTemplate{Name: name, Lanes: make([]LaneTemplate, len(c.Titles))}
+	for i, title := range c.Titles {
+		lane := LaneTemplate{Title: title, Color: c.GetLaneColor(i), WipLimit: c.GetWipLimit(i)}
+		if i < len(c.SortModes) {
+			lane.Sort = c.SortModes[i]
+		}
+		if withItems {
+			for _, item := range c.Items[i] {
+				lane.Items = append(lane.Items, TemplateItem{Title: item.Title, Secondary: item.Secondary, Note: item.Note, Priority: item.Priority, Color: item.Color})
+			}
+		}
+		t.Lanes[i] = lane
+	}
+	return t
+}
+
+// ApplyTemplate replaces the lanes and tasks of the board by the ones of the
+// template.
+func (c *ToDoContent) ApplyTemplate(t *BoardTemplate) {
+	n := len(t.Lanes)
+	c.Titles = make([]string, n)
+	c.Items = make([][]Item, n)
+	c.SortModes = make([]string, n)
+	c.LaneColors = make([]string, n)
+	c.WipLimits = make([]int, n)
+	for i, lane := range t.Lanes {
+		c.Titles[i] = lane.Title
+		c.SortModes[i] = lane.Sort
+		c.LaneColors[i] = lane.Color
+		c.WipLimits[i] = lane.WipLimit
+		for _, item := range lane.Items {
+			priority := item.Priority
+			if priority < 1 || priority > 4 {
+				priority = 2
+			}
+			c.AddItem(i, len(c.Items[i]), item.Title, item.Secondary, priority, "", item.Color)
+			c.Items[i][len(c.Items[i])-1].Note = item.Note
+		}
+	}
+}
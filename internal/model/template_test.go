@@ -0,0 +1,84 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyTemplate(t *testing.T) {
+	tpl := FindTemplate(BuiltinTemplates(), "Kanban")
+	if tpl == nil {
+		t.Fatalf("kanban template not found")
+	}
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.ApplyTemplate(tpl)
+	if len(c.Titles) != 5 || c.Titles[2] != "In Progress" || c.GetWipLimit(2) != 3 || c.SortModes[0] != SortPriority {
+		t.Fatalf("unexpected board %v %v %v", c.Titles, c.WipLimits, c.SortModes)
+	}
+
+	gtd := &ToDoContent{}
+	gtd.ApplyTemplate(FindTemplate(BuiltinTemplates(), "gtd"))
+	if len(gtd.Items[0]) != 1 || gtd.Items[0][0].Guid == "" || gtd.Items[0][0].Priority != 2 || gtd.GetLaneColor(3) != "gray" {
+		t.Fatalf("unexpected gtd board %+v", gtd)
+	}
+}
+
+func TestSaveAndLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetLaneColor(1, "blue")
+	c.SetWipLimit(1, 2)
+	c.AddItem(0, 0, "seed", "details", 1, "2024-01-01", "red")
+
+	if err := SaveTemplate(dir, TemplateFromBoard("kanban", c, true)); err == nil {
+		t.Fatalf("built-in template overwritten")
+	}
+	if err := SaveTemplate(dir, TemplateFromBoard("mine", c, true)); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)
+
+	templates, err := LoadTemplates(dir)
+	if err == nil {
+		t.Fatalf("expected error for broken template")
+	}
+	mine := FindTemplate(templates, "mine")
+	if len(templates) != len(BuiltinTemplates())+1 || mine == nil {
+		t.Fatalf("templates %v", TemplateNames(templates))
+	}
+
+	n := &ToDoContent{}
+	n.ApplyTemplate(mine)
+	if n.GetLaneColor(1) != "blue" || n.GetWipLimit(1) != 2 || len(n.Items[0]) != 1 {
+		t.Fatalf("unexpected board %+v", n)
+	}
+	if item := n.Items[0][0]; item.Title != "seed" || item.Priority != 1 || item.Color != "red" || item.Due != "" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestWipLimit(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetWipLimit(1, 1)
+	c.AddItem(1, 0, "a", "", 2, "", "")
+	if c.OverWipLimit(1) || c.GetLaneTitle(1) != " Doing (1/1) " {
+		t.Fatalf("unexpected lane %q", c.GetLaneTitle(1))
+	}
+	c.AddItem(1, 0, "b", "", 2, "", "")
+	if !c.OverWipLimit(1) {
+		t.Fatalf("limit not exceeded")
+	}
+
+	c.InsertNewLane(true, "New", 1)
+	if c.GetWipLimit(1) != 0 || c.GetWipLimit(2) != 1 {
+		t.Fatalf("limits after insert %v", c.WipLimits)
+	}
+	c.RemoveLane(1)
+	if len(c.WipLimits) != 3 || c.GetWipLimit(1) != 1 {
+		t.Fatalf("limits after remove %v", c.WipLimits)
+	}
+}
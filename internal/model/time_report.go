@@ -0,0 +1,178 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Groupings of time reports.
+const (
+	GroupByItem = "item"
+	GroupByLane = "lane"
+	GroupByDay  = "day"
+	GroupByTag  = "tag"
+)
+
+// TimeGroupings are the groupings of time reports.
+var TimeGroupings = []string{GroupByItem, GroupByLane, GroupByDay, GroupByTag}
+
+// TimeRecord is a time entry of an item with the item's position, as listed
+// in time reports. The mode is empty unless records of several modes are
+// reported together.
+type TimeRecord struct {
+	Mode     string
+	Lane     string
+	Title    string
+	Guid     string
+	Tags     []string
+	Archived bool
+	User     string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// TimeTotal is the time of a group of a time report.
+type TimeTotal struct {
+	Group    string
+	Duration time.Duration
+}
+
+// ArchivedItem is an item of the archive and the lane it was archived from.
+type ArchivedItem struct {
+	Lane string
+	Item Item
+}
+
+// ArchivedItems reads the items of the archive. The lane is taken from the
+// file name, so characters not allowed in file names are replaced.
+func (c *ToDoContent) ArchivedItems() ([]ArchivedItem, error) {
+	entries, err := os.ReadDir(c.archiveFolder)
+	if errors.Is(err, os.ErrNotExist) || c.archiveFolder == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var res []ArchivedItem
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := c.readFile(path.Join(c.archiveFolder, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var item Item
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("invalid archive file '%v': %w", e.Name(), err)
+		}
+		// "2006-01-02 15_04_05.000.<lane>.json", optionally with the GUID
+		lane := strings.TrimSuffix(e.Name(), ".json")
+		lane = strings.TrimSuffix(lane, "."+item.Guid)
+		if len(lane) > 24 {
+			lane = lane[24:]
+		}
+		res = append(res, ArchivedItem{Lane: lane, Item: item})
+	}
+	return res, nil
+}
+
+// TimeRecords returns the time entries of the items of the board and of the
+// archive which end after since, cut at since.
+func (c *ToDoContent) TimeRecords(mode string, since, now time.Time) ([]TimeRecord, error) {
+	var res []TimeRecord
+	add := func(item Item, lane string, archived bool) {
+		for _, e := range item.Time {
+			start, end, ok := e.Times(now)
+			if !ok || !end.After(since) {
+				continue
+			}
+			if start.Before(since) {
+				start = since
+			}
+			res = append(res, TimeRecord{Mode: mode, Lane: lane, Title: item.Title, Guid: item.Guid, Tags: item.Tags,
+				Archived: archived, User: e.User, Start: start, Duration: end.Sub(start)})
+		}
+	}
+	for lane, title := range c.Titles {
+		for _, item := range c.GetLaneItems(lane) {
+			add(item, title, false)
+		}
+	}
+	archived, err := c.ArchivedItems()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range archived {
+		add(a.Item, a.Lane, true)
+	}
+	return res, nil
+}
+
+// GroupTime sums the time of the records per item, lane, day or tag. Time of
+// records with several tags counts for each tag. The groups are sorted by
+// name, days in chronological order.
+func GroupTime(records []TimeRecord, by string) ([]TimeTotal, error) {
+	totals := make(map[string]time.Duration)
+	for _, r := range records {
+		var groups []string
+		switch by {
+		case GroupByItem:
+			groups = []string{r.Title}
+		case GroupByLane:
+			groups = []string{r.Lane}
+		case GroupByDay:
+			groups = []string{r.Start.Local().Format("2006-01-02")}
+		case GroupByTag:
+			groups = r.Tags
+			if len(groups) == 0 {
+				groups = []string{"(no tag)"}
+			}
+		default:
+			return nil, fmt.Errorf("invalid grouping '%v', use one of: %v", by, strings.Join(TimeGroupings, ", "))
+		}
+		for _, g := range groups {
+			if r.Mode != "" && by != GroupByDay && by != GroupByTag {
+				g = r.Mode + ": " + g
+			}
+			totals[g] += r.Duration
+		}
+	}
+	res := make([]TimeTotal, 0, len(totals))
+	for g, d := range totals {
+		res = append(res, TimeTotal{Group: g, Duration: d})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Group < res[j].Group })
+	return res, nil
+}
+
+// StopTimers stops the running timers of the user in all modes but except.
+// Modes which can not be read, e.g. encrypted ones, are skipped.
+func (s *ModeStore) StopTimers(user, except string, now time.Time) error {
+	modes, err := s.Modes()
+	if err != nil {
+		return err
+	}
+	for _, mode := range modes {
+		if mode == except || (isMainMode(mode) && isMainMode(except)) {
+			continue
+		}
+		c, err := s.Open(mode)
+		if err != nil || c.RunningTimer(user) == nil {
+			continue
+		}
+		if err := s.Update(mode, func(c *ToDoContent) error {
+			c.StopTimer(user, now)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
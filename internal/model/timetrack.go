@@ -0,0 +1,231 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimeEntry is a period of work on an item. End is empty while the timer of
+// the entry runs.
+type TimeEntry struct {
+	Start string
+	End   string `json:",omitempty"`
+	User  string `json:",omitempty"`
+}
+
+// Times returns the start and end of the entry, the end of a running entry
+// is now. The result is false if the entry is invalid.
+func (e TimeEntry) Times(now time.Time) (time.Time, time.Time, bool) {
+	start, err := time.Parse(time.RFC3339, e.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end := now
+	if e.End != "" {
+		if end, err = time.Parse(time.RFC3339, e.End); err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// Duration returns the time of the entry, up to now for a running entry.
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	start, end, ok := e.Times(now)
+	if !ok {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// TrackedTime returns the total time of the entries of an item.
+func (item *Item) TrackedTime(now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range item.Time {
+		total += e.Duration(now)
+	}
+	return total
+}
+
+// RunningEntry returns the index of the running time entry of the user, or
+// -1.
+func (item *Item) RunningEntry(user string) int {
+	for i, e := range item.Time {
+		if e.End == "" && e.User == user {
+			return i
+		}
+	}
+	return -1
+}
+
+// RunningTimer returns the item with the running timer of the user, or nil.
+func (c *ToDoContent) RunningTimer(user string) *Item {
+	for lane := range c.Items {
+		for i := range c.Items[lane] {
+			if c.Items[lane][i].RunningEntry(user) >= 0 {
+				return &c.Items[lane][i]
+			}
+		}
+	}
+	return nil
+}
+
+// StopTimer stops the running timer of the user. It returns the item of the
+// timer, or nil if no timer was running.
+func (c *ToDoContent) StopTimer(user string, now time.Time) *Item {
+	item := c.RunningTimer(user)
+	if item == nil {
+		return nil
+	}
+	for i := range item.Time {
+		if item.Time[i].End == "" && item.Time[i].User == user {
+			item.Time[i].End = now.UTC().Format(time.RFC3339)
+		}
+	}
+	return item
+}
+
+// StartTimer starts a timer of the user for an item. A timer of the user
+// running for another item is stopped, only one runs at a time.
+func (c *ToDoContent) StartTimer(guid, user string, now time.Time) error {
+	item := c.item(guid)
+	if item == nil {
+		return fmt.Errorf("task not found")
+	}
+	if item.RunningEntry(user) >= 0 {
+		return nil
+	}
+	c.StopTimer(user, now)
+	item.Time = append(item.Time, TimeEntry{Start: now.UTC().Format(time.RFC3339), User: user})
+	return nil
+}
+
+// FormatDuration returns a duration as hours and minutes, e.g. "1:05".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+const (
+	entryDateLayout = "2006-01-02"
+	entryTimeLayout = "2006-01-02 15:04"
+)
+
+// FormatTimeEntry returns an entry as a line of text, which is read by
+// ParseTimeEntries: the local start, the end time (with the date if it is
+// another day) or "running", and the user.
+func FormatTimeEntry(e TimeEntry, loc *time.Location) string {
+	start, err := time.Parse(time.RFC3339, e.Start)
+	if err != nil {
+		return e.Start
+	}
+	start = start.In(loc)
+	end := "running"
+	if e.End != "" {
+		t, err := time.Parse(time.RFC3339, e.End)
+		if err != nil {
+			return e.Start
+		}
+		t = t.In(loc)
+		if t.Format(entryDateLayout) == start.Format(entryDateLayout) {
+			end = t.Format("15:04")
+		} else {
+			end = t.Format(entryTimeLayout)
+		}
+	}
+	line := start.Format(entryTimeLayout) + " - " + end
+	if e.User != "" {
+		line += " " + e.User
+	}
+	return line
+}
+
+var (
+	entryRange    = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}) (\d{1,2}:\d{2})\s*-\s*(running|(?:\d{4}-\d{2}-\d{2} )?\d{1,2}:\d{2})(?:\s+(\S+))?$`)
+	entryDuration = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?: (\d{1,2}:\d{2}))?\s+((?:\d+h)?(?:\d+m)?)(?:\s+(\S+))?$`)
+)
+
+// ParseTimeEntries reads time entries, one per line as written by
+// FormatTimeEntry. Instead of the end time, a duration like "1h30m" may be
+// given, the start time is optional then. Empty lines and lines starting with
+// "#" are skipped. Entries without user are assigned to user. Lines which
+// equal a formatted entry of old keep this entry unchanged.
+func ParseTimeEntries(text, user string, loc *time.Location, old []TimeEntry) ([]TimeEntry, error) {
+	unchanged := make(map[string]TimeEntry)
+	for _, e := range old {
+		unchanged[FormatTimeEntry(e, loc)] = e
+	}
+	var res []TimeEntry
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if e, ok := unchanged[line]; ok {
+			res = append(res, e)
+			continue
+		}
+		e, err := parseTimeEntry(line, user, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", n+1, err)
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+func parseTimeEntry(line, user string, loc *time.Location) (TimeEntry, error) {
+	var start, end time.Time
+	running := false
+	var err error
+	if m := entryRange.FindStringSubmatch(line); m != nil {
+		if start, err = time.ParseInLocation(entryTimeLayout, m[1]+" "+m[2], loc); err != nil {
+			return TimeEntry{}, err
+		}
+		switch {
+		case m[3] == "running":
+			running = true
+		case strings.Contains(m[3], " "):
+			end, err = time.ParseInLocation(entryTimeLayout, m[3], loc)
+		default:
+			end, err = time.ParseInLocation(entryTimeLayout, m[1]+" "+m[3], loc)
+		}
+		if err != nil {
+			return TimeEntry{}, err
+		}
+		if m[4] != "" {
+			user = m[4]
+		}
+	} else if m := entryDuration.FindStringSubmatch(line); m != nil && m[3] != "" {
+		clock := m[2]
+		if clock == "" {
+			clock = "00:00"
+		}
+		if start, err = time.ParseInLocation(entryTimeLayout, m[1]+" "+clock, loc); err != nil {
+			return TimeEntry{}, err
+		}
+		d, err := time.ParseDuration(m[3])
+		if err != nil {
+			return TimeEntry{}, err
+		}
+		end = start.Add(d)
+		if m[4] != "" {
+			user = m[4]
+		}
+	} else {
+		return TimeEntry{}, fmt.Errorf("invalid time entry '%v', use e.g. '2006-01-02 09:00 - 10:30' or '2006-01-02 1h30m'", line)
+	}
+	e := TimeEntry{Start: start.UTC().Format(time.RFC3339), User: user}
+	if !running {
+		if end.Before(start) {
+			return TimeEntry{}, fmt.Errorf("the end of '%v' is before its start", line)
+		}
+		e.End = end.UTC().Format(time.RFC3339)
+	}
+	return e, nil
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimers(t *testing.T) {
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.AddItem(0, 1, "deploy", "", 2, "", "")
+	review, deploy := c.Items[0][0].Guid, c.Items[0][1].Guid
+	start := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	if err := c.StartTimer(review, "ann", start); err != nil {
+		t.Fatal(err)
+	}
+	c.StartTimer(review, "bob", start)
+	if item := c.RunningTimer("ann"); item == nil || item.Guid != review {
+		t.Fatalf("running timer %+v", item)
+	}
+	// starting another timer stops the running one of the user only
+	c.StartTimer(deploy, "ann", start.Add(30*time.Minute))
+	if item := c.RunningTimer("ann"); item == nil || item.Guid != deploy {
+		t.Fatalf("running timer %+v", item)
+	}
+	if c.Items[0][0].RunningEntry("bob") < 0 || c.Items[0][0].RunningEntry("ann") >= 0 {
+		t.Fatalf("entries of review %+v", c.Items[0][0].Time)
+	}
+	now := start.Add(time.Hour)
+	if d := c.Items[0][0].TrackedTime(now); d != 90*time.Minute {
+		t.Fatalf("tracked time %v", d)
+	}
+	if item := c.StopTimer("ann", now); item == nil || item.Guid != deploy || c.RunningTimer("ann") != nil {
+		t.Fatalf("timer not stopped: %+v", item)
+	}
+	if c.StopTimer("ann", now) != nil {
+		t.Fatal("no timer expected")
+	}
+	if err := c.StartTimer("unknown", "ann", now); err == nil {
+		t.Fatal("expected error for unknown task")
+	}
+}
+
+func TestParseTimeEntries(t *testing.T) {
+	loc := time.FixedZone("test", 2*60*60)
+	old := []TimeEntry{
+		{Start: "2024-05-06T07:00:00Z", End: "2024-05-06T08:30:00Z", User: "ann"},
+		{Start: "2024-05-06T21:00:00Z", End: "2024-05-07T01:00:00Z", User: "ann"},
+		{Start: "2024-05-07T08:00:00Z", User: "bob"},
+	}
+	want := []string{"2024-05-06 09:00 - 10:30 ann", "2024-05-06 23:00 - 2024-05-07 03:00 ann", "2024-05-07 10:00 - running bob"}
+	text := "# comment\n"
+	for i, e := range old {
+		if line := FormatTimeEntry(e, loc); line != want[i] {
+			t.Fatalf("line %v: %q", i, line)
+		}
+		text += want[i] + "\n"
+	}
+	text += "\n2024-05-08 1h30m\n2024-05-08 14:00 45m bob\n2024-05-09 8:00 - 9:15\n"
+
+	entries, err := ParseTimeEntries(text, "ann", loc, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := []TimeEntry{
+		{Start: "2024-05-07T22:00:00Z", End: "2024-05-07T23:30:00Z", User: "ann"},
+		{Start: "2024-05-08T12:00:00Z", End: "2024-05-08T12:45:00Z", User: "bob"},
+		{Start: "2024-05-09T06:00:00Z", End: "2024-05-09T07:15:00Z", User: "ann"},
+	}
+	if len(entries) != 6 {
+		t.Fatalf("entries %+v", entries)
+	}
+	for i, e := range append(old, added...) {
+		if entries[i] != e {
+			t.Fatalf("entry %v: %+v, expected %+v", i, entries[i], e)
+		}
+	}
+
+	for _, invalid := range []string{"yesterday 1h", "2024-05-08 10:00 - 9:00", "2024-05-08"} {
+		if _, err := ParseTimeEntries(invalid, "ann", loc, nil); err == nil {
+			t.Fatalf("expected error for %q", invalid)
+		}
+	}
+	if s := FormatDuration(65*time.Minute + 40*time.Second); s != "1:06" {
+		t.Fatalf("duration %v", s)
+	}
+}
+
+func TestTimeRecords(t *testing.T) {
+	dir := t.TempDir()
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.SetFileName(dir+"/todo.json", dir, dir)
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.AddItem(1, 0, "deploy", "", 2, "", "")
+	c.Items[0][0].Tags = []string{"work", "ops"}
+	c.Items[0][0].Time = []TimeEntry{
+		{Start: "2024-05-05T23:00:00Z", End: "2024-05-06T01:00:00Z"},
+		{Start: "2024-05-06T10:00:00Z", End: "2024-05-06T10:30:00Z"},
+	}
+	c.Items[1][0].Time = []TimeEntry{{Start: "2024-05-06T11:00:00Z"}}
+	if err := c.ArchiveItem(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	c.AddItem(0, 0, "old", "", 2, "", "")
+	c.Items[0][0].Time = []TimeEntry{{Start: "2024-05-01T10:00:00Z", End: "2024-05-01T11:00:00Z"}}
+
+	since := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+	records, err := c.TimeRecords("", since, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records %+v", records)
+	}
+	// the running timer of the archived item was stopped on archiving
+	archived := 0
+	for _, r := range records {
+		if r.Archived {
+			archived++
+			if r.Lane != c.Titles[0] {
+				t.Fatalf("lane of archived record %q", r.Lane)
+			}
+		}
+	}
+	if archived != 2 {
+		t.Fatalf("archived records %+v", records)
+	}
+
+	check := func(by string, want map[string]time.Duration) {
+		t.Helper()
+		totals, err := GroupTime(records, by)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(totals) != len(want) {
+			t.Fatalf("%v: %+v", by, totals)
+		}
+		for _, total := range totals {
+			if want[total.Group] != total.Duration {
+				t.Fatalf("%v: %+v", by, totals)
+			}
+		}
+	}
+	check(GroupByItem, map[string]time.Duration{"review": 90 * time.Minute, "deploy": time.Hour})
+	check(GroupByLane, map[string]time.Duration{c.Titles[0]: 90 * time.Minute, c.Titles[1]: time.Hour})
+	check(GroupByTag, map[string]time.Duration{"work": 90 * time.Minute, "ops": 90 * time.Minute, "(no tag)": time.Hour})
+	if _, err := GroupTime(records, "week"); err == nil {
+		t.Fatal("expected error for invalid grouping")
+	}
+}
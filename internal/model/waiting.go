@@ -0,0 +1,62 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// WaitingFor is the waiting state of an item: the party it waits on, since
+// when, and the date to follow up (ISO dates, the follow-up is optional).
+type WaitingFor struct {
+	On       string
+	Since    string
+	FollowUp string `json:",omitempty"`
+}
+
+// IsWaiting returns whether the item waits on someone or something.
+func (item *Item) IsWaiting() bool {
+	return item.Waiting != nil
+}
+
+// FollowUpDue returns whether the follow-up date of a waiting item is today
+// or has passed.
+func (item *Item) FollowUpDue(now time.Time) bool {
+	if item.Waiting == nil {
+		return false
+	}
+	days, ok := DueDays(item.Waiting.FollowUp, now)
+	return ok && days <= 0
+}
+
+// SetWaiting puts an item into the waiting state, or takes it out of it for
+// an empty party. The start of the waiting is kept when only the party or
+// the follow-up date change.
+func (item *Item) SetWaiting(on, followUp string, now time.Time) {
+	if on == "" {
+		item.Waiting = nil
+		return
+	}
+	since := now.Format("2006-01-02")
+	if item.Waiting != nil && item.Waiting.Since != "" {
+		since = item.Waiting.Since
+	}
+	item.Waiting = &WaitingFor{On: on, Since: since, FollowUp: followUp}
+}
+
+// WaitingItems returns the waiting items of the board, ordered by follow-up
+// date; items without follow-up date come last.
+func (c *ToDoContent) WaitingItems(mode string) []AgendaItem {
+	var res []AgendaItem
+	for lane, title := range c.Titles {
+		for _, item := range c.GetLaneItems(lane) {
+			if item.IsWaiting() {
+				res = append(res, AgendaItem{Mode: mode, Lane: lane, LaneTitle: title, Item: item})
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i].Item.Waiting.FollowUp, res[j].Item.Waiting.FollowUp
+		return a != "" && (b == "" || a < b)
+	})
+	return res
+}
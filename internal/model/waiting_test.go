@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaiting(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.Local)
+	c := &ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.AddItem(0, 1, "deploy", "", 2, "", "")
+	c.AddItem(1, 0, "docs", "", 2, "", "")
+	c.AddItem(1, 1, "release", "", 2, "", "")
+
+	c.Items[0][0].SetWaiting("bob", "", now.AddDate(0, 0, -3))
+	c.Items[0][0].SetWaiting("bob", "2024-05-12", now)
+	if w := c.Items[0][0].Waiting; w.Since != "2024-05-07" || w.FollowUp != "2024-05-12" {
+		t.Fatalf("waiting %+v", w)
+	}
+	c.Items[0][1].SetWaiting("ops", "2024-05-10", now)
+	c.Items[1][0].SetWaiting("customer", "", now)
+	if c.Items[0][0].FollowUpDue(now) || !c.Items[0][1].FollowUpDue(now) || c.Items[1][0].FollowUpDue(now) {
+		t.Fatal("wrong follow-ups due")
+	}
+
+	var titles []string
+	for _, ai := range c.WaitingItems("work") {
+		titles = append(titles, ai.Item.Title)
+	}
+	if len(titles) != 3 || titles[0] != "deploy" || titles[1] != "review" || titles[2] != "docs" {
+		t.Fatalf("waiting items %v", titles)
+	}
+	if s := Summarize("work", c, now); len(s.FollowUp) != 1 || s.FollowUp[0].Item.Title != "deploy" {
+		t.Fatalf("follow up %+v", s.FollowUp)
+	}
+
+	c.Items[0][1].SetWaiting("", "", now)
+	if c.Items[0][1].IsWaiting() || len(c.WaitingItems("work")) != 2 {
+		t.Fatal("waiting state not cleared")
+	}
+}
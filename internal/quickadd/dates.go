@@ -0,0 +1,108 @@
+package quickadd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// localeUSApple returns true if the Apple locale suggests an US date format.
+func localeUSApple(locale string) bool {
+	l := strings.ToLower(locale)
+	if idx := strings.Index(l, "@rg="); idx != -1 && len(l) >= idx+6 {
+		region := l[idx+4 : idx+6]
+		return region == "us"
+	}
+	if idx := strings.Index(l, "_"); idx != -1 && len(l) >= idx+3 {
+		region := l[idx+1 : idx+3]
+		return region == "us"
+	}
+	return strings.Contains(l, "us")
+}
+
+// LocaleUS returns true if the system locale suggests an US date format.
+func LocaleUS() bool {
+	if runtime.GOOS == "darwin" {
+		locale := os.Getenv("AppleLocale")
+		if locale == "" {
+			out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+			if err == nil {
+				locale = strings.TrimSpace(string(out))
+			}
+		}
+		if locale != "" {
+			return localeUSApple(locale)
+		}
+	}
+	lang := os.Getenv("LC_TIME")
+	if lang == "" {
+		lang = os.Getenv("LANG")
+	}
+	lang = strings.ToLower(lang)
+	return strings.Contains(lang, "us")
+}
+
+// DateLayouts maps the supported date formats to time layouts.
+var DateLayouts = map[string]string{
+	"dd.mm.yyyy": "02.01.2006",
+	"mm/dd/yyyy": "01/02/2006",
+	"dd/mm/yyyy": "02/01/2006",
+	"yyyy-mm-dd": "2006-01-02",
+}
+
+// DateFormat returns the given date format, e.g. "dd.mm.yyyy", or the format
+// of the locale if it is empty or not supported.
+func DateFormat(format string) string {
+	if _, ok := DateLayouts[format]; ok {
+		return format
+	}
+	if LocaleUS() {
+		return "mm/dd/yyyy"
+	}
+	return "dd.mm.yyyy"
+}
+
+// weekdays maps the names of the days and their abbreviations.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// ParseDue converts a due date entered as text to ISO format. Accepted are
+// dates in the given date format (see DateFormat) or ISO format, "today",
+// "tomorrow", the name of a weekday (the next such day, today if it matches)
+// and offsets like "+2d", "+1w" or "+1m".
+func ParseDue(text, format string, now time.Time) (string, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	switch text {
+	case "":
+		return "", nil
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	}
+	if wd, ok := weekdays[text]; ok {
+		days := (int(wd) - int(now.Weekday()) + 7) % 7
+		return now.AddDate(0, 0, days).Format("2006-01-02"), nil
+	}
+	if strings.HasPrefix(text, "+") {
+		return model.DueFromOffset(text, now)
+	}
+	if t, err := time.Parse("2006-01-02", text); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+	format = DateFormat(format)
+	t, err := time.Parse(DateLayouts[format], text)
+	if err != nil {
+		return "", fmt.Errorf("invalid due date '%v', use e.g. %v, today, fri or +2d", text, format)
+	}
+	return t.Format("2006-01-02"), nil
+}
@@ -0,0 +1,185 @@
+package quickadd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// Task is a task entered in a single line, e.g.
+// "Fix login !1 #bug @due:fri color:red >Doing".
+type Task struct {
+	Title    string
+	Priority int // 0 if not given
+	Tags     []string
+	Due      string // ISO date, empty if not given
+	Color    string
+	Lane     int    // -1 if not given
+	Epic     string // number or GUID of the epic, empty if not given
+	Links    []Link // links to other tasks
+	Errors   []string
+}
+
+// Link is a link of a quick-add task to another task, given by its number or
+// GUID.
+type Link struct {
+	Type string // one of model.LinkTypes
+	Ref  string
+}
+
+// linkTypes are the link words of the quick-add syntax.
+var linkTypes = map[string]string{
+	"blocks":     model.LinkBlocks,
+	"blockedby":  model.LinkBlockedBy,
+	"blocked-by": model.LinkBlockedBy,
+	"relates":    model.LinkRelates,
+	"relates-to": model.LinkRelates,
+}
+
+// Parse splits a quick-add line into the title and the attributes given by
+// these words:
+//
+//	!1 .. !4          priority
+//	#tag              tag
+//	@due:fri, due:fri due date, see ParseDue
+//	color:red         color
+//	>Doing            lane, "_" stands for a space; a unique prefix is enough
+//	epic:42           epic, the number or GUID of a task
+//	blocks:42         link, also blockedby:42 and relates:42
+//
+// Due dates are read in the given date format, see DateFormat. Invalid
+// attributes are reported in Errors. The tasks referenced are looked up by
+// Apply.
+func Parse(text string, lanes []string, dateFormat string, now time.Time) Task {
+	q := Task{Lane: -1}
+	var title []string
+	for _, word := range strings.Fields(text) {
+		lower := strings.ToLower(word)
+		switch {
+		case len(word) == 2 && word[0] == '!' && word[1] >= '1' && word[1] <= '4':
+			q.Priority = int(word[1] - '0')
+		case len(word) > 1 && word[0] == '#' && unicode.IsLetter([]rune(word)[1]):
+			if !containsFold(q.Tags, word[1:]) {
+				q.Tags = append(q.Tags, word[1:])
+			}
+		case strings.HasPrefix(lower, "@due:") || strings.HasPrefix(lower, "due:"):
+			due, err := ParseDue(word[strings.Index(word, ":")+1:], dateFormat, now)
+			if err != nil {
+				q.Errors = append(q.Errors, err.Error())
+			} else {
+				q.Due = due
+			}
+		case strings.HasPrefix(lower, "color:"):
+			if c := lower[len("color:"):]; tcell.ColorNames[c] != tcell.ColorDefault || c == "default" {
+				q.Color = c
+			} else {
+				q.Errors = append(q.Errors, fmt.Sprintf("unknown color '%v'", c))
+			}
+		case strings.HasPrefix(lower, "epic:") && len(word) > len("epic:"):
+			q.Epic = word[len("epic:"):]
+		case linkType(lower) != "":
+			q.Links = append(q.Links, Link{Type: linkType(lower), Ref: word[strings.Index(word, ":")+1:]})
+		case len(word) > 1 && word[0] == '>':
+			if lane := findLane(lanes, word[1:]); lane >= 0 {
+				q.Lane = lane
+			} else {
+				q.Errors = append(q.Errors, fmt.Sprintf("lane '%v' not found", word[1:]))
+			}
+		default:
+			title = append(title, word)
+		}
+	}
+	q.Title = strings.Join(title, " ")
+	return q
+}
+
+// linkType returns the link type of a link word like "blocks:42", empty if
+// the word is no link.
+func linkType(word string) string {
+	i := strings.Index(word, ":")
+	if i < 0 || i == len(word)-1 {
+		return ""
+	}
+	return linkTypes[word[:i]]
+}
+
+// findLane returns the lane with the given title, ignoring case and with "_"
+// for spaces, or else the only lane starting with it. It returns -1 if there
+// is no such lane.
+func findLane(lanes []string, name string) int {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", " "))
+	match := -1
+	for i, title := range lanes {
+		t := strings.ToLower(strings.TrimSpace(title))
+		if t == name {
+			return i
+		}
+		if strings.HasPrefix(t, name) {
+			if match >= 0 {
+				return -1
+			}
+			match = i
+		}
+	}
+	return match
+}
+
+func containsFold(list []string, s string) bool {
+	for _, e := range list {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the GUIDs of the epic, empty if none is given, and of the
+// linked tasks.
+func (q Task) resolve(c *model.ToDoContent) (string, []string, error) {
+	parent := ""
+	if q.Epic != "" {
+		var err error
+		if parent, err = c.ResolveRef(q.Epic); err != nil {
+			return "", nil, err
+		}
+	}
+	others := make([]string, len(q.Links))
+	for i, link := range q.Links {
+		var err error
+		if others[i], err = c.ResolveRef(link.Ref); err != nil {
+			return "", nil, err
+		}
+	}
+	return parent, others, nil
+}
+
+// CheckRefs returns an error if a task referenced as epic or link is not on
+// the board, see model.ToDoContent.FindRef.
+func (q Task) CheckRefs(c *model.ToDoContent) error {
+	_, _, err := q.resolve(c)
+	return err
+}
+
+// Apply sets the epic and the links of the added task with the given GUID.
+func (q Task) Apply(c *model.ToDoContent, guid string) error {
+	parent, others, err := q.resolve(c)
+	if err != nil {
+		return err
+	}
+	if parent != "" {
+		if err := c.SetParent(guid, parent); err != nil {
+			return err
+		}
+	}
+	for i, link := range q.Links {
+		if err := c.LinkItems(guid, others[i], link.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
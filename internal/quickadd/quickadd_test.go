@@ -0,0 +1,62 @@
+package quickadd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	lanes := []string{"To Do", "Doing", "Done", "In Progress"}
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local) // a Wednesday
+
+	q := Parse("Fix  login !1 #bug #ui #Bug @due:fri color:Red >Doing", lanes, "dd.mm.yyyy", now)
+	if q.Title != "Fix login" || q.Priority != 1 || strings.Join(q.Tags, ",") != "bug,ui" || q.Due != "2024-05-10" || q.Color != "red" || q.Lane != 1 || len(q.Errors) != 0 {
+		t.Fatalf("unexpected result %+v", q)
+	}
+
+	q = Parse("Issue #12 !5 now! due:20.05.2024 >in_progress", lanes, "dd.mm.yyyy", now)
+	if q.Title != "Issue #12 !5 now!" || q.Priority != 0 || q.Due != "2024-05-20" || q.Lane != 3 {
+		t.Fatalf("unexpected result %+v", q)
+	}
+
+	q = Parse("Write tests epic:#42 Blocks:7 blockedby:8 relates:abcd-1234 blocks:", lanes, "dd.mm.yyyy", now)
+	if q.Title != "Write tests blocks:" || q.Epic != "#42" || len(q.Links) != 3 || q.Links[0] != (Link{Type: "blocks", Ref: "7"}) || q.Links[1].Type != "blocked-by" || q.Links[2].Ref != "abcd-1234" {
+		t.Fatalf("unexpected references %+v", q)
+	}
+
+	q = Parse("x >Do @due:someday color:nocolor >in", lanes, "dd.mm.yyyy", now)
+	if len(q.Errors) != 3 || q.Lane != 3 {
+		t.Fatalf("expected errors for ambiguous lane, due date and color: %+v", q)
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local) // a Wednesday
+	for in, want := range map[string]string{
+		"today": "2024-05-08", "Tomorrow": "2024-05-09", "wed": "2024-05-08", "tuesday": "2024-05-14",
+		"+1w": "2024-05-15", "2024-06-01": "2024-06-01", "01.06.2024": "2024-06-01",
+	} {
+		if got, err := ParseDue(in, "dd.mm.yyyy", now); err != nil || got != want {
+			t.Fatalf("ParseDue(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseDue("06/01/2024", "dd.mm.yyyy", now); err == nil {
+		t.Fatalf("date in other format accepted")
+	}
+}
+
+func TestLocaleUSApple(t *testing.T) {
+	cases := map[string]bool{
+		"en_US":           true,
+		"en_US@rg=uszzzz": true,
+		"en_US@rg=dezzzz": false,
+		"de_DE":           false,
+		"en_GB":           false,
+	}
+	for in, want := range cases {
+		if got := localeUSApple(in); got != want {
+			t.Fatalf("localeUSApple(%q)=%v want %v", in, got, want)
+		}
+	}
+}
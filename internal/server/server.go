@@ -0,0 +1,629 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// keepAliveInterval is the time between comment lines sent on idle event
+// streams, so proxies do not close the connection.
+var keepAliveInterval = 30 * time.Second
+
+// Server exposes the boards stored below a home directory over a REST and
+// server-sent-events API. All requests have to carry the configured token as
+// bearer token.
+type Server struct {
+	home      string
+	token     string
+	cipherFor func(mode string) (model.Cipher, error)
+	mu        sync.Mutex
+	boards    map[string]*board
+}
+
+type board struct {
+	mu          sync.Mutex
+	mode        string
+	content     *model.ToDoContent
+	watcher     *fsnotify.Watcher
+	lastSave    time.Time
+	subscribers map[chan struct{}]struct{}
+}
+
+// LaneInfo describes a lane in the lane listing.
+type LaneInfo struct {
+	Index int
+	Title string
+	Color string
+	Sort  string
+	Count int
+}
+
+// NewItem is the request body for creating items. Index is optional, new
+// items are appended to the lane by default.
+type NewItem struct {
+	Title     string
+	Secondary string
+	Priority  int
+	Due       string
+	Color     string
+	Index     *int
+}
+
+// MoveRequest is the request body for moving an item.
+type MoveRequest struct {
+	Lane  int
+	Index int
+}
+
+func New(home, token string) *Server {
+	return &Server{home: home, token: token, boards: make(map[string]*board)}
+}
+
+// SetCipherFunc sets the function returning the cipher of encrypted modes.
+func (s *Server) SetCipherFunc(f func(mode string) (model.Cipher, error)) {
+	s.cipherFor = f
+}
+
+// Close stops monitoring the board files.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range s.boards {
+		if b.watcher != nil {
+			b.watcher.Close()
+		}
+	}
+}
+
+// Handler returns the HTTP handler serving the API below /api/.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/modes", s.handleModes)
+	mux.HandleFunc("/api/modes/", s.handleMode)
+	return s.auth(mux)
+}
+
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleModes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	modes, _, err := model.ListValidModes(path.Join(s.home, ".todo", "mode"), "")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, modes)
+}
+
+func (s *Server) handleMode(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/modes/"), "/"), "/")
+	if len(parts) < 2 {
+		http.NotFound(w, r)
+		return
+	}
+	mode := parts[0]
+
+	b, err := s.board(mode, mode == "main" || (r.Method == http.MethodPut && parts[1] == "board"))
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, fmt.Sprintf("mode '%v' not found", mode), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "board":
+		s.handleBoard(w, r, b)
+	case len(parts) == 2 && parts[1] == "events":
+		s.handleEvents(w, r, b)
+	case len(parts) == 2 && parts[1] == "lanes":
+		s.handleLanes(w, r, b)
+	case len(parts) == 4 && parts[1] == "lanes" && parts[3] == "items":
+		lane, err := strconv.Atoi(parts[2])
+		if err != nil {
+			http.Error(w, "invalid lane index", http.StatusBadRequest)
+			return
+		}
+		s.handleLaneItems(w, r, b, lane)
+	case len(parts) == 3 && parts[1] == "items":
+		s.handleItem(w, r, b, parts[2])
+	case len(parts) == 4 && parts[1] == "items" && parts[3] == "move":
+		s.handleMove(w, r, b, parts[2])
+	case len(parts) == 4 && parts[1] == "items" && parts[3] == "archive":
+		s.handleArchive(w, r, b, parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// board returns the board of the given mode. Unless create is set, only
+// existing modes are opened. The main mode is always available. The board is
+// written by the first change, not when it is opened.
+func (s *Server) board(mode string, create bool) (*board, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	todoDir, err := model.ModeDir(mode)
+	if err != nil {
+		return nil, err
+	}
+	fname := path.Join(s.home, todoDir, "todo.json")
+	_, errStat := os.Stat(fname)
+	if b, ok := s.boards[mode]; ok {
+		if errStat == nil || create {
+			return b, nil
+		}
+		// the mode was renamed, merged or removed meanwhile
+		s.forget(mode, b)
+	}
+	if errStat != nil && !create {
+		return nil, errStat
+	}
+
+	var cipher model.Cipher
+	if s.cipherFor != nil {
+		if cipher, err = s.cipherFor(mode); err != nil {
+			return nil, err
+		}
+	}
+	content, err := model.OpenMode(s.home, mode, cipher)
+	if err != nil {
+		return nil, err
+	}
+	b := &board{mode: mode, content: content, subscribers: make(map[chan struct{}]struct{})}
+
+	if watcher, err := fsnotify.NewWatcher(); err == nil {
+		if err := watcher.Add(filepath.Dir(fname)); err == nil {
+			b.watcher = watcher
+			go b.watch(func() {
+				s.mu.Lock()
+				s.forget(mode, b)
+				s.mu.Unlock()
+			})
+		} else {
+			watcher.Close()
+		}
+	}
+
+	s.boards[mode] = b
+	return b, nil
+}
+
+// forget removes a board from the cache and stops monitoring it. The caller
+// has to hold s.mu.
+func (s *Server) forget(mode string, b *board) {
+	if s.boards[mode] == b {
+		delete(s.boards, mode)
+	}
+	if b.watcher != nil {
+		b.watcher.Close()
+	}
+}
+
+// watch notifies subscribers about changes to todo.json made by other
+// processes, e.g. local instances of the application. moved is called when
+// the mode is renamed, merged or removed.
+func (b *board) watch(moved func()) {
+	for {
+		select {
+		case event, ok := <-b.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) == model.MovedMarker && event.Has(fsnotify.Create) {
+				b.notify()
+				moved()
+				return
+			}
+			if filepath.Base(event.Name) != "todo.json" || !(event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				continue
+			}
+			info, err := os.Stat(event.Name)
+			if err != nil {
+				continue
+			}
+			b.mu.Lock()
+			own := info.ModTime().Equal(b.lastSave)
+			b.mu.Unlock()
+			if !own {
+				b.notify()
+			}
+		case _, ok := <-b.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// reload reads the board from disk. The caller has to hold b.mu.
+func (b *board) reload() error {
+	err := b.content.Read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// save writes the board to disk. The caller has to hold b.mu.
+func (b *board) save() error {
+	if err := b.content.Save(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(b.content.FileName()); err == nil {
+		b.lastSave = info.ModTime()
+	}
+	return nil
+}
+
+func (b *board) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *board) unsubscribe(ch chan struct{}) {
+	b.mu.Lock()
+	delete(b.subscribers, ch)
+	b.mu.Unlock()
+}
+
+func (b *board) notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// a change notification is already pending
+		}
+	}
+}
+
+// mutate runs f on the freshly loaded board, saves the result and informs
+// all subscribers.
+func (b *board) mutate(f func(c *model.ToDoContent) (int, interface{}, error)) (int, interface{}, error) {
+	b.mu.Lock()
+	if err := b.reload(); err != nil {
+		b.mu.Unlock()
+		return http.StatusInternalServerError, nil, err
+	}
+	status, res, err := f(b.content)
+	if err == nil {
+		err = b.save()
+		if err != nil {
+			status = http.StatusInternalServerError
+		}
+	}
+	b.mu.Unlock()
+	if err == nil {
+		b.notify()
+	}
+	return status, res, err
+}
+
+// view runs f on the freshly loaded board without saving it.
+func (b *board) view(f func(c *model.ToDoContent) interface{}) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.reload(); err != nil {
+		return nil, err
+	}
+	return f(b.content), nil
+}
+
+func (s *Server) handleBoard(w http.ResponseWriter, r *http.Request, b *board) {
+	switch r.Method {
+	case http.MethodGet:
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if err := b.reload(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, b.content)
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var replacement model.ToDoContent
+		if err := json.Unmarshal(data, &replacement); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(replacement.Titles) == 0 || len(replacement.Items) != len(replacement.Titles) {
+			http.Error(w, "number of lanes and item lists differ", http.StatusBadRequest)
+			return
+		}
+		status, _, err := b.mutate(func(c *model.ToDoContent) (int, interface{}, error) {
+			// the board replaces the revision it was read as
+			if replacement.Revision != c.Revision {
+				return http.StatusConflict, nil, model.ErrConflict
+			}
+			if err := c.Decode(data); err != nil {
+				return http.StatusBadRequest, nil, err
+			}
+			return http.StatusNoContent, nil, nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(status)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleLanes(w http.ResponseWriter, r *http.Request, b *board) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	res, err := b.view(func(c *model.ToDoContent) interface{} {
+		lanes := make([]LaneInfo, c.GetNumLanes())
+		for i := range lanes {
+			lanes[i] = LaneInfo{Index: i, Title: c.Titles[i], Color: c.GetLaneColor(i), Sort: c.SortModes[i], Count: len(c.Items[i])}
+		}
+		return lanes
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleLaneItems(w http.ResponseWriter, r *http.Request, b *board, lane int) {
+	switch r.Method {
+	case http.MethodGet:
+		var found bool
+		res, err := b.view(func(c *model.ToDoContent) interface{} {
+			if lane < 0 || lane >= c.GetNumLanes() {
+				return nil
+			}
+			found = true
+			return c.GetLaneItems(lane)
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, "lane not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	case http.MethodPost:
+		var req NewItem
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		status, res, err := b.mutate(func(c *model.ToDoContent) (int, interface{}, error) {
+			if lane < 0 || lane >= c.GetNumLanes() {
+				return http.StatusNotFound, nil, errors.New("lane not found")
+			}
+			idx := len(c.Items[lane])
+			if req.Index != nil && *req.Index >= 0 && *req.Index < idx {
+				idx = *req.Index
+			}
+			if req.Priority == 0 {
+				req.Priority = 2
+			}
+			c.AddItem(lane, idx, req.Title, req.Secondary, req.Priority, req.Due, req.Color)
+			return http.StatusCreated, c.Items[lane][idx], nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		writeJSON(w, status, res)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request, b *board, guid string) {
+	switch r.Method {
+	case http.MethodGet:
+		var found bool
+		res, err := b.view(func(c *model.ToDoContent) interface{} {
+			lane, idx, ok := c.FindRef(guid)
+			if !ok {
+				return nil
+			}
+			found = true
+			return c.Items[lane][idx]
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, "item not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	case http.MethodPatch:
+		var patch map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		delete(patch, "Guid")
+		delete(patch, "Number")
+		data, _ := json.Marshal(patch)
+		status, res, err := b.mutate(func(c *model.ToDoContent) (int, interface{}, error) {
+			lane, idx, ok := c.FindRef(guid)
+			if !ok {
+				return http.StatusNotFound, nil, errors.New("item not found")
+			}
+			item := c.Items[lane][idx]
+			if err := json.Unmarshal(data, &item); err != nil {
+				return http.StatusBadRequest, nil, err
+			}
+			item.LastUpdate = time.Now().UTC().Format(time.RFC3339)
+			if _, ok := patch["UpdatedByName"]; !ok {
+				if usr, err := user.Current(); err == nil {
+					item.UpdatedByName = usr.Username
+				}
+			}
+			c.Items[lane][idx] = item
+			return http.StatusOK, item, nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		writeJSON(w, status, res)
+	case http.MethodDelete:
+		status, _, err := b.mutate(func(c *model.ToDoContent) (int, interface{}, error) {
+			lane, idx, ok := c.FindRef(guid)
+			if !ok {
+				return http.StatusNotFound, nil, errors.New("item not found")
+			}
+			c.DelItem(lane, idx)
+			return http.StatusNoContent, nil, nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(status)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request, b *board, guid string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req MoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	status, res, err := b.mutate(func(c *model.ToDoContent) (int, interface{}, error) {
+		lane, idx, ok := c.FindRef(guid)
+		if !ok {
+			return http.StatusNotFound, nil, errors.New("item not found")
+		}
+		if req.Lane < 0 || req.Lane >= c.GetNumLanes() {
+			return http.StatusBadRequest, nil, errors.New("invalid target lane")
+		}
+		maxIdx := len(c.Items[req.Lane])
+		if req.Lane == lane {
+			maxIdx--
+		}
+		if req.Index < 0 || req.Index > maxIdx {
+			req.Index = maxIdx
+		}
+		c.MoveItem(lane, idx, req.Lane, req.Index)
+		return http.StatusOK, c.Items[req.Lane][req.Index], nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, status, res)
+}
+
+func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request, b *board, guid string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status, _, err := b.mutate(func(c *model.ToDoContent) (int, interface{}, error) {
+		lane, idx, ok := c.FindRef(guid)
+		if !ok {
+			return http.StatusNotFound, nil, errors.New("item not found")
+		}
+		if err := c.ArchiveItem(lane, idx); err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		return http.StatusNoContent, nil, nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(status)
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request, b *board) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := b.subscribe()
+	defer b.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-ch:
+			fmt.Fprintf(w, "event: changed\ndata: %v\n\n", b.mode)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
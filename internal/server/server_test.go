@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	home := t.TempDir()
+	srv := New(home, "secret")
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		srv.Close()
+	})
+	return ts, home
+}
+
+func request(t *testing.T, method, url string, body interface{}, out interface{}) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, url, bytes.NewReader(data))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%v %v failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decoding response of %v %v failed: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestUnauthorized(t *testing.T) {
+	ts, _ := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/api/modes")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 got %d", resp.StatusCode)
+	}
+}
+
+func TestUnknownModeNotFound(t *testing.T) {
+	ts, _ := newTestServer(t)
+	if status := request(t, http.MethodGet, ts.URL+"/api/modes/work/lanes", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("expected 404 got %d", status)
+	}
+}
+
+func TestItemLifecycle(t *testing.T) {
+	ts, home := newTestServer(t)
+	base := ts.URL + "/api/modes/main"
+
+	var lanes []LaneInfo
+	if status := request(t, http.MethodGet, base+"/lanes", nil, &lanes); status != http.StatusOK {
+		t.Fatalf("listing lanes failed with %d", status)
+	}
+	if len(lanes) != 3 || lanes[1].Title != "Doing" {
+		t.Fatalf("unexpected lanes: %#v", lanes)
+	}
+
+	var created model.Item
+	if status := request(t, http.MethodPost, base+"/lanes/0/items", NewItem{Title: "task"}, &created); status != http.StatusCreated {
+		t.Fatalf("creating item failed with %d", status)
+	}
+	if created.Guid == "" || created.Priority != 2 {
+		t.Fatalf("unexpected item: %#v", created)
+	}
+
+	var patched model.Item
+	if status := request(t, http.MethodPatch, base+"/items/"+strconv.Itoa(created.Number), map[string]interface{}{"Title": "renamed", "Guid": "x", "Number": 99}, &patched); status != http.StatusOK {
+		t.Fatalf("patching item by number failed with %d", status)
+	}
+	if patched.Title != "renamed" || patched.Guid != created.Guid || patched.Number != created.Number {
+		t.Fatalf("unexpected patched item: %#v", patched)
+	}
+
+	if status := request(t, http.MethodPost, base+"/items/"+created.Guid+"/move", MoveRequest{Lane: 2}, nil); status != http.StatusOK {
+		t.Fatalf("moving item failed with %d", status)
+	}
+	var items []model.Item
+	request(t, http.MethodGet, base+"/lanes/2/items", nil, &items)
+	if len(items) != 1 || items[0].Guid != created.Guid {
+		t.Fatalf("item not moved: %#v", items)
+	}
+
+	if status := request(t, http.MethodPost, base+"/items/"+created.Guid+"/archive", nil, nil); status != http.StatusNoContent {
+		t.Fatalf("archiving item failed with %d", status)
+	}
+	if status := request(t, http.MethodGet, base+"/items/"+created.Guid, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("archived item still present: %d", status)
+	}
+	archived, _ := os.ReadDir(filepath.Join(home, ".todo", "archive"))
+	if len(archived) != 1 {
+		t.Fatalf("expected 1 archive file got %d", len(archived))
+	}
+}
+
+func TestEventsOnChange(t *testing.T) {
+	ts, _ := newTestServer(t)
+	base := ts.URL + "/api/modes/main"
+	request(t, http.MethodGet, base+"/lanes", nil, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/events", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("subscribing failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	events := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "event:") {
+				events <- scanner.Text()
+			}
+		}
+	}()
+
+	request(t, http.MethodPost, base+"/lanes/1/items", NewItem{Title: "task"}, nil)
+
+	select {
+	case ev := <-events:
+		if ev != "event: changed" {
+			t.Fatalf("unexpected event %q", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no change event received")
+	}
+}
+
+func TestBoardRevision(t *testing.T) {
+	ts, home := newTestServer(t)
+	base := ts.URL + "/api/modes/main"
+	var board model.ToDoContent
+	if status := request(t, http.MethodGet, base+"/board", nil, &board); status != http.StatusOK {
+		t.Fatalf("reading board failed with %d", status)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".todo", "todo.json")); !os.IsNotExist(err) {
+		t.Fatalf("board written when read: %v", err)
+	}
+
+	board.AddItem(0, 0, "task", "", 2, "", "")
+	if status := request(t, http.MethodPut, base+"/board", &board, nil); status != http.StatusNoContent {
+		t.Fatalf("replacing board failed with %d", status)
+	}
+	// the board is outdated after the first replacement
+	if status := request(t, http.MethodPut, base+"/board", &board, nil); status != http.StatusConflict {
+		t.Fatalf("expected 409 for outdated board got %d", status)
+	}
+	board.Revision++
+	if status := request(t, http.MethodPut, base+"/board", &board, nil); status != http.StatusNoContent {
+		t.Fatalf("replacing current board failed with %d", status)
+	}
+}
+
+func TestRemovedModeNotServed(t *testing.T) {
+	ts, home := newTestServer(t)
+	base := ts.URL + "/api/modes/work"
+	if status := request(t, http.MethodPut, base+"/board", model.ToDoContent{Titles: []string{"To Do"}, Items: [][]model.Item{nil}}, nil); status != http.StatusNoContent {
+		t.Fatalf("creating mode failed with %d", status)
+	}
+	if err := model.NewModeStore(home, nil).Remove("work"); err != nil {
+		t.Fatal(err)
+	}
+	if status := request(t, http.MethodGet, base+"/lanes", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("removed mode served, status %d", status)
+	}
+}
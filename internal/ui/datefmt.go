@@ -1,62 +1,32 @@
 package ui
 
 import (
-	"os"
-	"os/exec"
-	"runtime"
 	"strings"
 	"time"
+
+	"github.com/cklukas/todo/internal/quickadd"
 )
 
-// localeUS returns true if the system locale suggests an US date format.
-func localeUSApple(locale string) bool {
-	l := strings.ToLower(locale)
-	if idx := strings.Index(l, "@rg="); idx != -1 && len(l) >= idx+6 {
-		region := l[idx+4 : idx+6]
-		return region == "us"
-	}
-	if idx := strings.Index(l, "_"); idx != -1 && len(l) >= idx+3 {
-		region := l[idx+1 : idx+3]
-		return region == "us"
-	}
-	return strings.Contains(l, "us")
-}
+// dateFormat is the date format set in the settings, e.g. "dd.mm.yyyy". If
+// empty, the format is derived from the locale.
+var dateFormat string
 
-func localeUS() bool {
-	if runtime.GOOS == "darwin" {
-		locale := os.Getenv("AppleLocale")
-		if locale == "" {
-			out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
-			if err == nil {
-				locale = strings.TrimSpace(string(out))
-			}
-		}
-		if locale != "" {
-			return localeUSApple(locale)
-		}
+// SetDateFormat overrides the date format of the locale. Unknown formats
+// are ignored.
+func SetDateFormat(format string) {
+	if _, ok := quickadd.DateLayouts[format]; ok || format == "" {
+		dateFormat = format
 	}
-	lang := os.Getenv("LC_TIME")
-	if lang == "" {
-		lang = os.Getenv("LANG")
-	}
-	lang = strings.ToLower(lang)
-	return strings.Contains(lang, "us")
 }
 
 // dueLayout returns the date layout for the current locale.
 func dueLayout() string {
-	if localeUS() {
-		return "01/02/2006"
-	}
-	return "02.01.2006"
+	return quickadd.DateLayouts[duePlaceholder()]
 }
 
 // duePlaceholder returns the placeholder string for due date entry.
 func duePlaceholder() string {
-	if localeUS() {
-		return "mm/dd/yyyy"
-	}
-	return "dd.mm.yyyy"
+	return quickadd.DateFormat(dateFormat)
 }
 
 // isoToLocal converts an ISO date (YYYY-MM-DD) to the locale specific format.
@@ -105,7 +75,7 @@ func isoTimeToLocal(iso string) string {
 // clockDateLayout returns the date layout for the clock without the year.
 // The order of day and month depends on the locale.
 func clockDateLayout() string {
-	if localeUS() {
+	if strings.HasPrefix(duePlaceholder(), "mm") {
 		return "Mon Jan 02"
 	}
 	return "Mon 02 Jan"
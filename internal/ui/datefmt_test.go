@@ -6,21 +6,6 @@ import (
 	"time"
 )
 
-func TestLocaleUSApple(t *testing.T) {
-	cases := map[string]bool{
-		"en_US":           true,
-		"en_US@rg=uszzzz": true,
-		"en_US@rg=dezzzz": false,
-		"de_DE":           false,
-		"en_GB":           false,
-	}
-	for in, want := range cases {
-		if got := localeUSApple(in); got != want {
-			t.Fatalf("localeUSApple(%q)=%v want %v", in, got, want)
-		}
-	}
-}
-
 func TestIsoTimeToLocal(t *testing.T) {
 	iso := "2025-03-23T14:11:51Z"
 
@@ -42,3 +27,23 @@ func TestIsoTimeToLocal(t *testing.T) {
 	os.Unsetenv("LC_TIME")
 	os.Unsetenv("AppleLocale")
 }
+
+func TestSetDateFormat(t *testing.T) {
+	defer SetDateFormat("")
+
+	SetDateFormat("yyyy-mm-dd")
+	if got := isoToLocal("2025-03-23"); got != "2025-03-23" {
+		t.Fatalf("isoToLocal=%q", got)
+	}
+	if iso, err := localToISO("2025-03-23"); err != nil || iso != "2025-03-23" {
+		t.Fatalf("localToISO=%q (%v)", iso, err)
+	}
+	SetDateFormat("mm/dd/yyyy")
+	if clockDateLayout() != "Mon Jan 02" {
+		t.Fatalf("unexpected clock layout %q", clockDateLayout())
+	}
+	SetDateFormat("invalid")
+	if duePlaceholder() != "mm/dd/yyyy" {
+		t.Fatalf("invalid format applied: %q", duePlaceholder())
+	}
+}
@@ -14,6 +14,9 @@ func TestDueSuffix(t *testing.T) {
 	if s := dueSuffix("2025-06-11", now); s != "[tomorrow]" {
 		t.Fatalf("expected [tomorrow] got %s", s)
 	}
+	if s := dueSuffix("2025-06-09", now); s != "[overdue]" {
+		t.Fatalf("expected [overdue] got %s", s)
+	}
 	if s := dueSuffix("2025-06-12", now); s != "" {
 		t.Fatalf("expected empty suffix got %s", s)
 	}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/quickadd"
 )
 
 // ModalInput is based on Modal from tview, but has an input field instead
@@ -30,9 +33,67 @@ type ModalInput struct {
 	updated      string
 	titleField   *tview.InputField
 	okButton     *tview.Button
+	template     string
 	done         func(string, string, bool)
+
+	// item templates of the Add Task dialog, the selected one (0 is none)
+	// and the fields they prefill
+	itemTemplates []model.ItemTemplate
+	itemTemplate  int
+	note          string
+	detailsField  *tview.InputField
+	dueField      *tview.InputField
+	priorityField *tview.DropDown
+	colorField    *tview.DropDown
+
+	// lanes for the quick-add syntax in the title, see quickadd.Parse, and
+	// the preview of the parsed attributes
+	quickAdd      bool
+	quickAddLanes []string
+	preview       *tview.TextView
+
+	// links of the edited task, the tasks it can be linked to and the
+	// chosen change, see SetLinks
+	links       []string
+	linkTargets []LinkTarget
+	linkAction  int
+	linkTarget  int
+
+	// assignees of the task as entered and the members of the board offered
+	// for completion, see SetAssignees
+	showAssignees bool
+	assignees     string
+	members       []string
+
+	// party the task waits on and the follow-up date as entered, see
+	// SetWaiting
+	showWaiting bool
+	waitingOn   string
+	followUp    string
+
+	// GUID of the epic chosen from the link targets, see SetEpic
+	showEpic bool
+	epic     string
+
+	// estimate of the task as entered and its unit, see SetEstimate
+	showEstimate bool
+	estimate     string
+	estimateUnit string
+}
+
+// LinkTarget is a task offered in the link picker of the edit dialog.
+type LinkTarget struct {
+	Guid string
+	Text string
 }
 
+// linkActions are the choices of the link picker, linkTypes the link types
+// they set.
+var (
+	linkActions = []string{"(no change)", "blocks", "blocked by", "relates to", "remove link"}
+	linkTypes   = []string{"", model.LinkBlocks, model.LinkBlockedBy, model.LinkRelates, "remove"}
+)
+
 func (m *ModalInput) GetFrame() *tview.Frame {
 	return m.frame
 }
@@ -139,16 +200,41 @@ func NewModalInputMode(title, modeDirectory string) *ModalInput {
 	return m
 }
 
+// SetTemplates adds a dropdown for selecting the board template of a new
+// mode, the first template is preselected. Only the first call has an
+// effect.
+func (m *ModalInput) SetTemplates(names []string) {
+	if len(names) == 0 || m.template != "" {
+		return
+	}
+	m.template = names[0]
+	m.AddDropDown("Template:", names, 0, func(option string, index int) {
+		m.template = option
+	})
+	m.DialogHeight += 2
+}
+
+// GetTemplate returns the selected board template.
+func (m *ModalInput) GetTemplate() string {
+	return m.template
+}
+
 func NewModalInputLane(title, laneDescription string, dialogHeight int, initialInput1 string) *ModalInput {
+	return NewModalInputText(title, "Lane:", laneDescription, dialogHeight, initialInput1)
+}
+
+// NewModalInputText returns a dialog with a single input field with the
+// given label and a description below.
+func NewModalInputText(title, label, description string, dialogHeight int, initialInput1 string) *ModalInput {
 	form := tview.NewForm()
 	m := &ModalInput{Form: form, DialogHeight: dialogHeight, frame: tview.NewFrame(form), main: "", secondary: "", due: "", priority: 2, showPriority: false, showDue: false, laneColor: "", createdBy: "", created: "", updatedBy: "", updated: "", titleField: nil, okButton: nil, done: nil}
 	m.main = initialInput1
 
-	form.AddInputField("Lane:", initialInput1, 50, nil, func(text string) {
+	form.AddInputField(label, initialInput1, 50, nil, func(text string) {
 		m.main = text
 	})
 
-	m.frame.AddText(laneDescription, false, 0, tcell.ColorDarkGray)
+	m.frame.AddText(description, false, 0, tcell.ColorDarkGray)
 
 	m.SetButtonsAlign(tview.AlignCenter).
 		SetButtonBackgroundColor(tview.Styles.PrimitiveBackgroundColor).
@@ -182,6 +268,25 @@ func (m *ModalInput) SetValue(text string, secondary string, due string) {
 	m.secondary = secondary
 	m.due = due
 	m.Clear(false)
+	m.detailsField, m.dueField, m.priorityField, m.colorField = nil, nil, nil, nil
+
+	if len(m.itemTemplates) > 0 {
+		options := []string{"(none)"}
+		for _, t := range m.itemTemplates {
+			options = append(options, t.Name)
+		}
+		m.AddDropDown("Template:", options, m.itemTemplate, func(option string, index int) {
+			if index < 0 || index == m.itemTemplate {
+				return
+			}
+			m.itemTemplate = index
+			if index > 0 {
+				m.applyItemTemplate(m.itemTemplates[index-1])
+			} else {
+				m.note = ""
+			}
+		})
+	}
 
 	var titleField *tview.InputField
 	if m.showColor {
@@ -194,6 +299,7 @@ func (m *ModalInput) SetValue(text string, secondary string, due string) {
 			}
 			m.main = text
 			m.updateOKButton()
+			m.updatePreview()
 		})
 		idx := 0
 		for i, c := range m.colors {
@@ -206,6 +312,7 @@ func (m *ModalInput) SetValue(text string, secondary string, due string) {
 		ci.dropdown.SetSelectedFunc(func(option string, index int) {
 			m.color = m.colors[index]
 		})
+		m.colorField = ci.dropdown
 		m.AddFormItem(ci)
 	} else {
 		m.Form, titleField = m.Form.AddInputField("Title:", text, 50, nil, func(text string) {
@@ -214,10 +321,18 @@ func (m *ModalInput) SetValue(text string, secondary string, due string) {
 			}
 			m.main = text
 			m.updateOKButton()
+			m.updatePreview()
 		})
 	}
 	m.titleField = titleField
-	m.AddInputField("Details:", secondary, 50, nil, func(text string) {
+	m.preview = nil
+	if m.quickAdd {
+		m.preview = tview.NewTextView().SetLabel("Sets:").SetSize(1, 50).SetScrollable(false)
+		m.preview.SetTextColor(tcell.ColorDarkGray)
+		m.AddFormItem(m.preview)
+		m.updatePreview()
+	}
+	_, m.detailsField = m.AddInputField("Details:", secondary, 50, nil, func(text string) {
 		m.secondary = text
 	})
 	if m.showDue {
@@ -258,13 +373,75 @@ func (m *ModalInput) SetValue(text string, secondary string, due string) {
 			return event
 		})
 		dateField.SetText(due)
+		m.dueField = dateField
 		m.AddFormItem(dateField)
 	}
 	if m.showPriority {
-		options := []string{"1 (high)", "2 (normal)", "3 (low)", "4 (idle)"}
-		m.AddDropDown("Priority:", options, m.priority-1, func(option string, index int) {
+		m.AddDropDown("Priority:", priorityNames, m.priority-1, func(option string, index int) {
 			m.priority = index + 1
 		})
+		m.priorityField = m.GetFormItem(m.GetFormItemCount() - 1).(*tview.DropDown)
+	}
+	if m.showEstimate {
+		m.AddInputField("Estimate ("+m.estimateUnit+"):", m.estimate, 8, nil, func(text string) {
+			m.estimate = text
+		})
+	}
+	if m.showAssignees {
+		field := tview.NewInputField().SetLabel("Assignees:").SetFieldWidth(50).SetText(m.assignees)
+		field.SetChangedFunc(func(text string) {
+			m.assignees = text
+		})
+		field.SetAutocompleteFunc(m.completeAssignee)
+		m.AddFormItem(field)
+	}
+	if m.showWaiting {
+		m.AddInputField("Waiting on:", m.waitingOn, 50, nil, func(text string) {
+			m.waitingOn = text
+		})
+		field := tview.NewInputField().SetLabel("Follow-up:").SetFieldWidth(20).SetText(m.followUp).SetPlaceholder("e.g. fri or +3d")
+		field.SetChangedFunc(func(text string) {
+			m.followUp = text
+		})
+		m.AddFormItem(field)
+	}
+	extraLines := 0
+	if len(m.linkTargets) > 0 {
+		if len(m.links) > 0 {
+			tv := tview.NewTextView().SetLabel("Links:").SetSize(len(m.links), 50).SetText(strings.Join(m.links, "\n")).SetScrollable(false)
+			tv.SetTextColor(tcell.ColorDarkGray)
+			m.AddFormItem(tv)
+			extraLines += len(m.links) - 1
+		}
+		m.AddDropDown("Link:", linkActions, m.linkAction, func(option string, index int) {
+			if index >= 0 {
+				m.linkAction = index
+			}
+		})
+		targets := make([]string, len(m.linkTargets))
+		for i, t := range m.linkTargets {
+			targets[i] = tview.Escape(t.Text)
+		}
+		m.AddDropDown("Link task:", targets, m.linkTarget, func(option string, index int) {
+			if index >= 0 {
+				m.linkTarget = index
+			}
+		})
+		if m.showEpic {
+			current := 0
+			for i, t := range m.linkTargets {
+				if t.Guid == m.epic {
+					current = i + 1
+				}
+			}
+			m.AddDropDown("Epic:", append([]string{"(none)"}, targets...), current, func(option string, index int) {
+				if index == 0 {
+					m.epic = ""
+				} else if index > 0 {
+					m.epic = m.linkTargets[index-1].Guid
+				}
+			})
+		}
 	}
 	if m.createdBy != "" && m.created != "" {
 		txt := fmt.Sprintf("%s (%s)", m.created, m.createdBy)
@@ -280,8 +457,180 @@ func (m *ModalInput) SetValue(text string, secondary string, due string) {
 	}
 
 	itemCount := m.GetFormItemCount()
-	m.DialogHeight = 2*itemCount + 5
+	m.DialogHeight = 2*itemCount + 5 + extraLines
 	m.updateOKButton()
+	if len(m.itemTemplates) > 0 {
+		// start with the title, the template dropdown is above it
+		m.Form.SetFocus(1)
+	}
+}
+
+// SetLinks adds a picker to the dialog which links the task to one of the
+// targets, or removes the link to it. The existing links are listed. They are
+// shown by the next call of SetValue, until ClearExtras is called.
+func (m *ModalInput) SetLinks(links []string, targets []LinkTarget) {
+	m.links = links
+	m.linkTargets = targets
+	m.linkAction = 0
+	m.linkTarget = 0
+}
+
+// GetLink returns the link type chosen in the link picker, or "remove", and
+// the GUID of the task to link. The type is empty if nothing was chosen.
+func (m *ModalInput) GetLink() (string, string) {
+	if len(m.linkTargets) == 0 || m.linkTarget >= len(m.linkTargets) {
+		return "", ""
+	}
+	return linkTypes[m.linkAction], m.linkTargets[m.linkTarget].Guid
+}
+
+// SetEpic adds a picker for the epic of the task to the dialog, offering the
+// targets of the link picker, see SetLinks. It is shown by the next call of
+// SetValue, until ClearExtras is called.
+func (m *ModalInput) SetEpic(epic string) {
+	m.showEpic = true
+	m.epic = epic
+}
+
+// GetEpic returns the GUID of the epic chosen, empty for none.
+func (m *ModalInput) GetEpic() string {
+	return m.epic
+}
+
+// SetAssignees adds a field for the assignees of the task to the dialog, the
+// members of the board are offered for completion. It is shown by the next
+// call of SetValue, until ClearExtras is called.
+func (m *ModalInput) SetAssignees(assignees, members []string) {
+	m.showAssignees = true
+	m.assignees = strings.Join(assignees, ", ")
+	m.members = members
+}
+
+// GetAssignees returns the assignees entered.
+func (m *ModalInput) GetAssignees() []string {
+	return model.ParseAssignees(m.assignees)
+}
+
+// completeAssignee offers the members starting with the name being entered
+// after the last comma.
+func (m *ModalInput) completeAssignee(text string) []string {
+	start := strings.LastIndex(text, ",") + 1
+	prefix := text[:start]
+	if start > 0 {
+		prefix += " "
+	}
+	name := strings.ToLower(strings.TrimSpace(text[start:]))
+	if name == "" {
+		return nil
+	}
+	var res []string
+	for _, member := range m.members {
+		if strings.HasPrefix(strings.ToLower(member), name) {
+			res = append(res, prefix+member)
+		}
+	}
+	return res
+}
+
+// SetWaiting adds fields for the party the task waits on and the follow-up
+// date to the dialog. It is shown by the next call of SetValue, until
+// ClearExtras is called.
+func (m *ModalInput) SetWaiting(on, followUp string) {
+	m.showWaiting = true
+	m.waitingOn = on
+	m.followUp = followUp
+}
+
+// GetWaiting returns the party the task waits on, empty if it does not
+// wait, and the follow-up date as entered.
+func (m *ModalInput) GetWaiting() (string, string) {
+	return strings.TrimSpace(m.waitingOn), strings.TrimSpace(m.followUp)
+}
+
+// SetEstimate adds a field for the estimate of the task in the given unit
+// to the dialog, 0 is shown as empty field. It is shown by the next call of
+// SetValue, until ClearExtras is called.
+func (m *ModalInput) SetEstimate(estimate float64, unit string) {
+	m.showEstimate = true
+	m.estimate = ""
+	if estimate > 0 {
+		m.estimate = model.FormatEstimate(estimate, "")
+	}
+	m.estimateUnit = unit
+}
+
+// GetEstimate returns the estimate entered, 0 if the field is empty.
+func (m *ModalInput) GetEstimate() (float64, error) {
+	return model.ParseEstimate(m.estimate, m.estimateUnit)
+}
+
+// SetItemTemplates adds a dropdown to the dialog which prefills the fields
+// with one of the given item templates. It is shown by the next call of
+// SetValue, until ClearExtras is called.
+func (m *ModalInput) SetItemTemplates(templates []model.ItemTemplate) {
+	m.itemTemplates = append([]model.ItemTemplate{}, templates...)
+	m.itemTemplate = 0
+	m.note = ""
+}
+
+// applyItemTemplate fills the fields shown with the values of an item
+// template. The title is set to the pattern of the template, ready for
+// typing the rest.
+func (m *ModalInput) applyItemTemplate(t model.ItemTemplate) {
+	m.note = t.Note
+	if m.titleField != nil {
+		m.titleField.SetText(t.TaskTitle(""))
+	}
+	if m.detailsField != nil && t.Secondary != "" {
+		m.detailsField.SetText(t.Secondary)
+	}
+	if m.dueField != nil {
+		if due, err := model.DueFromOffset(t.Due, time.Now()); err == nil {
+			m.dueField.SetText(isoToLocal(due))
+		}
+	}
+	if m.priorityField != nil {
+		m.priorityField.SetCurrentOption(t.TaskPriority() - 1)
+	}
+	if m.colorField != nil {
+		idx := 0
+		for i, c := range m.colors {
+			if c == t.Color {
+				idx = i
+				break
+			}
+		}
+		m.colorField.SetCurrentOption(idx)
+	}
+}
+
+// SetQuickAdd enables the quick-add syntax in the title with the given lanes
+// as targets and shows a preview of the attributes set.
+func (m *ModalInput) SetQuickAdd(lanes []string) {
+	m.quickAdd = true
+	m.quickAddLanes = lanes
+}
+
+// GetQuickAdd parses the title. It returns false if the quick-add syntax is
+// not enabled.
+func (m *ModalInput) GetQuickAdd() (quickadd.Task, bool) {
+	if !m.quickAdd {
+		return quickadd.Task{}, false
+	}
+	return quickadd.Parse(m.main, m.quickAddLanes, dateFormat, time.Now()), true
+}
+
+func (m *ModalInput) updatePreview() {
+	if m.preview == nil {
+		return
+	}
+	q := quickadd.Parse(m.main, m.quickAddLanes, dateFormat, time.Now())
+	m.preview.SetText(describeQuickAdd(q, m.quickAddLanes))
+}
+
+// GetNote returns the note of the selected item template, if any.
+func (m *ModalInput) GetNote() string {
+	return m.note
 }
 
 // formatDueInput formats a date input so that dots are inserted after day and
@@ -292,7 +641,7 @@ func formatDueInput(text string) string {
 	if len(digits) > 8 {
 		digits = digits[:8]
 	}
-	if localeUS() {
+	if quickadd.LocaleUS() {
 		switch {
 		case len(digits) > 4:
 			return digits[:2] + "/" + digits[2:4] + "/" + digits[4:]
@@ -408,6 +757,26 @@ func (m *ModalInput) ClearExtras() {
 	m.updatedBy = ""
 	m.updated = ""
 	m.due = ""
+	m.itemTemplates = nil
+	m.itemTemplate = 0
+	m.note = ""
+	m.quickAdd = false
+	m.quickAddLanes = nil
+	m.links = nil
+	m.linkTargets = nil
+	m.linkAction = 0
+	m.linkTarget = 0
+	m.showAssignees = false
+	m.assignees = ""
+	m.members = nil
+	m.showWaiting = false
+	m.waitingOn = ""
+	m.followUp = ""
+	m.showEpic = false
+	m.epic = ""
+	m.showEstimate = false
+	m.estimate = ""
+	m.estimateUnit = ""
 }
 
 // SetDoneFunc sets the done func for this input.
@@ -0,0 +1,328 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Actions which can be bound to keys.
+const (
+	ActionAbout    = "about"
+	ActionQuit     = "quit"
+	ActionAdd      = "add"
+	ActionAddBelow = "add-below"
+	ActionEdit     = "edit"
+	ActionNote     = "note"
+	ActionArchive  = "archive"
+	ActionDelete   = "delete"
+	ActionSelect   = "select"
+	ActionLaneCmds = "lane-cmds"
+	ActionMode     = "mode"
+	ActionTheme    = "theme"
+	ActionSettings = "settings"
+	ActionOverview = "overview"
+	ActionMoveMode = "move-to-mode"
+	ActionCopyMode = "copy-to-mode"
+	ActionMark     = "toggle-mark"
+	ActionBulk     = "bulk"
+	ActionDetail   = "detail"
+	ActionLinks    = "links"
+	ActionTimer    = "timer"
+	ActionTimeLog  = "time-log"
+	ActionPomodoro = "pomodoro"
+	ActionMineOnly = "mine-only"
+	ActionComment  = "comment"
+	ActionWaiting  = "waiting"
+	ActionSprint   = "sprint"
+	ActionEpic     = "epic"
+	ActionGoto     = "goto"
+	ActionSearch   = "search"
+	ActionDetailUp = "detail-up"
+	ActionDetailDn = "detail-down"
+	ActionNextLane = "next-lane"
+	ActionPrevLane = "prev-lane"
+	ActionLeft     = "left"
+	ActionRight    = "right"
+	ActionUp       = "up"
+	ActionDown     = "down"
+)
+
+type actionInfo struct {
+	name        string
+	description string
+}
+
+// actions lists all actions in the order used for the help text.
+var actions = []actionInfo{
+	{ActionSelect, "select task to move"},
+	{ActionLeft, "previous lane / move marked task"},
+	{ActionRight, "next lane / move marked task"},
+	{ActionUp, "cursor up / move marked task"},
+	{ActionDown, "cursor down / move marked task"},
+	{ActionAdd, "add"},
+	{ActionAddBelow, "add below"},
+	{ActionEdit, "edit"},
+	{ActionDelete, "delete task"},
+	{ActionNote, "note"},
+	{ActionArchive, "archive"},
+	{ActionNextLane, "next lane"},
+	{ActionPrevLane, "previous lane"},
+	{ActionLaneCmds, "lane commands"},
+	{ActionMode, "select mode"},
+	{ActionTheme, "select theme"},
+	{ActionSettings, "settings"},
+	{ActionOverview, "overview of all modes"},
+	{ActionMoveMode, "move task to mode"},
+	{ActionCopyMode, "copy task to mode"},
+	{ActionMark, "mark task for bulk operations"},
+	{ActionBulk, "operations on marked tasks"},
+	{ActionDetail, "show/hide details"},
+	{ActionLinks, "go to linked task"},
+	{ActionTimer, "start/stop timer"},
+	{ActionTimeLog, "edit time entries"},
+	{ActionPomodoro, "start/stop pomodoro"},
+	{ActionMineOnly, "show only my tasks / all tasks"},
+	{ActionComment, "add comment"},
+	{ActionWaiting, "show only waiting tasks / all tasks"},
+	{ActionSprint, "sprint burndown chart"},
+	{ActionEpic, "child tasks of the epic"},
+	{ActionGoto, "go to task by number"},
+	{ActionSearch, "show only tasks matching a text or number / all tasks"},
+	{ActionDetailUp, "scroll details up"},
+	{ActionDetailDn, "scroll details down"},
+	{ActionAbout, "help"},
+	{ActionQuit, "quit"},
+}
+
+var defaultBindings = map[string][]string{
+	ActionAbout:    {"F1", "h", "?"},
+	ActionQuit:     {"F10", "q"},
+	ActionAdd:      {"F2", "Insert", "+"},
+	ActionAddBelow: {},
+	ActionEdit:     {"F3", "e"},
+	ActionNote:     {"F4", "n"},
+	ActionArchive:  {"F5", "a"},
+	ActionDelete:   {"Delete", "d"},
+	ActionSelect:   {"F6"},
+	ActionLaneCmds: {"F7"},
+	ActionMode:     {"m"},
+	ActionTheme:    {"t"},
+	ActionSettings: {"F9", "s"},
+	ActionOverview: {"F8", "v"},
+	ActionMoveMode: {"M"},
+	ActionCopyMode: {"C"},
+	ActionMark:     {"x"},
+	ActionBulk:     {"b"},
+	ActionDetail:   {"p"},
+	ActionLinks:    {"g"},
+	ActionTimer:    {"T"},
+	ActionTimeLog:  {"w"},
+	ActionPomodoro: {"P"},
+	ActionMineOnly: {"f"},
+	ActionComment:  {"c"},
+	ActionWaiting:  {"W"},
+	ActionSprint:   {"S"},
+	ActionEpic:     {"E"},
+	ActionGoto:     {"#"},
+	ActionSearch:   {"/"},
+	ActionDetailUp: {"Ctrl-U"},
+	ActionDetailDn: {"Ctrl-D"},
+	ActionNextLane: {"Tab"},
+	ActionPrevLane: {"Backtab"},
+	ActionLeft:     {"Left"},
+	ActionRight:    {"Right"},
+	ActionUp:       {"Up"},
+	ActionDown:     {"Down"},
+}
+
+// presets contains the built-in keymaps, given as changes to the default
+// bindings.
+var presets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		ActionAbout:    {"F1", "?"},
+		ActionQuit:     {"F10", "q", ":q"},
+		ActionAdd:      {"F2", "Insert", "+", "O"},
+		ActionAddBelow: {"o"},
+		ActionEdit:     {"F3", "e", "i"},
+		ActionDelete:   {"Delete", "dd"},
+		ActionLeft:     {"Left", "h"},
+		ActionRight:    {"Right", "l"},
+		ActionUp:       {"Up", "k"},
+		ActionDown:     {"Down", "j"},
+	},
+}
+
+// specialKeys maps the names used in keymaps to tcell keys.
+var specialKeys = map[string]tcell.Key{}
+
+func init() {
+	for k, name := range tcell.KeyNames {
+		specialKeys[strings.ToLower(name)] = k
+	}
+	specialKeys["del"] = tcell.KeyDelete
+	specialKeys["ins"] = tcell.KeyInsert
+	specialKeys["escape"] = tcell.KeyEscape
+}
+
+// Keymap maps key sequences to actions.
+type Keymap struct {
+	bindings  map[string][]string
+	sequences map[string]string
+	prefixes  map[string]bool
+}
+
+// DefaultKeymap returns the keymap used if nothing is configured.
+func DefaultKeymap() *Keymap {
+	km, _ := NewKeymap("default", nil)
+	return km
+}
+
+// Presets returns the names of the built-in keymaps.
+func Presets() []string {
+	res := make([]string, 0, len(presets))
+	for name := range presets {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// NewKeymap creates a keymap from a preset and bindings which replace the
+// keys of single actions.
+func NewKeymap(preset string, bindings map[string][]string) (*Keymap, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	changes, ok := presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown keymap preset '%v'", preset)
+	}
+	km := &Keymap{bindings: make(map[string][]string), sequences: make(map[string]string), prefixes: make(map[string]bool)}
+	for action, keys := range defaultBindings {
+		km.bindings[action] = keys
+	}
+	for action, keys := range changes {
+		km.bindings[action] = keys
+	}
+	for action, keys := range bindings {
+		if _, ok := defaultBindings[action]; !ok {
+			return nil, fmt.Errorf("unknown action '%v' in keymap", action)
+		}
+		km.bindings[action] = keys
+	}
+
+	for action, keys := range km.bindings {
+		for _, key := range keys {
+			seq, err := parseKeySequence(key)
+			if err != nil {
+				return nil, err
+			}
+			if other, ok := km.sequences[strings.Join(seq, " ")]; ok && other != action {
+				return nil, fmt.Errorf("key '%v' is bound to '%v' and '%v'", key, other, action)
+			}
+			km.sequences[strings.Join(seq, " ")] = action
+			for i := 1; i < len(seq); i++ {
+				km.prefixes[strings.Join(seq[:i], " ")] = true
+			}
+		}
+	}
+	return km, nil
+}
+
+// parseKeySequence splits a key definition into key names. Special keys
+// ("F2", "Ctrl-N", "Tab") are single keys, other texts are sequences of
+// characters, e.g. "dd" for pressing 'd' twice.
+func parseKeySequence(key string) ([]string, error) {
+	if key == "" {
+		return nil, fmt.Errorf("empty key in keymap")
+	}
+	if name, ok := normalizeSpecialKey(key); ok {
+		return []string{name}, nil
+	}
+	if idx := strings.IndexAny(key, "+-"); idx > 0 && utf8.RuneCountInString(key) > 1 {
+		modifier := strings.ToLower(key[:idx])
+		if modifier == "alt" && utf8.RuneCountInString(key[idx+1:]) == 1 {
+			return []string{"Alt-" + key[idx+1:]}, nil
+		}
+		if modifier == "alt" || modifier == "ctrl" || modifier == "shift" {
+			return nil, fmt.Errorf("unknown key '%v' in keymap", key)
+		}
+	}
+	seq := make([]string, 0, len(key))
+	for _, r := range key {
+		seq = append(seq, string(r))
+	}
+	return seq, nil
+}
+
+// normalizeSpecialKey returns the canonical name of a special key, e.g.
+// "Ctrl-N" for "ctrl+n".
+func normalizeSpecialKey(key string) (string, bool) {
+	lower := strings.ToLower(strings.ReplaceAll(key, "+", "-"))
+	if lower == "space" {
+		return " ", true
+	}
+	if k, ok := specialKeys[lower]; ok {
+		return tcell.KeyNames[k], true
+	}
+	return "", false
+}
+
+// keyName returns the name of a key event as used in key sequences.
+func keyName(event *tcell.EventKey) string {
+	if event.Key() == tcell.KeyRune {
+		if event.Modifiers()&tcell.ModAlt != 0 {
+			return "Alt-" + string(event.Rune())
+		}
+		return string(event.Rune())
+	}
+	if name, ok := tcell.KeyNames[event.Key()]; ok {
+		return name
+	}
+	return ""
+}
+
+// Lookup returns the action bound to the given key sequence and whether the
+// sequence is the beginning of a longer binding.
+func (km *Keymap) Lookup(seq []string) (string, bool) {
+	key := strings.Join(seq, " ")
+	return km.sequences[key], km.prefixes[key]
+}
+
+// Keys returns the keys bound to an action.
+func (km *Keymap) Keys(action string) []string {
+	return km.bindings[action]
+}
+
+// HelpText describes all bound keys.
+func (km *Keymap) HelpText() string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		if keys := km.KeyText(a.name); keys != "" {
+			parts = append(parts, keys+" - "+a.description)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// KeyText describes the keys bound to an action, e.g. "F3/e".
+func (km *Keymap) KeyText(action string) string {
+	keys := km.bindings[action]
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = displayKey(k)
+	}
+	return strings.Join(names, "/")
+}
+
+func displayKey(key string) string {
+	if strings.EqualFold(key, "delete") {
+		return "Del"
+	}
+	return key
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func runeEvent(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestDefaultKeymapLookup(t *testing.T) {
+	km := DefaultKeymap()
+	if action, _ := km.Lookup([]string{"F2"}); action != ActionAdd {
+		t.Fatalf("F2 bound to %q", action)
+	}
+	if action, _ := km.Lookup([]string{"d"}); action != ActionDelete {
+		t.Fatalf("d bound to %q", action)
+	}
+}
+
+func TestNewKeymapErrors(t *testing.T) {
+	if _, err := NewKeymap("emacs", nil); err == nil {
+		t.Fatalf("expected error for unknown preset")
+	}
+	if _, err := NewKeymap("", map[string][]string{"fly": {"f"}}); err == nil {
+		t.Fatalf("expected error for unknown action")
+	}
+	if _, err := NewKeymap("", map[string][]string{ActionEdit: {"d"}}); err == nil {
+		t.Fatalf("expected error for conflicting binding")
+	}
+	if _, err := NewKeymap("", map[string][]string{ActionEdit: {"Ctrl+E"}}); err != nil {
+		t.Fatalf("ctrl binding rejected: %v", err)
+	}
+}
+
+func TestVimPresetChord(t *testing.T) {
+	km, err := NewKeymap("vim", nil)
+	if err != nil {
+		t.Fatalf("vim preset failed: %v", err)
+	}
+	if action, prefix := km.Lookup([]string{"d"}); action != "" || !prefix {
+		t.Fatalf("d should start a chord, got %q %v", action, prefix)
+	}
+	if action, _ := km.Lookup([]string{"d", "d"}); action != ActionDelete {
+		t.Fatalf("dd bound to %q", action)
+	}
+	if !strings.Contains(km.HelpText(), "dd - delete task") {
+		t.Fatalf("help text misses chord: %s", km.HelpText())
+	}
+}
+
+func TestHotKeyHandlerChord(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	km, _ := NewKeymap("vim", nil)
+	l.SetKeymap(km)
+
+	if ev := l.HotKeyHandler(runeEvent('d')); ev != nil {
+		t.Fatalf("first key of chord not consumed")
+	}
+	if name, _ := l.pages.GetFrontPage(); name == "delete" {
+		t.Fatalf("delete dialog shown after first key")
+	}
+	l.HotKeyHandler(runeEvent('d'))
+	if name, _ := l.pages.GetFrontPage(); name != "delete" {
+		t.Fatalf("delete dialog not shown, front page %q", name)
+	}
+
+	if ev := l.HotKeyHandler(runeEvent('j')); ev == nil || ev.Key() != tcell.KeyDown {
+		t.Fatalf("j not translated to cursor down")
+	}
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+var (
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdCheckbox = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	mdBullet   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdNumbered = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdQuote    = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdRule     = regexp.MustCompile(`^\s*(-\s*){3,}$|^\s*(\*\s*){3,}$|^\s*(_\s*){3,}$`)
+	mdInline   = regexp.MustCompile("`[^`]+`|\\[[^\\]]+\\]\\([^)\\s]+\\)|\\*\\*[^*]+\\*\\*|__[^_]+__|\\*[^*\\s][^*]*\\*")
+)
+
+// renderMarkdown converts a Markdown note to text with color tags for a
+// TextView. Headings, lists with checkboxes, quotes, rules, code blocks,
+// inline code, links and emphasis are supported; everything else is shown
+// as it is.
+func renderMarkdown(text string, t *Theme) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, tag(t.SecondaryText)+"  "+tview.Escape(line)+"[-]")
+			continue
+		}
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			style := "::b"
+			if len(m[1]) == 1 {
+				style = "::bu"
+			}
+			out = append(out, "["+t.Title+style+"]"+tview.Escape(m[2])+"[-::-]")
+			continue
+		}
+		if mdRule.MatchString(line) {
+			out = append(out, tag(t.Border)+strings.Repeat("─", 20)+"[-]")
+			continue
+		}
+		if m := mdCheckbox.FindStringSubmatch(line); m != nil {
+			box := "☐ "
+			if m[2] != " " {
+				box = "☑ "
+			}
+			out = append(out, m[1]+box+renderInline(m[3], t))
+			continue
+		}
+		if m := mdBullet.FindStringSubmatch(line); m != nil {
+			out = append(out, m[1]+"• "+renderInline(m[2], t))
+			continue
+		}
+		if m := mdNumbered.FindStringSubmatch(line); m != nil {
+			out = append(out, m[1]+m[2]+" "+renderInline(m[3], t))
+			continue
+		}
+		if m := mdQuote.FindStringSubmatch(line); m != nil {
+			out = append(out, tag(t.Border)+"│[-] [::i]"+renderInline(m[1], t)+"[::-]")
+			continue
+		}
+		out = append(out, renderInline(line, t))
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderInline formats inline code, links and emphasis of a line.
+func renderInline(line string, t *Theme) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range mdInline.FindAllStringIndex(line, -1) {
+		b.WriteString(tview.Escape(line[last:loc[0]]))
+		last = loc[1]
+		token := line[loc[0]:loc[1]]
+		switch {
+		case strings.HasPrefix(token, "`"):
+			b.WriteString(tag(t.SecondaryText) + tview.Escape(strings.Trim(token, "`")) + "[-]")
+		case strings.HasPrefix(token, "["):
+			idx := strings.Index(token, "](")
+			label, url := token[1:idx], token[idx+2:len(token)-1]
+			b.WriteString("[::u]" + tview.Escape(label) + "[::-] " + tag(t.SecondaryText) + "<" + tview.Escape(url) + ">[-]")
+		case strings.HasPrefix(token, "**") || strings.HasPrefix(token, "__"):
+			b.WriteString("[::b]" + tview.Escape(token[2:len(token)-2]) + "[::-]")
+		default:
+			b.WriteString("[::i]" + tview.Escape(token[1:len(token)-1]) + "[::-]")
+		}
+	}
+	b.WriteString(tview.Escape(line[last:]))
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	th := builtinThemes[0]
+	cases := []struct {
+		in, want string
+	}{
+		{"# Title", "[" + th.Title + "::bu]Title[-::-]"},
+		{"## Sub ##", "[" + th.Title + "::b]Sub[-::-]"},
+		{"- [ ] open", "☐ open"},
+		{"  * [x] done", "  ☑ done"},
+		{"- item", "• item"},
+		{"2. second", "2. second"},
+		{"a **bold** and *it*", "a [::b]bold[::-] and [::i]it[::-]"},
+		{"see [docs](http://x.org)", "see [::u]docs[::-] " + tag(th.SecondaryText) + "<http://x.org>[-]"},
+		{"use `go [test]`", "use " + tag(th.SecondaryText) + "go [test[][-]"},
+		{"---", tag(th.Border) + strings.Repeat("─", 20) + "[-]"},
+		{"plain [red]", "plain [red[]"},
+	}
+	for _, c := range cases {
+		if got := renderMarkdown(c.in, th); got != c.want {
+			t.Errorf("renderMarkdown(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+
+	got := renderMarkdown("```\n# not a heading\n```\nafter", th)
+	want := tag(th.SecondaryText) + "  # not a heading[-]\nafter"
+	if got != want {
+		t.Errorf("code block rendered as %q, want %q", got, want)
+	}
+}
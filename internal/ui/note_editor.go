@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var noteCheckbox = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]`)
+
+// NoteEditor is the built-in editor for the notes of tasks. Besides the keys
+// of the text area (Ctrl-Z undo, Ctrl-Y redo), Ctrl-T toggles the Markdown
+// checkbox of the current line, Ctrl-S saves and Escape cancels.
+type NoteEditor struct {
+	*tview.TextArea
+	frame    *tview.Frame
+	original string
+	done     func(text string, success bool)
+}
+
+func (m *NoteEditor) GetFrame() *tview.Frame {
+	return m.frame
+}
+
+func NewNoteEditor(title string) *NoteEditor {
+	area := tview.NewTextArea().SetWrap(true).SetWordWrap(true)
+	m := &NoteEditor{TextArea: area, frame: tview.NewFrame(area)}
+	m.SetHelp("Ctrl-S save, Esc cancel, Ctrl-Z undo, Ctrl-Y redo, Ctrl-T toggle checkbox")
+	m.frame.SetTitle(fmt.Sprintf(" %v ", title))
+	m.frame.SetBorders(0, 0, 0, 1, 0, 0).
+		SetBorder(true).
+		SetBackgroundColor(tview.Styles.ContrastBackgroundColor).
+		SetBorderPadding(0, 0, 1, 1)
+	return m
+}
+
+// SetHelp sets the line below the text describing the keys.
+func (m *NoteEditor) SetHelp(text string) *NoteEditor {
+	m.frame.Clear()
+	m.frame.AddText(text, false, tview.AlignCenter, tcell.ColorDarkGray)
+	return m
+}
+
+// SetNote shows the text of a note, which is the reference for Modified.
+func (m *NoteEditor) SetNote(text string) *NoteEditor {
+	m.original = text
+	m.SetText(text, false)
+	return m
+}
+
+// Modified returns whether the note was changed in the editor.
+func (m *NoteEditor) Modified() bool {
+	return m.GetText() != m.original
+}
+
+func (m *NoteEditor) SetDoneFunc(handler func(text string, success bool)) {
+	m.done = handler
+}
+
+// ToggleCheckbox checks or unchecks the Markdown checkbox ("- [ ]") of the
+// line of the cursor.
+func (m *NoteEditor) ToggleCheckbox() {
+	text := m.GetText()
+	_, pos, _ := m.GetSelection()
+	start := strings.LastIndex(text[:pos], "\n") + 1
+	end := strings.Index(text[start:], "\n")
+	if end < 0 {
+		end = len(text)
+	} else {
+		end += start
+	}
+	loc := noteCheckbox.FindStringSubmatchIndex(text[start:end])
+	if loc == nil {
+		return
+	}
+	mark := "x"
+	if text[start+loc[2]:start+loc[3]] != " " {
+		mark = " "
+	}
+	m.Replace(start+loc[2], start+loc[3], mark)
+	m.Select(pos, pos)
+}
+
+// Draw draws the editor in a frame covering most of the screen.
+func (m *NoteEditor) Draw(screen tcell.Screen) {
+	screenWidth, screenHeight := screen.Size()
+	width, height := screenWidth*4/5, screenHeight*4/5
+	if width < 40 {
+		width = screenWidth
+	}
+	if height < 10 {
+		height = screenHeight
+	}
+	x := (screenWidth - width) / 2
+	y := (screenHeight - height) / 2
+	m.frame.SetRect(x, y, width, height)
+	m.frame.Draw(screen)
+}
+
+func (m *NoteEditor) Focus(delegate func(p tview.Primitive)) {
+	delegate(m.TextArea)
+}
+
+func (m *NoteEditor) HasFocus() bool {
+	return m.TextArea.HasFocus()
+}
+
+// InputHandler handles the keys of the editor, other keys are passed to the
+// text area.
+func (m *NoteEditor) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyCtrlS:
+			if m.done != nil {
+				m.done(m.GetText(), true)
+			}
+		case tcell.KeyEscape:
+			if m.done != nil {
+				m.done(m.GetText(), false)
+			}
+		case tcell.KeyCtrlT:
+			m.ToggleCheckbox()
+		default:
+			m.TextArea.InputHandler()(event, setFocus)
+		}
+	}
+}
+
+// MouseHandler passes mouse events to the text area.
+func (m *NoteEditor) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return m.TextArea.MouseHandler()
+}
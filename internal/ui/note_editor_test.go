@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestNoteEditorToggleCheckbox(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("screen init failed: %v", err)
+	}
+	screen.SetSize(80, 25)
+	m := NewNoteEditor("Note")
+	m.SetNote("# List\n- [ ] one\n  * [x] two")
+	m.Draw(screen)
+	if m.Modified() {
+		t.Fatalf("new note reported as modified")
+	}
+	m.Select(12, 12) // in line "- [ ] one"
+	m.ToggleCheckbox()
+	m.Select(len(m.GetText()), len(m.GetText()))
+	m.ToggleCheckbox()
+	if got := m.GetText(); got != "# List\n- [x] one\n  * [ ] two" {
+		t.Fatalf("unexpected text %q", got)
+	}
+	m.Select(0, 0)
+	m.ToggleCheckbox() // no checkbox in the heading
+	if !m.Modified() || m.GetText() != "# List\n- [x] one\n  * [ ] two" {
+		t.Fatalf("unexpected text %q", m.GetText())
+	}
+}
+
+func TestBuiltinNoteEditorConflict(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	c.Items[0][0].Note = "a\nb"
+	c.SetFileName(t.TempDir()+"/todo.json", "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.settings.NoteEditor = "builtin"
+	l.RedrawAllLanes()
+	save := tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl)
+
+	l.CmdEditNote()
+	editor, ok := l.activeDialog.(*NoteEditor)
+	if !ok {
+		t.Fatalf("built-in editor not shown")
+	}
+	editor.SetText("a\nb\nc", true)
+	editor.InputHandler()(save, func(tview.Primitive) {})
+	if c.Items[0][0].Note != "a\nb\nc" || l.dialogActive {
+		t.Fatalf("note not saved: %q", c.Items[0][0].Note)
+	}
+
+	l.CmdEditNote()
+	editor = l.activeDialog.(*NoteEditor)
+	editor.SetText("A\nb\nc", true)
+	c.Items[0][0].Note = "a\nb\nc\nd" // changed by another instance
+	editor.InputHandler()(save, func(tview.Primitive) {})
+	if name, _ := l.pages.GetFrontPage(); name != "noteConflict" {
+		t.Fatalf("conflict not detected, front page %q", name)
+	}
+	if c.Items[0][0].Note != "a\nb\nc\nd" {
+		t.Fatalf("note of the other instance overwritten: %q", c.Items[0][0].Note)
+	}
+}
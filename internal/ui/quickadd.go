@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cklukas/todo/internal/quickadd"
+)
+
+// describeQuickAdd returns what will be set besides the title, e.g.
+// "priority 1, #bug, due 24.10.2025, lane Doing", followed by the errors.
+func describeQuickAdd(q quickadd.Task, lanes []string) string {
+	var parts []string
+	if q.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("priority %v", q.Priority))
+	}
+	for _, t := range q.Tags {
+		parts = append(parts, "#"+t)
+	}
+	if q.Due != "" {
+		parts = append(parts, "due "+isoToLocal(q.Due))
+	}
+	if q.Color != "" {
+		parts = append(parts, "color "+q.Color)
+	}
+	if q.Lane >= 0 && q.Lane < len(lanes) {
+		parts = append(parts, "lane "+strings.TrimSpace(lanes[q.Lane]))
+	}
+	if q.Epic != "" {
+		parts = append(parts, "epic "+q.Epic)
+	}
+	for _, link := range q.Links {
+		parts = append(parts, link.Type+" "+link.Ref)
+	}
+	return strings.Join(append(parts, q.Errors...), ", ")
+}
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cklukas/todo/internal/quickadd"
+)
+
+func TestDescribeQuickAdd(t *testing.T) {
+	SetDateFormat("dd.mm.yyyy")
+	defer SetDateFormat("")
+	lanes := []string{"To Do", "Doing", "Done", "In Progress"}
+	now := time.Date(2024, 5, 8, 12, 0, 0, 0, time.Local) // a Wednesday
+
+	q := quickadd.Parse("Fix  login !1 #bug #ui #Bug @due:fri color:Red >Doing", lanes, dateFormat, now)
+	if got := describeQuickAdd(q, lanes); got != "priority 1, #bug, #ui, due 10.05.2024, color red, lane Doing" {
+		t.Fatalf("description %q", got)
+	}
+	q = quickadd.Parse("Write tests epic:#42 Blocks:7 blockedby:8 relates:abcd-1234 >x", lanes, dateFormat, now)
+	if got := describeQuickAdd(q, lanes); got != "epic #42, blocks 7, blocked-by 8, relates-to abcd-1234, lane 'x' not found" {
+		t.Fatalf("description %q", got)
+	}
+}
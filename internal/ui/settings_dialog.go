@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/config"
+)
+
+// SettingsModal shows the user settings in a form. Only changed values are
+// reported to the done handler, using the keys of the settings file.
+type SettingsModal struct {
+	*tview.Form
+	DialogHeight int
+	frame        *tview.Frame
+	initial      map[string]string
+	values       map[string]string
+	done         func(values map[string]string, modeOnly, success bool)
+}
+
+func (m *SettingsModal) GetFrame() *tview.Frame {
+	return m.frame
+}
+
+// settingsValues returns the settings as shown in the settings dialog.
+func settingsValues(s config.Settings) map[string]string {
+	values := map[string]string{
+		"editor":          s.Editor,
+		"noteEditor":      s.NoteEditor,
+		"dateFormat":      s.DateFormat,
+		"clock":           strconv.FormatBool(s.ClockEnabled()),
+		"clockFormat":     s.ClockFormat,
+		"defaultPriority": strconv.Itoa(s.Priority()),
+		"defaultLane":     s.DefaultLane,
+		"confirmDelete":   strconv.FormatBool(s.ConfirmDeleteEnabled()),
+		"backupDays":      strconv.Itoa(s.BackupDays),
+		"theme":           s.Theme,
+		"detailPane":      strconv.FormatBool(s.DetailPane),
+		"detailPosition":  s.DetailPaneAt(),
+		"pomodoroWork":    strconv.Itoa(int(s.PomodoroWorkTime().Minutes())),
+		"pomodoroBreak":   strconv.Itoa(int(s.PomodoroBreakTime().Minutes())),
+		"pomodoroLane":    s.PomodoroLane,
+		"pomodoroCommand": s.PomodoroCommand,
+		"estimateUnit":    s.EstimateUnit,
+		"showNumbers":     strconv.FormatBool(s.ShowNumbers),
+		"keymap":          s.Keymap.Preset,
+	}
+	if values["clockFormat"] == "" {
+		values["clockFormat"] = config.ClockFormats[0]
+	}
+	if values["noteEditor"] == "" {
+		values["noteEditor"] = config.NoteEditors[0]
+	}
+	if values["theme"] == "" {
+		values["theme"] = DefaultThemeName
+	}
+	if values["keymap"] == "" {
+		values["keymap"] = "default"
+	}
+	return values
+}
+
+// NewSettingsModal creates the settings dialog. The lane titles and theme
+// names are offered as choices. If mode is not empty, the settings can also
+// be saved for this mode only.
+func NewSettingsModal(s config.Settings, lanes, themes []string, mode string) *SettingsModal {
+	form := tview.NewForm()
+	m := &SettingsModal{Form: form, DialogHeight: 16, frame: tview.NewFrame(form), initial: settingsValues(s), values: settingsValues(s), done: nil}
+
+	form.SetCancelFunc(func() {
+		if m.done != nil {
+			m.done(nil, false, false)
+		}
+	})
+
+	dropDown := func(label, key string, options []string, texts []string) {
+		idx := 0
+		for i, o := range options {
+			if o == m.values[key] {
+				idx = i
+			}
+		}
+		if texts == nil {
+			texts = options
+		}
+		form.AddDropDown(label, texts, idx, func(_ string, index int) {
+			if index >= 0 {
+				m.values[key] = options[index]
+			}
+		})
+	}
+	checkbox := func(label, key string) {
+		form.AddCheckbox(label, m.values[key] == "true", func(checked bool) {
+			m.values[key] = strconv.FormatBool(checked)
+		})
+	}
+
+	form.AddInputField("Editor:", m.values["editor"], 30, nil, func(text string) {
+		m.values["editor"] = text
+	})
+	dropDown("Note editor:", "noteEditor", config.NoteEditors, nil)
+	dropDown("Date format:", "dateFormat", append([]string{""}, config.DateFormats...),
+		append([]string{"system"}, config.DateFormats...))
+	checkbox("Clock:", "clock")
+	dropDown("Clock format:", "clockFormat", config.ClockFormats, nil)
+	dropDown("Priority:", "defaultPriority", []string{"1", "2", "3", "4"},
+		[]string{"1 (high)", "2 (normal)", "3 (low)", "4 (idle)"})
+	dropDown("Start lane:", "defaultLane", append([]string{""}, lanes...),
+		append([]string{"first lane"}, lanes...))
+	checkbox("Confirm delete:", "confirmDelete")
+	form.AddInputField("Backup days:", m.values["backupDays"], 5, tview.InputFieldInteger, func(text string) {
+		m.values["backupDays"] = text
+	})
+	dropDown("Theme:", "theme", themes, nil)
+	checkbox("Detail pane:", "detailPane")
+	dropDown("Detail pane at:", "detailPosition", config.DetailPositions, nil)
+	form.AddInputField("Pomodoro minutes:", m.values["pomodoroWork"], 5, tview.InputFieldInteger, func(text string) {
+		m.values["pomodoroWork"] = text
+	})
+	form.AddInputField("Break minutes:", m.values["pomodoroBreak"], 5, tview.InputFieldInteger, func(text string) {
+		m.values["pomodoroBreak"] = text
+	})
+	dropDown("Pomodoro lane:", "pomodoroLane", append([]string{""}, lanes...),
+		append([]string{"keep lane"}, lanes...))
+	form.AddInputField("Pomodoro notify:", m.values["pomodoroCommand"], 30, nil, func(text string) {
+		m.values["pomodoroCommand"] = text
+	})
+	form.AddInputField("Estimate unit:", m.values["estimateUnit"], 5, nil, func(text string) {
+		m.values["estimateUnit"] = text
+	})
+	checkbox("Task numbers:", "showNumbers")
+	dropDown("Keys:", "keymap", Presets(), nil)
+
+	m.SetButtonsAlign(tview.AlignCenter).
+		SetButtonBackgroundColor(tview.Styles.PrimitiveBackgroundColor).
+		SetButtonTextColor(tview.Styles.PrimaryTextColor).
+		SetBackgroundColor(tview.Styles.ContrastBackgroundColor).
+		SetBorderPadding(0, 0, 0, 0)
+
+	m.AddButton("Save", func() {
+		m.finish(s, false)
+	})
+	if mode != "" {
+		m.AddButton("Save for mode", func() {
+			m.finish(s, true)
+		})
+	}
+	m.AddButton("Cancel", func() {
+		if m.done != nil {
+			m.done(nil, false, false)
+		}
+	})
+
+	m.frame.SetTitle(" Settings ")
+	m.frame.SetBorders(0, 0, 1, 0, 0, 0).
+		SetBorder(true).
+		SetBackgroundColor(tview.Styles.ContrastBackgroundColor).
+		SetBorderPadding(1, 1, 1, 1)
+
+	return m
+}
+
+// finish reports the changed values to the done handler.
+func (m *SettingsModal) finish(s config.Settings, modeOnly bool) {
+	if m.done == nil {
+		return
+	}
+	changed := make(map[string]string)
+	for key, value := range m.values {
+		if value == m.initial[key] {
+			continue
+		}
+		if key == "backupDays" && value == "" {
+			value = "0"
+		}
+		if key == "keymap" {
+			km := s.Keymap
+			km.Preset = value
+			data, err := json.Marshal(km)
+			if err != nil {
+				continue
+			}
+			value = string(data)
+		}
+		changed[key] = value
+	}
+	m.done(changed, modeOnly, true)
+}
+
+func (m *SettingsModal) SetDoneFunc(handler func(values map[string]string, modeOnly, success bool)) {
+	m.done = handler
+}
+
+// Draw draws this modal with a surrounding frame.
+func (m *SettingsModal) Draw(screen tcell.Screen) {
+	minWidth := 56
+	screenWidth, screenHeight := screen.Size()
+	width := screenWidth / 3
+	if width < minWidth {
+		width = minWidth
+	}
+	height := m.DialogHeight
+	width += 4
+	x := (screenWidth - width) / 2
+	y := (screenHeight - height) / 2
+	m.SetRect(x, y, width, height)
+	m.frame.SetRect(x, y, width, height)
+	m.frame.Draw(screen)
+}
+
+func (m *SettingsModal) Focus(delegate func(p tview.Primitive)) {
+	delegate(m.Form)
+}
+
+func (m *SettingsModal) HasFocus() bool {
+	return m.Form.HasFocus()
+}
+
+func (m *SettingsModal) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+	return m.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+		consumed, capture := m.Form.MouseHandler()(action, event, setFocus)
+		if !consumed && action == tview.MouseLeftDown && m.InRect(event.Position()) {
+			setFocus(m)
+			consumed = true
+		}
+		return consumed, capture
+	})
+}
+
+func (m *SettingsModal) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return m.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if m.frame.HasFocus() {
+			if handler := m.frame.InputHandler(); handler != nil {
+				handler(event, setFocus)
+			}
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+	"github.com/rivo/tview"
+)
+
+func TestSettingsModalReportsChangedValues(t *testing.T) {
+	off := false
+	s := config.Settings{Clock: &off, Keymap: config.KeymapSettings{Bindings: map[string]config.KeyList{ActionEdit: {"x"}}}}
+	dlg := NewSettingsModal(s, []string{"To Do", "Done"}, []string{"default", "dark"}, "work")
+	if dlg.GetButtonIndex("Save for mode") == -1 {
+		t.Fatalf("mode button missing")
+	}
+
+	var got map[string]string
+	var gotModeOnly bool
+	dlg.SetDoneFunc(func(values map[string]string, modeOnly, success bool) {
+		got, gotModeOnly = values, modeOnly
+	})
+	dlg.GetFormItemByLabel("Theme:").(*tview.DropDown).SetCurrentOption(1)
+	dlg.GetFormItemByLabel("Keys:").(*tview.DropDown).SetCurrentOption(1)
+	dlg.finish(s, true)
+
+	if !gotModeOnly {
+		t.Fatalf("mode only not reported")
+	}
+	if len(got) != 2 || got["theme"] != "dark" || got["keymap"] != `{"preset":"vim","bindings":{"edit":["x"]}}` {
+		t.Fatalf("unexpected changes %#v", got)
+	}
+}
+
+func TestApplySettingsWithoutDeleteConfirmation(t *testing.T) {
+	dir := t.TempDir()
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.SetFileName(filepath.Join(dir, "todo.json"), dir, dir)
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	off := false
+	if err := l.ApplySettings(config.Settings{ConfirmDelete: &off, Theme: "unknown"}); err == nil {
+		t.Fatalf("expected error for unknown theme")
+	}
+	if l.Theme().Name != DefaultThemeName {
+		t.Fatalf("default theme not used, got %s", l.Theme().Name)
+	}
+
+	l.CmdDeleteTask()
+	if len(c.Items[0]) != 0 {
+		t.Fatalf("task not deleted")
+	}
+	if name, _ := l.pages.GetFrontPage(); name == "delete" {
+		t.Fatalf("confirmation shown")
+	}
+}
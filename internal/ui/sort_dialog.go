@@ -26,7 +26,7 @@ func (m *SortModal) GetFrame() *tview.Frame {
 func NewSortModal(title, lane string, current string) *SortModal {
 	form := tview.NewForm()
 	m := &SortModal{Form: form, DialogHeight: 9, frame: tview.NewFrame(form), optionIndex: 0,
-		options: []string{"", model.SortColor, model.SortDue, model.SortCreated, model.SortModified, model.SortPriority}, done: nil}
+		options: []string{"", model.SortColor, model.SortDue, model.SortCreated, model.SortModified, model.SortPriority, model.SortEstimate}, done: nil}
 
 	form.SetCancelFunc(func() {
 		if m.done != nil {
@@ -34,7 +34,7 @@ func NewSortModal(title, lane string, current string) *SortModal {
 		}
 	})
 
-	labels := []string{"manual", "color", "due", "created", "modified", "priority"}
+	labels := []string{"manual", "color", "due", "created", "modified", "priority", "estimate"}
 	idx := 0
 	for i, v := range m.options {
 		if v == current {
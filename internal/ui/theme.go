@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme defines the colors of the user interface. Colors are given as names
+// ("lightgray") or as hex values ("#268bd2").
+type Theme struct {
+	Name string `json:"-"`
+	// Base is the name of the theme providing all colors not set in a user
+	// theme file.
+	Base string `json:"base,omitempty"`
+
+	Background    string `json:"background,omitempty"`
+	Text          string `json:"text,omitempty"`
+	SecondaryText string `json:"secondaryText,omitempty"`
+	Border        string `json:"border,omitempty"`
+	Title         string `json:"title,omitempty"`
+
+	Selection     string `json:"selection,omitempty"`
+	SelectionText string `json:"selectionText,omitempty"`
+	Marked        string `json:"marked,omitempty"`
+	MarkedText    string `json:"markedText,omitempty"`
+	// ItemContrast is used as background for items whose color equals the
+	// lane color, ItemContrastAlt if the lane has the ItemContrast color.
+	ItemContrast    string `json:"itemContrast,omitempty"`
+	ItemContrastAlt string `json:"itemContrastAlt,omitempty"`
+
+	StatusBar     string `json:"statusBar,omitempty"`
+	StatusText    string `json:"statusText,omitempty"`
+	StatusKey     string `json:"statusKey,omitempty"`
+	StatusMainKey string `json:"statusMainKey,omitempty"`
+	StatusMode    string `json:"statusMode,omitempty"`
+
+	Dialog      string `json:"dialog,omitempty"`
+	DialogText  string `json:"dialogText,omitempty"`
+	DialogLabel string `json:"dialogLabel,omitempty"`
+
+	Due     string `json:"due,omitempty"`
+	Overdue string `json:"overdue,omitempty"`
+}
+
+// DefaultThemeName is the theme used if none is configured.
+const DefaultThemeName = "default"
+
+var builtinThemes = []*Theme{
+	{
+		Name:       DefaultThemeName,
+		Background: "black", Text: "white", SecondaryText: "green", Border: "white", Title: "white",
+		Selection: "lightblue", SelectionText: "black", Marked: "navy", MarkedText: "white",
+		ItemContrast: "white", ItemContrastAlt: "black",
+		StatusBar: "lightgray", StatusText: "black", StatusKey: "red", StatusMainKey: "brown", StatusMode: "blue",
+		Dialog: "blue", DialogText: "white", DialogLabel: "yellow",
+		Due: "orange", Overdue: "red",
+	},
+	{
+		Name:       "light",
+		Background: "white", Text: "black", SecondaryText: "darkslategray", Border: "gray", Title: "black",
+		Selection: "lightblue", SelectionText: "black", Marked: "navy", MarkedText: "white",
+		ItemContrast: "black", ItemContrastAlt: "white",
+		StatusBar: "lightgray", StatusText: "black", StatusKey: "darkred", StatusMainKey: "brown", StatusMode: "blue",
+		Dialog: "lightsteelblue", DialogText: "black", DialogLabel: "darkblue",
+		Due: "darkorange", Overdue: "red",
+	},
+	{
+		Name:       "dark",
+		Background: "#1c1c1c", Text: "#d0d0d0", SecondaryText: "#8a8a8a", Border: "#585858", Title: "#d0d0d0",
+		Selection: "#5f87af", SelectionText: "black", Marked: "#875f87", MarkedText: "white",
+		ItemContrast: "#d0d0d0", ItemContrastAlt: "#1c1c1c",
+		StatusBar: "#303030", StatusText: "#d0d0d0", StatusKey: "#d7875f", StatusMainKey: "#d7af5f", StatusMode: "#87afd7",
+		Dialog: "#3a3a3a", DialogText: "#d0d0d0", DialogLabel: "#d7af5f",
+		Due: "#d7af5f", Overdue: "#d75f5f",
+	},
+	{
+		Name:       "solarized",
+		Background: "#002b36", Text: "#839496", SecondaryText: "#586e75", Border: "#586e75", Title: "#93a1a1",
+		Selection: "#268bd2", SelectionText: "#fdf6e3", Marked: "#6c71c4", MarkedText: "#fdf6e3",
+		ItemContrast: "#fdf6e3", ItemContrastAlt: "#002b36",
+		StatusBar: "#073642", StatusText: "#93a1a1", StatusKey: "#cb4b16", StatusMainKey: "#b58900", StatusMode: "#2aa198",
+		Dialog: "#073642", DialogText: "#93a1a1", DialogLabel: "#b58900",
+		Due: "#b58900", Overdue: "#dc322f",
+	},
+	{
+		Name:       "high-contrast",
+		Background: "black", Text: "white", SecondaryText: "aqua", Border: "white", Title: "yellow",
+		Selection: "yellow", SelectionText: "black", Marked: "fuchsia", MarkedText: "black",
+		ItemContrast: "white", ItemContrastAlt: "black",
+		StatusBar: "white", StatusText: "black", StatusKey: "red", StatusMainKey: "blue", StatusMode: "green",
+		Dialog: "black", DialogText: "white", DialogLabel: "yellow",
+		Due: "yellow", Overdue: "red",
+	},
+}
+
+// color returns the tcell color for a theme color value.
+func color(value string) tcell.Color {
+	return tcell.GetColor(value)
+}
+
+// tag returns a tview color tag for the given foreground color.
+func tag(value string) string {
+	return "[" + value + "::-]"
+}
+
+// BuiltinThemes returns the themes shipped with the application.
+func BuiltinThemes() []*Theme {
+	res := make([]*Theme, len(builtinThemes))
+	copy(res, builtinThemes)
+	return res
+}
+
+// DefaultTheme returns the theme used if none is configured.
+func DefaultTheme() *Theme {
+	return builtinThemes[0]
+}
+
+// LoadThemes returns the built-in themes followed by the user themes stored
+// as JSON files in dir. The file name without extension is the theme name.
+// Colors not given in a file are taken from the theme named in "base", or
+// from the default theme.
+func LoadThemes(dir string) ([]*Theme, error) {
+	themes := BuiltinThemes()
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return themes, err
+	}
+	sort.Strings(files)
+	var errs []string
+	for _, fname := range files {
+		t, err := loadTheme(fname, themes)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		themes = append(themes, t)
+	}
+	if len(errs) > 0 {
+		return themes, errors.New(strings.Join(errs, "; "))
+	}
+	return themes, nil
+}
+
+func loadTheme(fname string, known []*Theme) (*Theme, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	t := new(Theme)
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("theme '%v': %w", fname, err)
+	}
+	t.Name = strings.TrimSuffix(filepath.Base(fname), filepath.Ext(fname))
+
+	base := DefaultTheme()
+	if t.Base != "" {
+		if base = findTheme(known, t.Base); base == nil {
+			return nil, fmt.Errorf("theme '%v': unknown base theme '%v'", fname, t.Base)
+		}
+	}
+	t.inherit(base)
+	return t, nil
+}
+
+// inherit fills all colors not set in t from base.
+func (t *Theme) inherit(base *Theme) {
+	fields := func(th *Theme) []*string {
+		return []*string{&th.Background, &th.Text, &th.SecondaryText, &th.Border, &th.Title,
+			&th.Selection, &th.SelectionText, &th.Marked, &th.MarkedText, &th.ItemContrast, &th.ItemContrastAlt,
+			&th.StatusBar, &th.StatusText, &th.StatusKey, &th.StatusMainKey, &th.StatusMode,
+			&th.Dialog, &th.DialogText, &th.DialogLabel, &th.Due, &th.Overdue}
+	}
+	own := fields(t)
+	inherited := fields(base)
+	for i := range own {
+		if *own[i] == "" {
+			*own[i] = *inherited[i]
+		}
+	}
+}
+
+func findTheme(themes []*Theme, name string) *Theme {
+	for _, t := range themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// applyStyles sets the tview default styles, which are used for all
+// primitives created afterwards, e.g. dialogs.
+func (t *Theme) applyStyles() {
+	tview.Styles.PrimitiveBackgroundColor = color(t.Background)
+	tview.Styles.ContrastBackgroundColor = color(t.Dialog)
+	tview.Styles.PrimaryTextColor = color(t.Text)
+	tview.Styles.SecondaryTextColor = color(t.DialogLabel)
+	tview.Styles.TertiaryTextColor = color(t.SecondaryText)
+	tview.Styles.BorderColor = color(t.Border)
+	tview.Styles.TitleColor = color(t.Title)
+}
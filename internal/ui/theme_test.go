@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestLoadThemes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mine.json"), []byte(`{"base": "solarized", "selection": "#ff0000"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"base": "nope"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	themes, err := LoadThemes(dir)
+	if err == nil {
+		t.Fatalf("expected error for unknown base theme")
+	}
+	mine := findTheme(themes, "mine")
+	if mine == nil {
+		t.Fatalf("user theme not loaded")
+	}
+	if mine.Selection != "#ff0000" {
+		t.Fatalf("selection %s, want #ff0000", mine.Selection)
+	}
+	if mine.Background != "#002b36" {
+		t.Fatalf("background %s not inherited from base theme", mine.Background)
+	}
+	if findTheme(themes, "broken") != nil {
+		t.Fatalf("broken theme should be skipped")
+	}
+	for _, name := range []string{"light", "dark", "solarized", "high-contrast"} {
+		if findTheme(themes, name) == nil {
+			t.Fatalf("built-in theme %s missing", name)
+		}
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	defer l.ApplyTheme(DefaultThemeName)
+
+	var applied string
+	l.AddThemeFunc(func(th *Theme) { applied = th.Name })
+	if applied != DefaultThemeName {
+		t.Fatalf("theme func not called with active theme, got %q", applied)
+	}
+
+	if err := l.ApplyTheme("unknown"); err == nil {
+		t.Fatalf("expected error for unknown theme")
+	}
+	if err := l.ApplyTheme("solarized"); err != nil {
+		t.Fatal(err)
+	}
+	if applied != "solarized" {
+		t.Fatalf("theme func not called on change, got %q", applied)
+	}
+	if bg := l.lanes[1].GetBackgroundColor(); bg != tcell.GetColor("#002b36") {
+		t.Fatalf("lane background %v not themed", bg)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"github.com/cklukas/todo/internal/config"
 	"github.com/cklukas/todo/internal/model"
 	"github.com/cklukas/todo/internal/util"
 )
@@ -17,12 +18,23 @@ type dialogWithFrame interface {
 }
 
 type Lanes struct {
-	nextMode        string
-	nextLaneFocus   int
 	todoDirModes    string
+	modeLister      func() ([]string, error)
+	modeStore       *model.ModeStore
+	modeOpener      func(mode string) (*model.ToDoContent, error)
+	modeFuncs       []func(mode string, content *model.ToDoContent) error
+	modeStates      map[string]modeState
+	modeClosed      bool
 	mode            string
 	appVersion      string
+	releaseNote     string
 	content         *model.ToDoContent
+	flex            *tview.Flex
+	body            *tview.Flex
+	detail          *tview.TextView
+	detailShown     bool
+	detailAt        string
+	detailGuid      string
 	lanes           []*tview.List
 	active          int
 	lastActive      int
@@ -30,12 +42,40 @@ type Lanes struct {
 	pages           *tview.Pages
 	app             *tview.Application
 	inselect        bool
+	marked          map[string]bool
 	add             *ModalInput
 	edit            *ModalInput
 	addMode         *ModalInput
+	addBelow        bool
+	help            *tview.Modal
+	keymap          *Keymap
+	pendingKeys     []string
+	theme           *Theme
+	themes          []*Theme
+	templates       []*model.BoardTemplate
+	themeFuncs      []func(t *Theme)
+	themeChanged    func(name string)
+	modals          []*tview.Modal
+	settings        config.Settings
+	settingsChanged func(values map[string]string, modeOnly bool) error
 
 	bMoveHelp *tview.Button
-	clock     *tview.TextView
+	// userName is the user of the timers
+	userName string
+	clock    *tview.TextView
+	pomodoro *pomodoro
+	// filter limits the lanes to the tasks assigned to the user, to the
+	// waiting tasks, to the epic with the GUID epic or to the tasks matching
+	// the text search, shown holds the indexes of the tasks listed per lane
+	// while filtered
+	filter string
+	epic   string
+	search string
+	shown  [][]int
+	// followUpCheck is the last check for waiting tasks to follow up,
+	// reminded holds the follow-up dates already shown per task
+	followUpCheck time.Time
+	reminded      map[string]string
 
 	dialogActive     bool
 	activeDialog     dialogWithFrame
@@ -72,28 +112,95 @@ func (l *Lanes) redrawLane(laneIndex, active int) error {
 	l.content.SortLane(laneIndex)
 	l.lanes[laneIndex].Clear()
 	now := time.Now()
-	laneBg := tview.Styles.PrimitiveBackgroundColor
-	if col := l.content.GetLaneColor(laneIndex); col != "" {
-		laneBg = tcell.GetColor(col)
+	laneBg := l.laneBackground(laneIndex)
+	blocked := l.content.BlockedItems()
+	epics := l.content.EpicProgress()
+
+	if len(l.shown) != len(l.lanes) {
+		l.shown = make([][]int, len(l.lanes))
+	}
+	l.shown[laneIndex] = nil
+	if l.filter != "" {
+		l.shown[laneIndex] = []int{}
 	}
 
-	for _, item := range l.content.GetLaneItems(laneIndex) {
+	for i, item := range l.content.GetLaneItems(laneIndex) {
+		if !l.visible(&item) {
+			continue
+		}
+		if l.filter != "" {
+			l.shown[laneIndex] = append(l.shown[laneIndex], i)
+		}
 		title := item.Title
 		if item.Color != "" {
 			if tcell.GetColor(item.Color) == laneBg {
-				altBg := "white"
-				if laneBg == tcell.ColorWhite {
-					altBg = "black"
+				altBg := l.theme.ItemContrast
+				if laneBg == color(altBg) {
+					altBg = l.theme.ItemContrastAlt
 				}
 				title = "[" + item.Color + ":" + altBg + "]" + title
 			} else {
 				title = "[" + item.Color + "]" + title
 			}
 		}
+		if l.settings.ShowNumbers && item.Number > 0 {
+			title = tag(l.theme.SecondaryText) + item.Ref() + "[-] " + title
+		}
 		if suffix := dueSuffix(item.Due, now); suffix != "" {
-			title += " " + suffix
+			title += " " + tag(l.dueColor(suffix)) + tview.Escape(suffix)
+		}
+		if item.FollowUpDue(now) {
+			title += " " + tag(l.theme.Due) + tview.Escape("[follow up]")
+		}
+		if item.IsWaiting() {
+			title = tag(l.theme.Due) + "⌛[-] " + title
+		}
+		if item.RunningEntry(l.userName) >= 0 {
+			title = tag(l.theme.StatusMode) + "◷[-] " + title
+		}
+		if blocked[item.Guid] {
+			title = tag(l.theme.Overdue) + "⛓[-] " + title
+		}
+		if l.marked[item.Guid] {
+			title = tag(l.theme.Title) + "✓[-] " + title
 		}
 		secondary := item.Secondary
+		for _, t := range item.Tags {
+			if len(secondary) > 0 {
+				secondary += " "
+			}
+			secondary += tview.Escape("#" + t)
+		}
+		if item.IsWaiting() {
+			if len(secondary) > 0 {
+				secondary += " "
+			}
+			secondary += tview.Escape("waiting on " + item.Waiting.On)
+		}
+		if p, ok := epics[item.Guid]; ok {
+			if len(secondary) > 0 {
+				secondary += " "
+			}
+			secondary += fmt.Sprintf("◆ %d/%d done", p.Done, p.Total)
+		}
+		if item.Estimate > 0 {
+			if len(secondary) > 0 {
+				secondary += " "
+			}
+			secondary += tview.Escape(model.FormatEstimate(item.Estimate, l.content.EstimateUnit()))
+		}
+		if n := len(item.Comments); n > 0 {
+			if len(secondary) > 0 {
+				secondary += " "
+			}
+			secondary += fmt.Sprintf("✉%d", n)
+		}
+		if initials := assigneeText(&item); initials != "" {
+			if len(secondary) > 0 {
+				secondary += " "
+			}
+			secondary += initials
+		}
 		if mark := model.PriorityMark(item.Priority); mark != "" {
 			if len(secondary) > 0 {
 				secondary += " "
@@ -113,39 +220,51 @@ func (l *Lanes) redrawLane(laneIndex, active int) error {
 				}
 			}
 		}
-		l.lanes[laneIndex].SetCurrentItem(util.NormPos(active, num))
+		l.lanes[laneIndex].SetCurrentItem(util.NormPos(l.listPos(laneIndex, active), num))
 	}
 
-	l.lanes[laneIndex].SetTitle(l.content.GetLaneTitle(laneIndex))
-	if col := l.content.GetLaneColor(laneIndex); col != "" {
-		l.lanes[laneIndex].SetBackgroundColor(tcell.GetColor(col))
-	} else {
-		l.lanes[laneIndex].SetBackgroundColor(tview.Styles.PrimitiveBackgroundColor)
+	laneTitle := l.content.GetLaneTitle(laneIndex)
+	if l.filter != "" {
+		laneTitle += " (" + l.filter + ")"
+	}
+	l.lanes[laneIndex].SetTitle(laneTitle)
+	titleColor := l.theme.Title
+	if l.content.OverWipLimit(laneIndex) {
+		titleColor = l.theme.Overdue
+	}
+	l.lanes[laneIndex].SetTitleColor(color(titleColor))
+	l.lanes[laneIndex].SetBackgroundColor(laneBg)
+	if laneIndex == l.active {
+		l.updateDetail()
 	}
 	return nil
 }
 
 func dueSuffix(due string, now time.Time) string {
-	if len(due) == 0 {
+	days, ok := model.DueDays(due, now)
+	if !ok {
 		return ""
 	}
-	d, err := time.Parse("2006-01-02", due)
-	if err != nil {
-		return ""
-	}
-	d = d.In(now.Location())
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	days := int(d.Sub(today).Hours() / 24)
-	switch days {
-	case 0:
+	switch {
+	case days < 0:
+		return "[overdue]"
+	case days == 0:
 		return "[due!]"
-	case 1:
+	case days == 1:
 		return "[tomorrow]"
 	default:
 		return ""
 	}
 }
 
+// dueColor returns the theme color of a due date marker.
+func (l *Lanes) dueColor(suffix string) string {
+	if suffix == "[overdue]" {
+		return l.theme.Overdue
+	}
+	return l.theme.Due
+}
+
 func (l *Lanes) setActive() {
 	l.active = util.NormPos(l.active, len(l.lanes))
 	l.app.SetFocus(l.lanes[l.active])
@@ -164,7 +283,7 @@ func (l *Lanes) saveActive() int {
 }
 
 func (l *Lanes) currentItem() *model.Item {
-	pos := l.lanes[l.active].GetCurrentItem()
+	pos := l.currentIndex(l.active)
 	content := l.content.GetLaneItems(l.active)
 	if pos < 0 || pos >= len(content) {
 		return nil
@@ -229,12 +348,14 @@ func (l *Lanes) ActiveIndex() int {
 	return l.active
 }
 
-func (l *Lanes) NextMode() string {
-	return l.nextMode
+// Mode returns the name of the active mode.
+func (l *Lanes) Mode() string {
+	return l.mode
 }
 
-func (l *Lanes) NextLaneFocus() int {
-	return l.nextLaneFocus
+// Content returns the board of the active mode.
+func (l *Lanes) Content() *model.ToDoContent {
+	return l.content
 }
 
 func (l *Lanes) StartClock() {
@@ -245,19 +366,41 @@ func (l *Lanes) StartClock() {
 	go func() {
 		for now := range ticker.C {
 			l.app.QueueUpdateDraw(func() {
-				box := tview.NewBox()
-				l.app.ResizeToFullScreen(box)
-				_, _, width, _ := box.GetRect()
-				labelWidth := tview.TaggedStringWidth(l.bMoveHelp.GetLabel())
-				available := width - 89 - labelWidth
-				if available >= 19 {
-					l.clock.SetText(now.Format(clockDateLayout() + " 15:04:05"))
-				} else if available >= 8 {
-					l.clock.SetText(now.Format("15:04:05"))
-				} else {
-					l.clock.SetText("")
-				}
+				l.updateTimer(now)
+				l.updatePomodoro(now)
+				l.remindFollowUps(now)
+				l.updateClock(now)
 			})
 		}
 	}()
 }
+
+// updateClock shows the time, as far as there is room, or the countdown of
+// a running pomodoro.
+func (l *Lanes) updateClock(now time.Time) {
+	if l.clock == nil {
+		return
+	}
+	if text := l.pomodoroLabel(now); text != "" {
+		l.clock.SetText(text)
+		return
+	}
+	box := tview.NewBox()
+	l.app.ResizeToFullScreen(box)
+	_, _, width, _ := box.GetRect()
+	labelWidth := tview.TaggedStringWidth(l.bMoveHelp.GetLabel())
+	available := width - 89 - labelWidth
+	timeLayout := "15:04:05"
+	if l.settings.ClockFormat == "12h" {
+		timeLayout = "03:04 PM"
+	}
+	if !l.settings.ClockEnabled() {
+		l.clock.SetText("")
+	} else if available >= 19 {
+		l.clock.SetText(now.Format(clockDateLayout() + " " + timeLayout))
+	} else if available >= 8 {
+		l.clock.SetText(now.Format(timeLayout))
+	} else {
+		l.clock.SetText("")
+	}
+}
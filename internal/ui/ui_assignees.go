@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// assigneeText returns the initials of the assignees of a task, shown on the
+// card.
+func assigneeText(item *model.Item) string {
+	var initials []string
+	for _, a := range item.Assignees {
+		initials = append(initials, tview.Escape("@"+model.Initials(a)))
+	}
+	return strings.Join(initials, " ")
+}
+
+// CmdToggleMine shows only the tasks assigned to the user, or all tasks
+// again.
+func (l *Lanes) CmdToggleMine() {
+	l.toggleFilter(filterMine)
+}
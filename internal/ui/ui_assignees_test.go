@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestMineOnlyFilter(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	for i, title := range []string{"review", "deploy", "docs", "release"} {
+		c.AddItem(0, i, title, "", 2, "", "")
+	}
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.userName = "ann"
+	c.Items[0][1].Assignees = []string{"ann"}
+	c.Items[0][3].Assignees = []string{"bob", "ann"}
+	l.RedrawAllLanes()
+	if _, secondary := l.lanes[0].GetItemText(3); !strings.Contains(secondary, "@BO @AN") {
+		t.Fatalf("initials missing in %q", secondary)
+	}
+
+	l.CmdToggleMine()
+	if n := l.lanes[0].GetItemCount(); n != 2 || !strings.Contains(l.lanes[0].GetTitle(), "(mine)") {
+		t.Fatalf("%v tasks shown, title %q", n, l.lanes[0].GetTitle())
+	}
+	l.lanes[0].SetCurrentItem(1)
+	if item := l.currentItem(); item == nil || item.Title != "release" {
+		t.Fatalf("current task %+v", item)
+	}
+	l.deleteCurrentTask()
+	if len(c.Items[0]) != 3 || c.Items[0][2].Title != "docs" {
+		t.Fatalf("wrong task deleted: %+v", c.Items[0])
+	}
+
+	// tasks added while filtered are assigned to the user
+	l.CmdAddTask()
+	l.add.done("", "", true)
+	if item := l.currentItem(); l.lanes[0].GetItemCount() != 2 || item.Title != "(empty)" || !item.AssignedTo("ann") {
+		t.Fatalf("added task not shown: %+v", item)
+	}
+
+	// jumping to a hidden task removes the filter
+	if !l.FocusItem(c.Items[0][0].Guid) || l.filter != "" || l.currentItem().Title != "review" {
+		t.Fatalf("hidden task not focused, filter %q", l.filter)
+	}
+}
+
+func TestEditAssignees(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.Items[0][0].UserName = "ann"
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	l.CmdEditTask()
+	if got := l.edit.completeAssignee("bob, a"); len(got) != 1 || got[0] != "bob, ann" {
+		t.Fatalf("completion %v", got)
+	}
+	l.edit.assignees = "bob, ann"
+	l.edit.done("review", "", true)
+	if a := c.Items[0][0].Assignees; len(a) != 2 || a[0] != "bob" || len(c.Members) != 2 {
+		t.Fatalf("assignees %v, members %v", a, c.Members)
+	}
+}
@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/quickadd"
+)
+
+// markedGuids returns the GUIDs of the marked tasks in the order of the
+// board. Marks of tasks which no longer exist are dropped.
+func (l *Lanes) markedGuids() []string {
+	var guids []string
+	found := make(map[string]bool)
+	for lane := range l.content.Items {
+		for _, item := range l.content.Items[lane] {
+			if l.marked[item.Guid] {
+				guids = append(guids, item.Guid)
+				found[item.Guid] = true
+			}
+		}
+	}
+	for guid := range l.marked {
+		if !found[guid] {
+			delete(l.marked, guid)
+		}
+	}
+	return guids
+}
+
+// markedItems returns the marked tasks in the order of the board.
+func (l *Lanes) markedItems() []model.Item {
+	var items []model.Item
+	for lane := range l.content.Items {
+		for _, item := range l.content.Items[lane] {
+			if l.marked[item.Guid] {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// CmdToggleMark marks the current task for a bulk operation, or removes the
+// mark, and moves the cursor to the next task.
+func (l *Lanes) CmdToggleMark() {
+	item := l.currentItem()
+	if item == nil {
+		return
+	}
+	if l.marked[item.Guid] {
+		delete(l.marked, item.Guid)
+	} else {
+		l.marked[item.Guid] = true
+	}
+	pos := l.lanes[l.active].GetCurrentItem()
+	if pos+1 < l.lanes[l.active].GetItemCount() {
+		pos++
+	}
+	l.lanes[l.active].SetCurrentItem(pos)
+	l.redrawLane(l.active, l.itemIndex(l.active, pos))
+}
+
+// clearMarks removes all marks and redraws the lanes.
+func (l *Lanes) clearMarks() {
+	l.marked = make(map[string]bool)
+	l.redrawLanes()
+}
+
+// redrawLanes redraws all lanes, keeping the cursor positions.
+func (l *Lanes) redrawLanes() {
+	for i := range l.lanes {
+		l.redrawLane(i, l.currentIndex(i))
+	}
+}
+
+// bulkDone saves the board after a bulk operation changed it, removes the
+// marks and returns to the lanes.
+func (l *Lanes) bulkDone(lastIndex int) {
+	l.save()
+	l.clearMarks()
+	l.setActiveIndex(lastIndex)
+}
+
+// choiceModal shows a modal with buttons on the given page and a Cancel
+// button. The handler is called with the index of the button chosen, not if
+// the dialog was cancelled; the lanes are active again unless it opens
+// another dialog.
+func (l *Lanes) choiceModal(page, title, text string, buttons []string, lastIndex int, handler func(index int)) {
+	m := tview.NewModal().
+		SetTitle(title).
+		SetText(text).
+		AddButtons(append(append([]string{}, buttons...), "Cancel")).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage(page)
+			l.setActiveIndex(lastIndex)
+			if buttonIndex < 0 || buttonIndex >= len(buttons) {
+				return
+			}
+			handler(buttonIndex)
+		})
+	l.pages.RemovePage(page)
+	l.pages.AddPage(page, m, false, true)
+}
+
+// CmdBulk shows the operations for the marked tasks.
+func (l *Lanes) CmdBulk() {
+	lastIndex := l.saveActive()
+	guids := l.markedGuids()
+	if len(guids) == 0 {
+		msg := "No tasks are marked."
+		if keys := l.keymap.KeyText(ActionMark); keys != "" {
+			msg = fmt.Sprintf("No tasks are marked, use %v to mark tasks.", keys)
+		}
+		l.showError("lanes", msg)
+		return
+	}
+	ops := []string{"Move to Lane", "Archive", "Delete", "Priority", "Color", "Due Date", "Move to Mode", "Unmark All"}
+	l.choiceModal("bulk", " Marked Tasks ", fmt.Sprintf("%v marked tasks:", len(guids)), ops, lastIndex, func(index int) {
+		switch ops[index] {
+		case "Move to Lane":
+			l.choiceModal("bulkLane", " Move to Lane ", fmt.Sprintf("Move %v tasks to lane:", len(guids)), l.content.Titles, lastIndex, func(lane int) {
+				from := make([]int, len(guids))
+				for i, guid := range guids {
+					from[i], _, _ = l.content.FindItem(guid)
+				}
+				l.content.MoveItems(guids, lane)
+				l.bulkDone(lastIndex)
+				l.warnBlocked(guids, from, lane)
+			})
+		case "Archive":
+			l.choiceModal("bulkConfirm", " Archive Tasks ", fmt.Sprintf("About to archive %v tasks. Continue?", len(guids)), []string{"Yes"}, lastIndex, func(int) {
+				_, err := l.content.ArchiveItems(guids)
+				l.bulkDone(lastIndex)
+				if err != nil {
+					l.showError("lanes", err.Error())
+				}
+			})
+		case "Delete":
+			deleteTasks := func(int) {
+				l.content.RemoveItems(guids)
+				l.bulkDone(lastIndex)
+			}
+			if !l.settings.ConfirmDeleteEnabled() {
+				deleteTasks(0)
+				return
+			}
+			l.choiceModal("bulkConfirm", " Delete Tasks ", fmt.Sprintf("About to delete %v tasks. Continue?", len(guids)), []string{"Yes"}, lastIndex, deleteTasks)
+		case "Priority":
+			l.choiceModal("bulkPriority", " Priority ", fmt.Sprintf("Priority of %v tasks:", len(guids)), priorityNames, lastIndex, func(index int) {
+				l.content.UpdateItems(guids, func(item *model.Item) { item.Priority = index + 1 })
+				l.bulkDone(lastIndex)
+			})
+		case "Color":
+			l.bulkColorDialog(guids, lastIndex)
+		case "Due Date":
+			l.bulkDueDialog(guids, lastIndex)
+		case "Move to Mode":
+			l.transferTasksDialog(l.markedItems(), fmt.Sprintf("%v tasks", len(guids)), false, lastIndex)
+		case "Unmark All":
+			l.clearMarks()
+		}
+	})
+}
+
+func (l *Lanes) bulkColorDialog(guids []string, lastIndex int) {
+	colorDlg := NewColorModal("Task Color", "")
+	colorDlg.SetDoneFunc(func(color string, success bool) {
+		l.hideDialog("bulkColor")
+		l.pages.RemovePage("bulkColor")
+		if !success {
+			l.setActiveIndex(lastIndex)
+			return
+		}
+		l.content.UpdateItems(guids, func(item *model.Item) { item.Color = color })
+		l.bulkDone(lastIndex)
+	})
+	l.pages.AddPage("bulkColor", colorDlg, false, true)
+	l.showDialog("bulkColor", colorDlg)
+}
+
+func (l *Lanes) bulkDueDialog(guids []string, lastIndex int) {
+	dueDlg := NewModalInputText("Due Date", "Due:", fmt.Sprintf("E.g. %v, today, fri or +2d. Leave empty to remove the due date.", duePlaceholder()), 8, "")
+	dueDlg.SetDoneFunc(func(text, _ string, success bool) {
+		if !success {
+			l.hideDialog("bulkDue")
+			l.pages.RemovePage("bulkDue")
+			l.setActiveIndex(lastIndex)
+			return
+		}
+		due := ""
+		if text != "" {
+			var err error
+			if due, err = quickadd.ParseDue(text, dateFormat, time.Now()); err != nil {
+				l.showError("bulkDue", err.Error())
+				return
+			}
+		}
+		l.hideDialog("bulkDue")
+		l.pages.RemovePage("bulkDue")
+		l.content.UpdateItems(guids, func(item *model.Item) { item.Due = due })
+		l.bulkDone(lastIndex)
+	})
+	l.pages.AddPage("bulkDue", dueDlg, false, true)
+	l.showDialog("bulkDue", dueDlg)
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestToggleMarkAndBulkMove(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "a", "", 2, "", "")
+	c.AddItem(0, 1, "b", "", 2, "", "")
+	c.AddItem(0, 2, "c", "", 2, "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	l.CmdToggleMark()
+	if l.lanes[0].GetCurrentItem() != 1 {
+		t.Fatalf("cursor not moved to the next task")
+	}
+	l.CmdToggleMark()
+	l.CmdToggleMark()
+	l.lanes[0].SetCurrentItem(2)
+	l.CmdToggleMark() // unmarks c again
+	if main, _ := l.lanes[0].GetItemText(0); !strings.Contains(main, "✓") {
+		t.Fatalf("marker missing in %q", main)
+	}
+	if guids := l.markedGuids(); len(guids) != 2 || guids[0] != c.Items[0][0].Guid || guids[1] != c.Items[0][1].Guid {
+		t.Fatalf("marked %v", guids)
+	}
+
+	c.MoveItems(l.markedGuids(), 2)
+	l.bulkDone(0)
+	if len(l.marked) != 0 || len(c.Items[2]) != 2 || l.lanes[2].GetItemCount() != 2 {
+		t.Fatalf("unexpected board after bulk move %+v", c.Items)
+	}
+	if main, _ := l.lanes[2].GetItemText(0); strings.Contains(main, "✓") {
+		t.Fatalf("marker not removed in %q", main)
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// CmdAddComment asks for a comment and adds it to the thread of the current
+// task, with the user as author.
+func (l *Lanes) CmdAddComment() {
+	item := l.currentItem()
+	if item == nil {
+		return
+	}
+	lastIndex := l.saveActive()
+	guid := item.Guid
+	desc := "The comments are shown in the detail pane."
+	if n := len(item.Comments); n == 1 {
+		desc = "1 comment so far, shown in the detail pane."
+	} else if n > 1 {
+		desc = fmt.Sprintf("%d comments so far, shown in the detail pane.", n)
+	}
+	dlg := NewModalInputText("Add Comment: "+tview.Escape(shorten(item.Title, 30)), "Comment:", desc, 8, "")
+	dlg.SetDoneFunc(func(text, _ string, success bool) {
+		if success {
+			if err := l.content.AddComment(guid, l.userName, text, time.Now()); err != nil {
+				l.showError("comment", err.Error())
+				return
+			}
+			l.save()
+			l.redrawLanes()
+		}
+		l.hideDialog("comment")
+		l.pages.RemovePage("comment")
+		l.setActiveIndex(lastIndex)
+	})
+	l.pages.AddPage("comment", dlg, false, true)
+	l.showDialog("comment", dlg)
+}
@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestAddCommentDialog(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.userName = "ann"
+	l.RedrawAllLanes()
+
+	l.CmdAddComment()
+	dlg, ok := l.activeDialog.(*ModalInput)
+	if !ok {
+		t.Fatal("comment dialog not shown")
+	}
+	dlg.done("looks [good]", "", true)
+	if comments := c.Items[0][0].Comments; len(comments) != 1 || comments[0].Author != "ann" || comments[0].Text != "looks [good]" || l.dialogActive {
+		t.Fatalf("comment not added: %+v", comments)
+	}
+	if _, secondary := l.lanes[0].GetItemText(0); !strings.Contains(secondary, "✉1") {
+		t.Fatalf("comment count missing in %q", secondary)
+	}
+
+	text := detailText(&c.Items[0][0], "To Do", "pt", nil, l.theme, time.Now())
+	if !strings.Contains(text, "Comments") || !strings.Contains(text, "ann, ") || !strings.Contains(text, "looks [good[]") {
+		t.Fatalf("comments missing in detail text:\n%v", text)
+	}
+}
@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// priorityNames are the texts of the priorities 1 to 4.
+var priorityNames = []string{"1 (high)", "2 (normal)", "3 (low)", "4 (idle)"}
+
+// detailText describes a task for the detail pane, with the note rendered
+// as Markdown. The estimate is shown in the given unit.
+func detailText(item *model.Item, laneTitle, unit string, links []string, t *Theme, now time.Time) string {
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%v%v:[-] %v\n", tag(t.SecondaryText), name, value)
+		}
+	}
+	b.WriteString("[" + t.Title + "::b]" + tview.Escape(item.Title) + "[-::-]\n")
+	if item.Secondary != "" {
+		b.WriteString(tview.Escape(item.Secondary) + "\n")
+	}
+	b.WriteString("\n")
+
+	field("Number", item.Ref())
+	field("Lane", tview.Escape(strings.TrimSpace(laneTitle)))
+	if item.Due != "" {
+		due := isoToLocal(item.Due)
+		if suffix := dueSuffix(item.Due, now); suffix != "" {
+			col := t.Due
+			if suffix == "[overdue]" {
+				col = t.Overdue
+			}
+			due += " " + tag(col) + tview.Escape(suffix) + "[-]"
+		}
+		field("Due", due)
+	}
+	if item.Priority >= 1 && item.Priority <= len(priorityNames) {
+		field("Priority", priorityNames[item.Priority-1])
+	}
+	field("Color", item.Color)
+	if len(item.Tags) > 0 {
+		field("Tags", tview.Escape("#"+strings.Join(item.Tags, " #")))
+	}
+	if created := isoTimeToLocal(item.Created); created != "" {
+		field("Created", tview.Escape(fmt.Sprintf("%s (%s)", created, item.UserName)))
+	}
+	if updated := isoTimeToLocal(item.LastUpdate); updated != "" && item.LastUpdate != item.Created {
+		field("Modified", tview.Escape(fmt.Sprintf("%s (%s)", updated, item.UpdatedByName)))
+	}
+	field("From mode", tview.Escape(item.Mode))
+	if w := item.Waiting; w != nil {
+		waiting := tview.Escape(w.On)
+		if since := isoToLocal(w.Since); since != "" {
+			waiting += " since " + since
+		}
+		if followUp := isoToLocal(w.FollowUp); followUp != "" {
+			if item.FollowUpDue(now) {
+				followUp = tag(t.Due) + followUp + "[-]"
+			}
+			waiting += ", follow up " + followUp
+		}
+		field("Waiting on", waiting)
+	}
+	if item.Estimate > 0 {
+		field("Estimate", tview.Escape(model.FormatEstimate(item.Estimate, unit)))
+	}
+	if len(item.Assignees) > 0 {
+		field("Assignees", tview.Escape(strings.Join(item.Assignees, ", ")))
+	}
+	if tracked := item.TrackedTime(now); tracked > 0 {
+		running := ""
+		for _, e := range item.Time {
+			if e.End == "" {
+				running = " (running)"
+			}
+		}
+		field("Time", model.FormatDuration(tracked)+running)
+	}
+	if item.Pomodoros > 0 {
+		field("Pomodoros", strconv.Itoa(item.Pomodoros))
+	}
+	for _, link := range links {
+		field("Link", tview.Escape(link))
+	}
+
+	if strings.TrimSpace(item.Note) != "" {
+		b.WriteString("\n" + renderMarkdown(item.Note, t))
+	}
+	if len(item.Comments) > 0 {
+		b.WriteString("\n[" + t.Title + "::b]Comments[-::-]\n")
+		for _, c := range item.Comments {
+			fmt.Fprintf(&b, "%v%v, %v:[-]\n%v\n", tag(t.SecondaryText), tview.Escape(c.Author), isoTimeToLocal(c.Time), tview.Escape(c.Text))
+		}
+	}
+	return b.String()
+}
+
+// newDetailPane creates the text view showing the selected task.
+func newDetailPane() *tview.TextView {
+	detail := tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
+	detail.SetBorder(true).SetTitle(" Details ")
+	return detail
+}
+
+// styleDetail sets the theme colors of the detail pane.
+func (l *Lanes) styleDetail() {
+	t := l.theme
+	l.detail.SetTextColor(color(t.Text)).SetBackgroundColor(color(t.Background))
+	l.detail.SetBorderColor(color(t.Border)).SetTitleColor(color(t.Title))
+}
+
+// layoutBody arranges the lanes and, if shown, the detail pane.
+func (l *Lanes) layoutBody() {
+	l.body.Clear()
+	if !l.detailShown {
+		l.body.AddItem(l.flex, 0, 1, true)
+		return
+	}
+	if l.detailAt == "bottom" {
+		l.body.SetDirection(tview.FlexRow)
+	} else {
+		l.body.SetDirection(tview.FlexColumn)
+	}
+	l.body.AddItem(l.flex, 0, 2, true)
+	l.body.AddItem(l.detail, 0, 1, false)
+}
+
+// setDetailPane shows or hides the detail pane at the given position,
+// "right" or "bottom".
+func (l *Lanes) setDetailPane(shown bool, at string) {
+	if shown == l.detailShown && at == l.detailAt {
+		return
+	}
+	l.detailShown, l.detailAt = shown, at
+	l.layoutBody()
+	l.detailGuid = ""
+	l.updateDetail()
+}
+
+// updateDetail shows the current task in the detail pane.
+func (l *Lanes) updateDetail() {
+	if len(l.lanes) == 0 {
+		return
+	}
+	l.showDetail(l.lanes[l.active].GetCurrentItem())
+}
+
+// showDetail shows the task at the given position of the active lane in the
+// detail pane. The text is scrolled to the top when another task is shown.
+func (l *Lanes) showDetail(pos int) {
+	if !l.detailShown {
+		return
+	}
+	items := l.content.GetLaneItems(l.active)
+	pos = l.itemIndex(l.active, pos)
+	if pos < 0 || pos >= len(items) {
+		l.detail.SetText("")
+		l.detailGuid = ""
+		return
+	}
+	item := &items[pos]
+	l.detail.SetText(detailText(item, l.content.Titles[l.active], l.content.EstimateUnit(), l.linkTexts(item), l.theme, time.Now()))
+	if item.Guid != l.detailGuid {
+		l.detail.ScrollToBeginning()
+		l.detailGuid = item.Guid
+	}
+}
+
+// CmdToggleDetail shows or hides the detail pane and stores the choice in
+// the settings.
+func (l *Lanes) CmdToggleDetail() {
+	l.setDetailPane(!l.detailShown, l.detailAt)
+	if l.settingsChanged == nil {
+		return
+	}
+	if err := l.settingsChanged(map[string]string{"detailPane": strconv.FormatBool(l.detailShown)}, false); err != nil {
+		l.showError("lanes", err.Error())
+	}
+}
+
+// scrollDetail scrolls the detail pane by the given number of lines.
+func (l *Lanes) scrollDetail(lines int) {
+	if !l.detailShown {
+		return
+	}
+	row, _ := l.detail.GetScrollOffset()
+	if row += lines; row < 0 {
+		row = 0
+	}
+	l.detail.ScrollTo(row, 0)
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestDetailText(t *testing.T) {
+	th := builtinThemes[0]
+	item := &model.Item{Title: "Fix [bug]", Secondary: "login", Priority: 1, Color: "red",
+		Tags: []string{"web", "ui"}, Due: "2026-01-03", Note: "# Steps\n- [x] reproduce"}
+	text := detailText(item, " Doing ", "pt", []string{"blocks: deploy"}, th, time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local))
+	for _, want := range []string{"Fix [bug[]", "login", ":[-] Doing\n", "1 (high)", "#web #ui", "overdue", "☑ reproduce", "Steps", "blocks: deploy"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("%q missing in detail text %q", want, text)
+		}
+	}
+}
+
+func TestDetailPaneFollowsCursor(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "first", "", 2, "", "")
+	c.AddItem(0, 1, "second", "", 2, "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	l.CmdToggleDetail()
+	if !l.detailShown || l.body.GetItemCount() != 2 {
+		t.Fatalf("detail pane not shown")
+	}
+	if text := l.detail.GetText(false); !strings.Contains(text, "first") {
+		t.Fatalf("detail shows %q", text)
+	}
+	l.lanes[0].SetCurrentItem(1)
+	if text := l.detail.GetText(false); !strings.Contains(text, "second") {
+		t.Fatalf("detail not updated: %q", text)
+	}
+	l.CmdToggleDetail()
+	if l.detailShown || l.body.GetItemCount() != 1 {
+		t.Fatalf("detail pane not hidden")
+	}
+}
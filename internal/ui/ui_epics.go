@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// currentEpic returns the epic of the current task: the task itself if it
+// has children, or else its parent.
+func (l *Lanes) currentEpic() *model.Item {
+	item := l.currentItem()
+	if item == nil {
+		return nil
+	}
+	if len(l.content.Children(item.Guid)) > 0 || item.Parent == "" {
+		return item
+	}
+	if lane, pos, found := l.content.FindItem(item.Parent); found {
+		return &l.content.Items[lane][pos]
+	}
+	return item
+}
+
+// CmdEpic lists the child tasks of the current epic across all lanes. Enter
+// jumps to a task, 'f' shows only the epic and its children on the board or
+// all tasks again.
+func (l *Lanes) CmdEpic() {
+	lastIndex := l.saveActive()
+	epic := l.currentEpic()
+	if epic == nil {
+		return
+	}
+	children := l.content.Children(epic.Guid)
+	if len(children) == 0 {
+		l.showError("lanes", fmt.Sprintf("'%v' has no child tasks, the epic of a task is chosen in the edit dialog.", epic.Title))
+		return
+	}
+	guid := epic.Guid
+	done := 0
+	list := tview.NewList()
+	for _, child := range children {
+		mark := ""
+		if child.Lane == l.content.DoneLane() {
+			mark = tag(l.theme.Title) + "✓[-] "
+			done++
+		}
+		list.AddItem(mark+tview.Escape(child.Item.Title), tview.Escape(child.LaneTitle), 0, nil)
+	}
+	list.SetBorder(true).SetTitle(fmt.Sprintf(" %v - %d/%d done - Enter: show, f: filter board, Esc: close ", tview.Escape(shorten(epic.Title, 30)), done, len(children)))
+	list.SetBackgroundColor(color(l.theme.Background))
+	l.highlight(list)
+
+	closeEpic := func() {
+		l.pages.RemovePage("epic")
+		l.setActiveIndex(lastIndex)
+	}
+	list.SetDoneFunc(closeEpic)
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		closeEpic()
+		l.FocusItem(children[index].Item.Guid)
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'f' {
+			closeEpic()
+			l.toggleEpicFilter(guid)
+			return nil
+		}
+		if action, _ := l.keymap.Lookup([]string{keyName(event)}); action == ActionEpic || action == ActionQuit {
+			closeEpic()
+			return nil
+		}
+		return event
+	})
+
+	l.pages.RemovePage("epic")
+	l.pages.AddPage("epic", list, true, true)
+	l.app.SetFocus(list)
+}
+
+// toggleEpicFilter shows only the epic and its children, or all tasks again
+// if the board is filtered to this epic.
+func (l *Lanes) toggleEpicFilter(guid string) {
+	if l.filter == filterEpic && l.epic == guid {
+		l.setFilter("")
+		return
+	}
+	l.epic = guid
+	l.setFilter(filterEpic)
+}
+
+// archiveEpic asks what to do with the open children of the current task
+// before archiving it: archive them as well or keep them on the board
+// without epic. It returns false if the task has no open children.
+func (l *Lanes) archiveEpic() bool {
+	item := l.currentItem()
+	if item == nil {
+		return false
+	}
+	open := l.content.OpenChildren(item.Guid)
+	if len(open) == 0 {
+		return false
+	}
+	guid := item.Guid
+	lastIndex := l.saveActive()
+	m := tview.NewModal().
+		SetTitle(" Archive Epic ").
+		SetText(fmt.Sprintf("'%v' has %d open child tasks. Archive them as well, or keep them on the board?", item.Title, len(open))).
+		AddButtons([]string{"Archive all", "Keep children", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("archiveEpic")
+			l.setActiveIndex(lastIndex)
+			if buttonLabel == "Archive all" || buttonLabel == "Keep children" {
+				l.archiveWithChildren(guid, buttonLabel == "Archive all")
+			}
+		})
+	l.pages.AddPage("archiveEpic", m, false, true)
+	l.app.SetFocus(m)
+	return true
+}
+
+// archiveWithChildren archives a task and, if children is set, all its
+// children. Otherwise the children stay on the board without epic.
+func (l *Lanes) archiveWithChildren(guid string, children bool) {
+	var err error
+	if children {
+		var guids []string
+		for _, child := range l.content.Children(guid) {
+			guids = append(guids, child.Item.Guid)
+		}
+		_, err = l.content.ArchiveItems(guids)
+	}
+	if lane, pos, found := l.content.FindItem(guid); found && err == nil {
+		err = l.content.ArchiveItem(lane, pos)
+	}
+	l.redrawLanes()
+	l.save()
+	if err != nil {
+		l.showError("lanes", err.Error())
+	}
+}
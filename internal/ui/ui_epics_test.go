@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func newEpicLanes(t *testing.T) (*Lanes, *model.ToDoContent) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	for i, title := range []string{"epic", "design", "other", "build"} {
+		c.AddItem(0, i, title, "", 2, "", "")
+	}
+	c.SetFileName(t.TempDir()+"/todo.json", t.TempDir(), t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+	return l, c
+}
+
+func TestEpicFilter(t *testing.T) {
+	l, c := newEpicLanes(t)
+	epic := c.Items[0][0].Guid
+
+	// the epic is chosen in the edit dialog
+	l.lanes[0].SetCurrentItem(1)
+	l.CmdEditTask()
+	l.edit.epic = epic
+	l.edit.done("design", "", true)
+	c.Items[0][3].Parent = epic
+	l.RedrawAllLanes()
+	if _, secondary := l.lanes[0].GetItemText(0); !strings.Contains(secondary, "◆ 0/2 done") {
+		t.Fatalf("epic badge missing in %q", secondary)
+	}
+
+	l.lanes[0].SetCurrentItem(3)
+	l.CmdEpic()
+	name, page := l.pages.GetFrontPage()
+	if list, ok := page.(*tview.List); name != "epic" || !ok || list.GetItemCount() != 2 {
+		t.Fatal("children not listed")
+	}
+	l.pages.RemovePage("epic")
+	l.toggleEpicFilter(epic)
+	if n := l.lanes[0].GetItemCount(); n != 3 {
+		t.Fatalf("%v tasks shown", n)
+	}
+	l.toggleEpicFilter(epic)
+	if n := l.lanes[0].GetItemCount(); n != 4 {
+		t.Fatalf("%v tasks shown without filter", n)
+	}
+}
+
+func TestArchiveEpic(t *testing.T) {
+	l, c := newEpicLanes(t)
+	epic := c.Items[0][0].Guid
+	c.Items[0][1].Parent = epic
+	c.Items[0][3].Parent = epic
+	l.RedrawAllLanes()
+
+	l.lanes[0].SetCurrentItem(2)
+	if l.archiveEpic() {
+		t.Fatal("task without children asked")
+	}
+	l.lanes[0].SetCurrentItem(0)
+	l.CmdArchiveNote()
+	if name, _ := l.pages.GetFrontPage(); name != "archiveEpic" {
+		t.Fatalf("front page %q", name)
+	}
+	l.pages.RemovePage("archiveEpic")
+
+	l.archiveWithChildren(epic, false)
+	if len(c.Items[0]) != 3 || c.Items[0][0].Parent != "" || c.Items[0][2].Parent != "" {
+		t.Fatalf("children not kept: %+v", c.Items[0])
+	}
+
+	l2, c2 := newEpicLanes(t)
+	c2.Items[0][1].Parent = c2.Items[0][0].Guid
+	l2.archiveWithChildren(c2.Items[0][0].Guid, true)
+	if len(c2.Items[0]) != 2 || c2.Items[0][0].Title != "other" {
+		t.Fatalf("children not archived: %+v", c2.Items[0])
+	}
+}
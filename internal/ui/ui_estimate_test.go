@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestEditEstimate(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.ApplySettings(config.Settings{EstimateUnit: "h"})
+	l.RedrawAllLanes()
+
+	l.CmdEditTask()
+	l.edit.estimate = "much"
+	l.edit.done("review", "", true)
+	if c.Items[0][0].Estimate != 0 {
+		t.Fatal("invalid estimate stored")
+	}
+	l.pages.RemovePage("error")
+	l.edit.estimate = "1.5h"
+	l.edit.done("review", "", true)
+	if c.Items[0][0].Estimate != 1.5 {
+		t.Fatalf("estimate %v", c.Items[0][0].Estimate)
+	}
+	if title := l.lanes[0].GetTitle(); !strings.Contains(title, "(1 · 1.5h)") {
+		t.Fatalf("lane title %q", title)
+	}
+	if _, secondary := l.lanes[0].GetItemText(0); !strings.Contains(secondary, "1.5h") {
+		t.Fatalf("estimate missing in %q", secondary)
+	}
+}
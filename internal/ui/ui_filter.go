@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// Filters of the lanes.
+const (
+	filterMine    = "mine"
+	filterWaiting = "waiting"
+	filterEpic    = "epic"
+	filterSearch  = "search"
+)
+
+// visible returns whether a task is shown in its lane: all tasks, or the
+// tasks matching the filter.
+func (l *Lanes) visible(item *model.Item) bool {
+	switch l.filter {
+	case filterMine:
+		return item.AssignedTo(l.userName)
+	case filterWaiting:
+		return item.IsWaiting()
+	case filterEpic:
+		return item.Guid == l.epic || item.Parent == l.epic
+	case filterSearch:
+		return item.Matches(l.search)
+	default:
+		return true
+	}
+}
+
+// itemIndex returns the index in the lane of the board of the task shown at
+// the given position of the lane list. Without filter both are the same. A
+// position after the last task returns the end of the lane.
+func (l *Lanes) itemIndex(lane, pos int) int {
+	if lane >= len(l.shown) || l.shown[lane] == nil || pos < 0 {
+		return pos
+	}
+	if pos >= len(l.shown[lane]) {
+		return len(l.content.GetLaneItems(lane))
+	}
+	return l.shown[lane][pos]
+}
+
+// listPos returns the position in the lane list of the task with the given
+// index in the lane of the board, or of the next task shown after it.
+func (l *Lanes) listPos(lane, idx int) int {
+	if lane >= len(l.shown) || l.shown[lane] == nil {
+		return idx
+	}
+	for pos, i := range l.shown[lane] {
+		if i >= idx {
+			return pos
+		}
+	}
+	return len(l.shown[lane]) - 1
+}
+
+// currentIndex returns the index in the lane of the board of the current
+// task of a lane.
+func (l *Lanes) currentIndex(lane int) int {
+	return l.itemIndex(lane, l.lanes[lane].GetCurrentItem())
+}
+
+// toggleFilter shows only the tasks matching a filter, or all tasks again if
+// the filter is active.
+func (l *Lanes) toggleFilter(filter string) {
+	if l.inselect {
+		return
+	}
+	if l.filter == filter {
+		filter = ""
+	}
+	l.setFilter(filter)
+}
+
+func (l *Lanes) setFilter(filter string) {
+	l.filter = filter
+	l.redrawLanes()
+}
+
+// CmdSearch asks for a text and shows only the tasks matching it, see
+// model.Item.Matches. An empty text shows all tasks again.
+func (l *Lanes) CmdSearch() {
+	if l.inselect {
+		return
+	}
+	lastIndex := l.saveActive()
+	dlg := NewModalInputText("Search", "Text:", "Shows the tasks with the number, e.g. 42 or #42, or with the text in the title, note or tags.", 7, l.search)
+	dlg.SetDoneFunc(func(text, _ string, success bool) {
+		l.hideDialog("search")
+		l.pages.RemovePage("search")
+		l.setActiveIndex(lastIndex)
+		if !success {
+			return
+		}
+		l.search = strings.TrimSpace(text)
+		if l.search == "" {
+			l.setFilter("")
+		} else {
+			l.setFilter(filterSearch)
+		}
+	})
+	l.pages.AddPage("search", dlg, false, true)
+	l.showDialog("search", dlg)
+}
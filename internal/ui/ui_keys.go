@@ -2,92 +2,149 @@ package ui
 
 import "github.com/gdamore/tcell/v2"
 
+// SetKeymap replaces the active key bindings and updates the help page.
+func (l *Lanes) SetKeymap(km *Keymap) {
+	l.keymap = km
+	l.pendingKeys = nil
+	if l.help != nil {
+		l.help.SetText(l.aboutText())
+	}
+}
+
+// Keymap returns the active key bindings.
+func (l *Lanes) Keymap() *Keymap {
+	return l.keymap
+}
+
 func (l *Lanes) HotKeyHandler(event *tcell.EventKey) *tcell.EventKey {
-	switch event.Key() {
-	case tcell.KeyF10:
-		l.CmdExit()
-		return nil
-		// case tcell.KeyF9:
-		// 	l.CmdSelectMode()
-		// 	return nil
-	case tcell.KeyF7:
-		l.CmdLanesCmds()
-		return nil
-	case tcell.KeyF6:
-		l.CmdSelectNote()
-		return nil
-	case tcell.KeyF5:
-		l.CmdArchiveNote()
-		return nil
-	case tcell.KeyF4:
-		l.CmdEditNote()
-		return nil
-	case tcell.KeyF3:
-		l.CmdEditTask()
+	name := keyName(event)
+	if name == "" {
+		l.pendingKeys = nil
+		return event
+	}
+
+	seq := append(append([]string{}, l.pendingKeys...), name)
+	action, isPrefix := l.keymap.Lookup(seq)
+	if isPrefix {
+		// wait for the next key of a chord like "dd"
+		l.pendingKeys = seq
 		return nil
-	case tcell.KeyF2:
-		fallthrough
-	case tcell.KeyInsert:
+	}
+	if action == "" && len(l.pendingKeys) > 0 {
+		// the chord was not completed, run a shorter binding of the
+		// pending keys and handle the key on its own
+		pendingAction, _ := l.keymap.Lookup(l.pendingKeys)
+		l.pendingKeys = nil
+		if pendingAction != "" {
+			l.runAction(pendingAction, event)
+		}
+		return l.HotKeyHandler(event)
+	}
+	l.pendingKeys = nil
+	if action == "" {
+		return event
+	}
+	return l.runAction(action, event)
+}
+
+// runAction executes the action bound to a key. The returned event is
+// passed on to the focused list, nil if the key was consumed.
+func (l *Lanes) runAction(action string, event *tcell.EventKey) *tcell.EventKey {
+	switch action {
+	case ActionQuit:
+		l.CmdExit()
+	case ActionAbout:
+		l.CmdAbout()
+	case ActionAdd:
 		l.CmdAddTask()
-		return nil
-	case tcell.KeyDelete:
-		l.pages.ShowPage("delete")
-		return nil
-	case tcell.KeyTab:
+	case ActionAddBelow:
+		l.CmdAddTaskBelow()
+	case ActionEdit:
+		l.CmdEditTask()
+	case ActionNote:
+		l.CmdEditNote()
+	case ActionArchive:
+		l.CmdArchiveNote()
+	case ActionDelete:
+		l.CmdDeleteTask()
+	case ActionSelect:
+		l.CmdSelectNote()
+	case ActionLaneCmds:
+		l.CmdLanesCmds()
+	case ActionMode:
+		l.CmdSelectModeDialog()
+	case ActionTheme:
+		l.CmdSelectThemeDialog()
+	case ActionSettings:
+		l.CmdSettingsDialog()
+	case ActionOverview:
+		l.CmdOverview()
+	case ActionMoveMode:
+		l.CmdMoveToMode()
+	case ActionCopyMode:
+		l.CmdCopyToMode()
+	case ActionMark:
+		l.CmdToggleMark()
+	case ActionBulk:
+		l.CmdBulk()
+	case ActionDetail:
+		l.CmdToggleDetail()
+	case ActionLinks:
+		l.CmdLinks()
+	case ActionTimer:
+		l.CmdToggleTimer()
+	case ActionTimeLog:
+		l.CmdTimeLog()
+	case ActionPomodoro:
+		l.CmdPomodoro()
+	case ActionMineOnly:
+		l.CmdToggleMine()
+	case ActionComment:
+		l.CmdAddComment()
+	case ActionWaiting:
+		l.CmdToggleWaiting()
+	case ActionSprint:
+		l.CmdSprint()
+	case ActionEpic:
+		l.CmdEpic()
+	case ActionGoto:
+		l.CmdGoto()
+	case ActionSearch:
+		l.CmdSearch()
+	case ActionDetailUp:
+		l.scrollDetail(-5)
+	case ActionDetailDn:
+		l.scrollDetail(5)
+	case ActionNextLane:
 		l.incActive()
-		return nil
-	case tcell.KeyBacktab:
+	case ActionPrevLane:
 		l.decActive()
-		return nil
-	case tcell.KeyUp:
-		if l.inselect {
-			l.up()
-			return nil
-		}
-	case tcell.KeyDown:
-		if l.inselect {
-			l.down()
-			return nil
-		}
-	case tcell.KeyLeft:
+	case ActionLeft:
 		if l.inselect {
 			l.moveSelectionLeft()
 		} else {
 			l.decActive()
 		}
-		return nil
-	case tcell.KeyRight:
+	case ActionRight:
 		if l.inselect {
 			l.moveSelectionRight()
 		} else {
 			l.incActive()
 		}
-		return nil
-	case tcell.KeyF1:
-		l.CmdAbout()
-		return nil
-	}
-	switch event.Rune() {
-	case 'q':
-		l.CmdExit()
-	case 'h':
-		fallthrough
-	case '?':
-		l.CmdAbout()
-	case 'd':
-		l.pages.ShowPage("delete")
-	case '+':
-		l.CmdAddTask()
-		return nil
-	case 'a':
-		l.CmdArchiveNote()
-		return nil
-	case 'e':
-		l.CmdEditTask()
-	case 'n':
-		l.CmdEditNote()
-	case 'm':
-		l.CmdSelectModeDialog()
+	case ActionUp:
+		if l.inselect {
+			l.up()
+			return nil
+		}
+		return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+	case ActionDown:
+		if l.inselect {
+			l.down()
+			return nil
+		}
+		return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+	default:
+		return event
 	}
-	return event
+	return nil
 }
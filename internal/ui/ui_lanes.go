@@ -1,16 +1,19 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/user"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/quickadd"
 	"github.com/cklukas/todo/internal/util"
 )
 
@@ -34,7 +37,7 @@ func (l *Lanes) RedrawAllLanes() {
 		if len(l.content.Items[laneIdx]) == 0 {
 			continue
 		}
-		currentIndexInLine := l.lanes[laneIdx].GetCurrentItem()
+		currentIndexInLine := l.currentIndex(laneIdx)
 		validIndexInLine := util.NormPos(currentIndexInLine, len(l.content.Items[laneIdx]))
 		l.redrawLane(laneIdx, validIndexInLine)
 	}
@@ -42,22 +45,28 @@ func (l *Lanes) RedrawAllLanes() {
 
 func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirModes, version string) *Lanes {
 	l := &Lanes{
-		nextMode:         "",
-		nextLaneFocus:    0,
 		todoDirModes:     todoDirModes,
 		mode:             mode,
+		modeStates:       make(map[string]modeState),
 		appVersion:       version,
 		content:          content,
-		lanes:            make([]*tview.List, content.GetNumLanes()),
+		userName:         currentUserName(),
+		flex:             tview.NewFlex(),
+		body:             tview.NewFlex(),
+		detail:           newDetailPane(),
 		active:           0,
 		lastActive:       0,
 		lastActiveSaved:  false,
 		pages:            tview.NewPages(),
 		app:              app,
 		inselect:         false,
+		marked:           make(map[string]bool),
 		add:              NewModalInput("Add Task"),
 		edit:             NewModalInput("Edit Task"),
 		addMode:          NewModalInputMode("Add Mode", todoDirModes),
+		keymap:           DefaultKeymap(),
+		theme:            DefaultTheme(),
+		themes:           BuiltinThemes(),
 		bMoveHelp:        nil,
 		dialogActive:     false,
 		activeDialog:     nil,
@@ -69,52 +78,10 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 	app.SetInputCapture(l.appInputCapture)
 	l.origMouseCapture = app.GetMouseCapture()
 	app.SetMouseCapture(l.appMouseCapture)
-	flex := tview.NewFlex()
-	for i := 0; i < l.content.GetNumLanes(); i++ {
-		l.lanes[i] = tview.NewList()
-		l.lanes[i].SetSelectedFocusOnly(true)
-		if col := l.content.GetLaneColor(i); col != "" {
-			l.lanes[i].SetBackgroundColor(tcell.GetColor(col))
-		}
-		xi := i
-		l.lanes[i].SetFocusFunc(func() {
-			l.lanes[xi].SetSelectedStyle(tcell.StyleDefault)
-			l.active = xi
-			l.lanes[xi].SetSelectedBackgroundColor(tcell.ColorLightBlue)
-			l.lanes[xi].SetSelectedTextColor(tcell.ColorBlack)
-			if l.lastActiveSaved {
-				l.lastActiveSaved = false
-				if l.lastActive > 0 {
-					for i := 0; i < l.lastActive; i++ {
-						l.incActive()
-					}
-				}
-			}
-		})
-		l.lanes[i].ShowSecondaryText(true).SetBorder(true)
-		l.lanes[i].SetTitle(l.content.GetLaneTitle(i))
-		l.lanes[i].SetInputCapture(l.HotKeyHandler)
-		l.lanes[i].SetSelectedFunc(func(w int, x string, y string, z rune) {
-			if l.inselect {
-				l.selected()
-				content.Save()
-			} else {
-				l.selected()
-			}
-		})
-		l.lanes[i].SetDoneFunc(func() {
-			// Cancel select on Done (escape)
-			if l.inselect {
-				l.selected()
-				content.Save()
-			}
-		})
-		for _, item := range l.content.GetLaneItems(i) {
-			l.lanes[i].AddItem(item.Title, item.Secondary, 0, nil)
-		}
-		flex.AddItem(l.lanes[i], 0, 1, i == 0)
-	}
-	l.pages.AddPage("lanes", flex, true, true)
+	l.buildLanes()
+	l.styleDetail()
+	l.layoutBody()
+	l.pages.AddPage("lanes", l.body, true, true)
 
 	quit := tview.NewModal().
 		SetText("Do you want to quit the application?").
@@ -128,20 +95,13 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 		})
 	l.pages.AddPage("quit", quit, false, false)
 
-	// help := tview.NewModal().
-	help := tview.NewModal()
-	aboutText := fmt.Sprintf("Version: %s", l.appVersion)
-	if util.IsLocalDevelopmentVersion(l.appVersion) {
-		aboutText += " (local development version)"
-	}
-	aboutText += "\n- developed by C. Klukas -\n\n- adapted from toukan (https://github.com/witchard/toukan) -\n\nUsage/Keys:\nEnter/space - mark task, cursor keys - move marked task, +/Insert - add, e - edit, Del/d - delete task, n - note, a - archive, Tab - switch lane, m - select mode, q - quit"
 	if tag, newer, err := util.LatestReleaseInfo(l.appVersion); err == nil && newer {
 		if exe, err := os.Executable(); err == nil {
-			aboutText += fmt.Sprintf("\n\nA newer version %s is available.\nUse \"%s version --update\" to update.", tag, exe)
+			l.releaseNote = fmt.Sprintf("\n\nA newer version %s is available.\nUse \"%s version --update\" to update.", tag, exe)
 		}
 	}
-	help = help.
-		SetText(aboutText).
+	help := tview.NewModal().
+		SetText(l.aboutText()).
 		AddButtons([]string{"OK"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			l.pages.HidePage("help")
@@ -149,6 +109,7 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 		})
 
 	help.SetTitle(" About TODO ")
+	l.help = help
 
 	l.pages.AddPage("help", help, false, false)
 
@@ -157,10 +118,7 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 		AddButtons([]string{"Yes", "No"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			if buttonLabel == "Yes" {
-				item := l.lanes[l.active].GetCurrentItem()
-				l.content.DelItem(l.active, item)
-				l.redrawLane(l.active, item)
-				content.Save()
+				l.deleteCurrentTask()
 			}
 			l.pages.HidePage("delete")
 			l.setActive()
@@ -173,14 +131,14 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 		AddButtons([]string{"Yes", "No"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			if buttonLabel == "Yes" {
-				item := l.lanes[l.active].GetCurrentItem()
+				item := l.currentIndex(l.active)
 				err := l.content.ArchiveItem(l.active, item)
 				if err != nil {
 					app.Stop()
 					log.Fatal(err)
 				}
 				l.redrawLane(l.active, item)
-				content.Save()
+				l.save()
 			}
 			l.pages.HidePage("archive")
 			l.setActive()
@@ -194,22 +152,86 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 
 	l.pages.AddPage("wait", waitPage, false, false)
 
+	l.modals = []*tview.Modal{quit, help, delete, archive, waitPage}
+
 	l.add.SetDoneFunc(func(text string, secondary string, success bool) {
 		if success {
 			if !l.add.DueValid() {
 				l.showError("add", "Invalid due date")
 				return
 			}
-			item := l.lanes[l.active].GetCurrentItem()
-			if len(text) == 0 {
-				text = "(empty)"
+			estimate, err := l.add.GetEstimate()
+			if err != nil {
+				l.showError("add", "Invalid estimate")
+				return
+			}
+			lane := l.active
+			item := l.currentIndex(l.active)
+			if l.addBelow && l.lanes[l.active].GetItemCount() > 0 {
+				item++
 			}
 			prio := l.add.GetPriority()
 			due := l.add.GetDueISO()
 			color := l.add.GetColor()
-			l.content.AddItem(l.active, item, text, secondary, prio, due, color)
-			l.redrawLane(l.active, item)
-			content.Save()
+			var tags []string
+			q, quick := l.add.GetQuickAdd()
+			if quick {
+				if len(q.Errors) > 0 {
+					l.showError("add", strings.Join(q.Errors, ", "))
+					return
+				}
+				if err := q.CheckRefs(l.content); err != nil {
+					l.showError("add", err.Error())
+					return
+				}
+				text = q.Title
+				tags = q.Tags
+				if q.Priority > 0 {
+					prio = q.Priority
+				}
+				if q.Due != "" {
+					due = q.Due
+				}
+				if q.Color != "" {
+					color = q.Color
+				}
+				if q.Lane >= 0 && q.Lane != lane {
+					lane, item = q.Lane, 0
+				}
+			}
+			if len(text) == 0 {
+				text = "(empty)"
+			}
+			var applyErr error
+			l.saveChange(func() error {
+				// the board may have been reloaded since the position was chosen
+				if lane >= len(l.content.Items) {
+					return fmt.Errorf("lane %v no longer exists", lane+1)
+				}
+				if item > len(l.content.Items[lane]) {
+					item = len(l.content.Items[lane])
+				}
+				l.content.AddItem(lane, item, text, secondary, prio, due, color)
+				l.content.Items[lane][item].Note = l.add.GetNote()
+				l.content.Items[lane][item].Tags = tags
+				l.content.Items[lane][item].Assignees = l.add.GetAssignees()
+				l.content.Items[lane][item].Estimate = estimate
+				if l.filter == filterEpic {
+					// new tasks stay visible in the filtered lanes
+					l.content.Items[lane][item].Parent = l.epic
+				}
+				if quick {
+					applyErr = q.Apply(l.content, l.content.Items[lane][item].Guid)
+				}
+				l.content.AddMembers(l.add.GetAssignees()...)
+				return nil
+			})
+			if lane < len(l.lanes) {
+				l.redrawLane(lane, item)
+			}
+			if applyErr != nil {
+				l.showError("lanes", applyErr.Error())
+			}
 		}
 		l.hideDialog("add")
 	})
@@ -219,8 +241,14 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 		l.pages.HidePage("addMode")
 		l.setActive()
 		if success {
-			l.nextMode = text
-			l.app.Stop()
+			if l.modeStore != nil && !l.modeStore.Exists(text) {
+				tpl := model.FindTemplate(l.templates, l.addMode.GetTemplate())
+				if err := l.modeStore.Create(text, tpl); err != nil {
+					l.showError("lanes", err.Error())
+					return
+				}
+			}
+			l.switchMode(text, "")
 		}
 	})
 	l.pages.AddPage("addMode", l.addMode, false, false)
@@ -231,19 +259,52 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 				l.showError("edit", "Invalid due date")
 				return
 			}
-			item := l.lanes[l.active].GetCurrentItem()
+			estimate, err := l.edit.GetEstimate()
+			if err != nil {
+				l.showError("edit", "Invalid estimate")
+				return
+			}
+			now := time.Now()
+			waitingOn, followUp := l.edit.GetWaiting()
+			if waitingOn != "" && followUp != "" {
+				if followUp, err = quickadd.ParseDue(followUp, dateFormat, now); err != nil {
+					l.showError("edit", "Invalid follow-up date")
+					return
+				}
+			}
+			item := l.currentIndex(l.active)
 			itemVal := l.currentItem()
 			itemVal.Title = text
 			itemVal.Secondary = secondary
 			itemVal.Priority = l.edit.GetPriority()
 			itemVal.Due = l.edit.GetDueISO()
 			itemVal.Color = l.edit.GetColor()
-			itemVal.LastUpdate = time.Now().UTC().Format(time.RFC3339)
+			itemVal.Estimate = estimate
+			itemVal.Assignees = l.edit.GetAssignees()
+			l.content.AddMembers(itemVal.Assignees...)
+			itemVal.SetWaiting(waitingOn, followUp, now)
+			itemVal.LastUpdate = now.UTC().Format(time.RFC3339)
 			if usr, err := user.Current(); err == nil {
 				itemVal.UpdatedByName = usr.Username
 			}
-			l.redrawLane(l.active, item)
-			l.content.Save()
+			epicChanged := l.edit.GetEpic() != itemVal.Parent
+			linked, err := l.applyEditLink(itemVal.Guid)
+			if epicChanged && err == nil {
+				err = l.content.SetParent(itemVal.Guid, l.edit.GetEpic())
+				linked = true
+			}
+			if linked {
+				// the linked task or the epic is marked as well
+				l.redrawLanes()
+			} else {
+				l.redrawLane(l.active, item)
+			}
+			l.save()
+			l.hideDialog("edit")
+			if err != nil {
+				l.showError("lanes", err.Error())
+			}
+			return
 		}
 		l.hideDialog("edit")
 	})
@@ -252,6 +313,88 @@ func NewLanes(content *model.ToDoContent, app *tview.Application, mode, todoDirM
 	return l
 }
 
+// buildLanes creates a list for each lane of the board, replacing the lists
+// shown before.
+func (l *Lanes) buildLanes() {
+	l.flex.Clear()
+	l.lanes = make([]*tview.List, l.content.GetNumLanes())
+	for i := range l.lanes {
+		l.lanes[i] = tview.NewList()
+		l.lanes[i].SetSelectedFocusOnly(true)
+		l.styleLane(i)
+		xi := i
+		l.lanes[i].SetFocusFunc(func() {
+			l.lanes[xi].SetSelectedStyle(tcell.StyleDefault)
+			l.active = xi
+			l.highlight(l.lanes[xi])
+			l.updateDetail()
+			if l.lastActiveSaved {
+				l.lastActiveSaved = false
+				if l.lastActive > 0 {
+					for i := 0; i < l.lastActive; i++ {
+						l.incActive()
+					}
+				}
+			}
+		})
+		l.lanes[i].ShowSecondaryText(true).SetBorder(true)
+		l.lanes[i].SetTitle(l.content.GetLaneTitle(i))
+		l.lanes[i].SetInputCapture(l.HotKeyHandler)
+		l.lanes[i].SetSelectedFunc(func(w int, x string, y string, z rune) {
+			if l.inselect {
+				l.selected()
+				l.save()
+			} else {
+				l.selected()
+			}
+		})
+		l.lanes[i].SetChangedFunc(func(index int, _, _ string, _ rune) {
+			// called before the list changes its current item
+			if xi == l.active {
+				l.showDetail(index)
+			}
+		})
+		l.lanes[i].SetDoneFunc(func() {
+			// Cancel select on Done (escape)
+			if l.inselect {
+				l.selected()
+				l.save()
+			}
+		})
+		for _, item := range l.content.GetLaneItems(i) {
+			l.lanes[i].AddItem(item.Title, item.Secondary, 0, nil)
+		}
+		l.flex.AddItem(l.lanes[i], 0, 1, i == 0)
+	}
+}
+
+// reloadLanes shows the lanes of the board again after lanes were added or
+// removed, or the board was replaced, and activates the given lane.
+func (l *Lanes) reloadLanes(active int) {
+	if l.inselect {
+		l.inselect = false
+		l.updateTimer(time.Now())
+	}
+	l.lastActiveSaved = false
+	l.buildLanes()
+	l.RedrawAllLanes()
+	if active >= len(l.lanes) {
+		active = len(l.lanes) - 1
+	}
+	l.setActiveIndex(active)
+}
+
+// aboutText returns the text of the help page, listing the active key
+// bindings.
+func (l *Lanes) aboutText() string {
+	aboutText := fmt.Sprintf("Version: %s", l.appVersion)
+	if util.IsLocalDevelopmentVersion(l.appVersion) {
+		aboutText += " (local development version)"
+	}
+	aboutText += "\n- developed by C. Klukas -\n\n- adapted from toukan (https://github.com/witchard/toukan) -\n\nUsage/Keys:\nEnter/space - select task to move, " + l.keymap.HelpText()
+	return aboutText + l.releaseNote
+}
+
 func (l *Lanes) showError(pageReturn, message string) {
 	modal := tview.NewModal().
 		SetText(message).
@@ -264,6 +407,48 @@ func (l *Lanes) showError(pageReturn, message string) {
 	l.pages.AddPage("error", modal, false, true)
 }
 
+// save writes the board and shows the error if this fails. If another
+// instance changed the board meanwhile, it is reloaded and the last change is
+// lost, which the user is told.
+func (l *Lanes) save() {
+	err := l.content.Save()
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, model.ErrConflict) {
+		l.showError("lanes", fmt.Sprintf("The board could not be saved: %v", err))
+		return
+	}
+	if err := l.content.Read(); err != nil {
+		l.showError("lanes", err.Error())
+		return
+	}
+	l.RedrawAllLanes()
+	l.showError("lanes", "The board was changed by another instance and has been reloaded, your last change was not saved.")
+}
+
+// saveChange applies a change to the board and writes it. If another
+// instance changed the board meanwhile, the board is reloaded and the change
+// is applied again, so e.g. an added task is kept and gets the next free
+// number.
+func (l *Lanes) saveChange(change func() error) {
+	err := change()
+	if err == nil {
+		err = l.content.Save()
+	}
+	if errors.Is(err, model.ErrConflict) {
+		if err = l.content.Read(); err == nil {
+			if err = change(); err == nil {
+				err = l.content.Save()
+			}
+		}
+		l.RedrawAllLanes()
+	}
+	if err != nil {
+		l.showError("lanes", fmt.Sprintf("The board could not be saved: %v", err))
+	}
+}
+
 func (l *Lanes) CmdLanesCmds() {
 	initActiveLane := l.saveActive()
 	addToLeft := false
@@ -321,7 +506,7 @@ func (l *Lanes) renameLaneCommand(initActiveLane int) {
 		l.hideDialog("addLane")
 		l.setActiveIndex(initActiveLane)
 		l.content.SetLaneTitle(initActiveLane, lane)
-		l.content.Save()
+		l.save()
 		l.RedrawAllLanes()
 	})
 
@@ -337,8 +522,8 @@ func (l *Lanes) laneColorCommand(initActiveLane int) {
 		l.setActiveIndex(initActiveLane)
 		if success {
 			l.content.SetLaneColor(initActiveLane, color)
-			l.redrawLane(initActiveLane, l.lanes[initActiveLane].GetCurrentItem())
-			l.content.Save()
+			l.redrawLane(initActiveLane, l.currentIndex(initActiveLane))
+			l.save()
 		}
 	})
 
@@ -358,14 +543,13 @@ func (l *Lanes) addLaneLeftRightCommand(addToLeft bool, initActiveLane int) {
 		fmt.Sprintf("New lane will be created %v of lane '%v'.", leftRight, l.GetActiveLaneName()), 8, "")
 
 	addLaneDialog.SetDoneFunc(func(lane, _ string, success bool) {
+		l.hideDialog("addLane")
 		if success && len(lane) > 0 {
 			laneIndex := l.content.InsertNewLane(addToLeft, lane, initActiveLane)
-			l.content.Save()
-			l.nextMode = l.mode
-			l.nextLaneFocus = laneIndex
-			l.app.Stop()
+			l.save()
+			l.reloadLanes(laneIndex)
+			return
 		}
-		l.hideDialog("addLane")
 		l.setActiveIndex(initActiveLane)
 	})
 
@@ -446,10 +630,9 @@ func (l *Lanes) removeMergeLaneCommand(buttonIndex int, buttonLabel string, targ
 
 	if removeLaneOK {
 		l.content.RemoveLane(initActiveLane)
-		l.content.Save()
-		l.nextMode = l.mode
-		l.nextLaneFocus = initActiveLane
-		l.app.Stop()
+		l.save()
+		l.hideDialog("removeLane")
+		l.reloadLanes(initActiveLane)
 		return
 	}
 
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cklukas/todo/internal/model"
@@ -165,3 +166,143 @@ func TestInputCaptureDuringLaneDialog(t *testing.T) {
 		t.Fatalf("original input capture not called after dialog")
 	}
 }
+
+func TestAddTaskFromItemTemplate(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.SetItemTemplate(model.ItemTemplate{Name: "review", Title: "Code review: {}", Priority: 1, Color: "red", Note: "- [ ] tests"})
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+
+	l.CmdAddTask()
+	if app.GetFocus() != l.add.titleField {
+		t.Fatalf("focus not on the title")
+	}
+	l.add.GetFormItem(0).(*tview.DropDown).SetCurrentOption(1)
+	if l.add.main != "Code review: " || l.add.GetPriority() != 1 || l.add.GetColor() != "red" {
+		t.Fatalf("template not applied: %q %v %q", l.add.main, l.add.GetPriority(), l.add.GetColor())
+	}
+	l.add.titleField.SetText("Code review: PR 1")
+	l.add.done(l.add.main, l.add.secondary, true)
+
+	item := c.Items[0][0]
+	if item.Title != "Code review: PR 1" || item.Priority != 1 || item.Color != "red" || item.Note != "- [ ] tests" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestAddTaskQuickAdd(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+
+	l.CmdAddTask()
+	l.add.titleField.SetText("Fix login !1 #bug color:red >done")
+	if got := strings.TrimSpace(l.add.preview.GetText(false)); got != "priority 1, #bug, color red, lane Done" {
+		t.Fatalf("preview %q", got)
+	}
+	l.add.done(l.add.main, l.add.secondary, true)
+
+	if len(c.Items[0]) != 0 || len(c.Items[2]) != 1 {
+		t.Fatalf("task not added to the target lane: %+v", c.Items)
+	}
+	item := c.Items[2][0]
+	if item.Title != "Fix login" || item.Priority != 1 || item.Color != "red" || len(item.Tags) != 1 || item.Tags[0] != "bug" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestSaveConflictReloads(t *testing.T) {
+	fname := t.TempDir() + "/todo.json"
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	c.SetFileName(fname, "", "")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	// another instance changes the board before this one reloaded it
+	other := &model.ToDoContent{}
+	if err := other.ReadFromFile(fname); err != nil {
+		t.Fatal(err)
+	}
+	other.SetFileName(fname, "", "")
+	other.AddItem(1, 0, "other", "", 2, "", "")
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.CmdEditTask()
+	l.edit.done("renamed", "", true)
+	if name, _ := l.pages.GetFrontPage(); name != "error" {
+		t.Fatalf("conflict not reported, front page %q", name)
+	}
+	if c.Items[0][0].Title != "task" || len(c.Items[1]) != 1 {
+		t.Fatalf("board not reloaded: %+v", c.Items)
+	}
+}
+
+func TestSaveErrorShown(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/missing/todo.json", "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	l.CmdEditTask()
+	l.edit.done("renamed", "", true)
+	if name, _ := l.pages.GetFrontPage(); name != "error" {
+		t.Fatalf("save error not reported, front page %q", name)
+	}
+}
+
+func TestAddTaskAfterConflict(t *testing.T) {
+	fname := t.TempDir() + "/todo.json"
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "task", "", 2, "", "")
+	c.SetFileName(fname, "", "")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	// another instance adds a task before this one reloaded the board
+	other := &model.ToDoContent{}
+	if err := other.ReadFromFile(fname); err != nil {
+		t.Fatal(err)
+	}
+	other.SetFileName(fname, "", "")
+	other.AddItem(1, 0, "other", "", 2, "", "")
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.CmdAddTask()
+	l.add.main = "mine"
+	l.add.done(l.add.main, "", true)
+	if name, _ := l.pages.GetFrontPage(); name == "error" {
+		t.Fatal("error shown for a concurrent add")
+	}
+	saved := &model.ToDoContent{}
+	if err := saved.ReadFromFile(fname); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Items[0]) != 2 || len(saved.Items[1]) != 1 || saved.Items[1][0].Number != 2 {
+		t.Fatalf("unexpected board %+v", saved.Items)
+	}
+	for _, item := range saved.Items[0] {
+		if item.Title == "mine" && item.Number != 3 {
+			t.Fatalf("added task got number %v", item.Number)
+		}
+	}
+}
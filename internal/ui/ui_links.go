@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// linkNames are the texts of the link types.
+var linkNames = map[string]string{
+	model.LinkBlocks:    "blocks",
+	model.LinkBlockedBy: "blocked by",
+	model.LinkRelates:   "relates to",
+}
+
+// shorten returns at most max characters of a text, shortened texts end
+// with "…".
+func shorten(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// refTitle returns the title of a task preceded by its number, e.g.
+// "#42 Fix login".
+func refTitle(item *model.Item) string {
+	if ref := item.Ref(); ref != "" {
+		return ref + " " + item.Title
+	}
+	return item.Title
+}
+
+// setEditLinks lists the links of a task in the edit dialog and offers the
+// other tasks of the board in its link picker.
+func (l *Lanes) setEditLinks(item *model.Item) {
+	var targets []LinkTarget
+	for lane := range l.content.Items {
+		for _, other := range l.content.Items[lane] {
+			if other.Guid != item.Guid {
+				targets = append(targets, LinkTarget{Guid: other.Guid, Text: strings.TrimSpace(l.content.Titles[lane]) + ": " + refTitle(&other)})
+			}
+		}
+	}
+	l.edit.SetLinks(l.linkTexts(item), targets)
+}
+
+// linkTexts describes the links of a task to other tasks of the board and
+// its epic.
+func (l *Lanes) linkTexts(item *model.Item) []string {
+	var texts []string
+	links, items := l.content.LinkedItems(item)
+	for i, link := range links {
+		texts = append(texts, linkNames[link.Type]+": "+refTitle(&items[i]))
+	}
+	if lane, pos, found := l.content.FindItem(item.Parent); item.Parent != "" && found {
+		texts = append(texts, "epic: "+refTitle(&l.content.Items[lane][pos]))
+	}
+	return texts
+}
+
+// applyEditLink adds or removes the link chosen in the edit dialog. It
+// returns whether the links changed.
+func (l *Lanes) applyEditLink(guid string) (bool, error) {
+	linkType, other := l.edit.GetLink()
+	switch linkType {
+	case "":
+		return false, nil
+	case "remove":
+		return l.content.UnlinkItems(guid, other), nil
+	}
+	if err := l.content.LinkItems(guid, other, linkType); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// blockedMessage describes the blockers of a task, it is empty if the task
+// is not blocked.
+func (l *Lanes) blockedMessage(item *model.Item) string {
+	blockers := l.content.Blockers(item)
+	if len(blockers) == 0 {
+		return ""
+	}
+	titles := make([]string, len(blockers))
+	for i, b := range blockers {
+		titles[i] = "'" + b.Title + "'"
+	}
+	return fmt.Sprintf("'%v' is blocked by %v.", item.Title, strings.Join(titles, ", "))
+}
+
+// warnBlocked shows a warning if blocked tasks were moved from the given
+// lanes to a later lane.
+func (l *Lanes) warnBlocked(guids []string, from []int, to int) {
+	var msgs []string
+	for i, guid := range guids {
+		if from[i] >= to {
+			continue
+		}
+		if lane, idx, found := l.content.FindItem(guid); found {
+			if msg := l.blockedMessage(&l.content.Items[lane][idx]); msg != "" {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	if len(msgs) > 0 {
+		l.showError("lanes", strings.Join(msgs, "\n"))
+	}
+}
+
+// CmdLinks jumps to the task linked to the current one. If there are several
+// linked tasks, one is chosen in a dialog.
+func (l *Lanes) CmdLinks() {
+	lastIndex := l.saveActive()
+	item := l.currentItem()
+	if item == nil {
+		return
+	}
+	links, items := l.content.LinkedItems(item)
+	switch len(items) {
+	case 0:
+		msg := "The task is not linked to other tasks."
+		if keys := l.keymap.KeyText(ActionEdit); keys != "" {
+			msg = fmt.Sprintf("The task is not linked to other tasks, use %v to link it.", keys)
+		}
+		l.showError("lanes", msg)
+	case 1:
+		l.FocusItem(items[0].Guid)
+	default:
+		buttons := make([]string, len(items))
+		for i, link := range links {
+			buttons[i] = linkNames[link.Type] + ": " + shorten(items[i].Title, 30)
+		}
+		l.choiceModal("links", " Linked Tasks ", fmt.Sprintf("Go to a task linked to '%v':", item.Title), buttons, lastIndex, func(index int) {
+			l.FocusItem(items[index].Guid)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestLinkTasksInEditDialog(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "deploy", "", 2, "", "")
+	c.AddItem(1, 0, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	l.CmdEditTask()
+	if targets := l.edit.linkTargets; len(targets) != 1 || targets[0].Text != "Doing: #2 review" {
+		t.Fatalf("link targets %+v", targets)
+	}
+	l.edit.linkAction = 2 // blocked by
+	l.edit.done("deploy", "", true)
+	if blocked := c.BlockedItems(); !blocked[c.Items[0][0].Guid] {
+		t.Fatalf("task not blocked: %+v", c.Items[0][0].Links)
+	}
+	if main, _ := l.lanes[0].GetItemText(0); !strings.Contains(main, "⛓") {
+		t.Fatalf("blocked marker missing in %q", main)
+	}
+
+	l.CmdLinks()
+	if l.active != 1 {
+		t.Fatalf("linked task not focused, active lane %v", l.active)
+	}
+
+	// moving the blocked task to a later lane shows a warning
+	l.SetMoveHelpButton(tview.NewButton(""))
+	l.setActiveIndex(0)
+	l.moveSelectionRight()
+	if name, _ := l.pages.GetFrontPage(); name != "error" {
+		t.Fatalf("no warning shown, front page %q", name)
+	}
+}
@@ -4,11 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
 )
 
 func (l *Lanes) ListValidModesRemoveProvided(activeMode string) ([]string, int, error) {
@@ -26,32 +26,149 @@ func (l *Lanes) ListValidModesRemoveProvided(activeMode string) ([]string, int,
 }
 
 func (l *Lanes) ListValidModes(activeMode string) ([]string, int, error) {
-	activeModeIndex := 0
-	modes := make([]string, 0)
-	modes = append(modes, "main")
-	dirEntries, err := os.ReadDir(l.todoDirModes)
+	if l.modeLister != nil {
+		modes, err := l.modeLister()
+		if err != nil {
+			return []string{"main"}, 0, err
+		}
+		for i, m := range modes {
+			if m == activeMode {
+				return modes, i, nil
+			}
+		}
+		return modes, 0, nil
+	}
+	return model.ListValidModes(l.todoDirModes, activeMode)
+}
+
+// SetModeStore enables merging, removing and renaming modes.
+func (l *Lanes) SetModeStore(store *model.ModeStore) {
+	l.modeStore = store
+}
+
+// modeState is the cursor position within a mode, restored when switching
+// back to the mode.
+type modeState struct {
+	active  int
+	current []string
+}
+
+// SetModeOpener sets the function reading the board of a mode when switching
+// modes. New modes have to be created by the opener.
+func (l *Lanes) SetModeOpener(open func(mode string) (*model.ToDoContent, error)) {
+	l.modeOpener = open
+}
+
+// AddModeFunc registers a function which is called with the new mode and
+// board after switching modes, e.g. to monitor the board or to load the
+// settings of the mode. It is called with the active mode right away.
+// Returned errors are shown to the user.
+func (l *Lanes) AddModeFunc(f func(mode string, content *model.ToDoContent) error) {
+	l.modeFuncs = append(l.modeFuncs, f)
+	if err := f(l.mode, l.content); err != nil {
+		l.showError("lanes", err.Error())
+	}
+}
+
+// SwitchMode shows the board of another mode. The cursor positions of the
+// previous mode are kept for switching back. If guid is given, this task is
+// focused.
+func (l *Lanes) SwitchMode(mode, guid string) error {
+	if l.modeOpener == nil {
+		return errors.New("switching modes is not supported")
+	}
+	content, err := l.modeOpener(mode)
 	if err != nil {
-		return modes, 0, err
+		return err
+	}
+	if l.modeClosed {
+		delete(l.modeStates, l.mode)
+		l.modeClosed = false
+	} else {
+		l.save()
+		l.modeStates[l.mode] = l.cursorState()
 	}
 
-	idx := 0
-	for _, di := range dirEntries {
-		if di.Name() == "main" {
-			continue
+	l.mode = mode
+	l.content = content
+	l.marked = make(map[string]bool)
+	l.reloadLanes(0)
+	var errs []string
+	for _, f := range l.modeFuncs {
+		if err := f(mode, content); err != nil {
+			errs = append(errs, err.Error())
 		}
-		if _, err := os.Stat(filepath.Join(l.todoDirModes, di.Name(), "todo.json")); errors.Is(err, os.ErrNotExist) {
-			continue
+	}
+	if state, ok := l.modeStates[mode]; ok {
+		l.restoreCursor(state)
+	} else {
+		l.FocusDefaultLane()
+	}
+	if guid != "" {
+		l.FocusItem(guid)
+	}
+	if len(errs) > 0 {
+		l.showError("lanes", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// switchMode switches modes from a dialog, errors are shown to the user.
+func (l *Lanes) switchMode(mode, guid string) {
+	if err := l.SwitchMode(mode, guid); err != nil {
+		l.showError("lanes", fmt.Sprintf("Could not open mode '%v': %v", mode, err))
+	}
+}
+
+// cursorState returns the active lane and the selected task of each lane.
+func (l *Lanes) cursorState() modeState {
+	state := modeState{active: l.active, current: make([]string, len(l.lanes))}
+	for i, list := range l.lanes {
+		items := l.content.GetLaneItems(i)
+		if pos := l.itemIndex(i, list.GetCurrentItem()); pos >= 0 && pos < len(items) {
+			state.current[i] = items[pos].Guid
 		}
-		if di.IsDir() && !strings.HasPrefix(di.Name(), ".") {
-			modes = append(modes, di.Name())
-			idx++
-			if di.Name() == activeMode {
-				activeModeIndex = idx
-			}
+	}
+	return state
+}
+
+// restoreCursor selects the tasks and the lane saved by cursorState, as far
+// as they still exist.
+func (l *Lanes) restoreCursor(state modeState) {
+	for _, guid := range state.current {
+		if lane, idx, ok := l.content.FindItem(guid); ok && lane < len(l.lanes) {
+			l.lanes[lane].SetCurrentItem(l.listPos(lane, idx))
 		}
 	}
+	if state.active < len(l.lanes) {
+		l.setActiveIndex(state.active)
+	}
+}
 
-	return modes, activeModeIndex, nil
+// CloseMode leaves the current mode, which was merged, renamed or removed,
+// and continues with the next mode. The board of the closed mode is not
+// saved anymore. If the next mode can not be opened, the main mode is shown.
+func (l *Lanes) CloseMode(next string) {
+	l.modeClosed = true
+	if err := l.SwitchMode(next, ""); err == nil {
+		return
+	}
+	if err := l.SwitchMode("main", ""); err != nil {
+		l.app.Stop()
+		log.Fatal(err)
+	}
+}
+
+// SetTemplates sets the board templates offered for new modes.
+func (l *Lanes) SetTemplates(templates []*model.BoardTemplate) {
+	l.templates = templates
+	l.addMode.SetTemplates(model.TemplateNames(templates))
+}
+
+// SetModeLister replaces the scan of the local mode directory, e.g. with the
+// list of modes offered by a remote server.
+func (l *Lanes) SetModeLister(lister func() ([]string, error)) {
+	l.modeLister = lister
 }
 
 func (l *Lanes) CmdSelectModeDialog() {
@@ -64,9 +181,8 @@ func (l *Lanes) CmdSelectModeDialog() {
 	modePage := tview.NewModal().
 		SetTitle(" Mode Selection ").
 		// SetText("Modes allow separation of ToDo lists into categories.\n\nSelect an existing mode, 'Add' a new, or 'Merge/Remove' the current mode (tasks of all lanes are moved to the lanes of another mode or archived)").
-		SetText("Modes allow separation of ToDo lists into categories. Select an existing mode from the list:").
-		// AddButtons(append(modes, "Add", "Merge/Remove", "Cancel")).
-		AddButtons(append(modes, " Add...", "Cancel")).
+		SetText("Modes allow separation of ToDo lists into categories. Select an existing mode from the list, 'Add' a new one, or rename, merge or remove the current mode:").
+		AddButtons(append(modes, l.modeCommands()...)).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			l.cmdSelectModeDialogAction(buttonIndex, buttonLabel, lastIndex)
 		})
@@ -101,8 +217,97 @@ func (l *Lanes) CmdRemoveModeDialog() {
 	l.pages.ShowPage("removeMode")
 }
 
+// modeCommands returns the buttons of the mode dialog following the modes.
+func (l *Lanes) modeCommands() []string {
+	if l.modeStore == nil || l.mode == "main" {
+		return []string{" Add...", "Cancel"}
+	}
+	return []string{" Add...", "Rename...", "Merge/Remove", "Cancel"}
+}
+
 func (l *Lanes) cmdRemoveModeAction(buttonIndex int, buttonLabel string, lastIndex int) {
 	l.pages.RemovePage("removeMode")
+	if buttonIndex < 0 || buttonLabel == "Cancel" {
+		l.setActiveIndex(lastIndex)
+		return
+	}
+	l.save()
+	if buttonLabel == "Archive" {
+		if err := l.modeStore.Remove(l.mode); err != nil {
+			l.showError("lanes", err.Error())
+			return
+		}
+		l.CloseMode("main")
+		return
+	}
+
+	target, err := l.modeStore.Open(buttonLabel)
+	if err != nil {
+		l.showError("lanes", err.Error())
+		return
+	}
+	lanes := model.MatchLanes(l.content.Titles, target.Titles)
+	l.promptUnmatchedLanes(buttonLabel, target.Titles, lanes, 0, lastIndex)
+}
+
+// promptUnmatchedLanes asks for the target of each lane starting at index
+// from which has tasks but no lane of the same title in the target mode.
+// Afterwards the current mode is merged into the target mode.
+func (l *Lanes) promptUnmatchedLanes(target string, targetTitles []string, lanes []int, from, lastIndex int) {
+	for i := from; i < len(lanes); i++ {
+		count := len(l.content.GetLaneItems(i))
+		if lanes[i] != model.LaneNew || count == 0 {
+			continue
+		}
+		lane := i
+		prompt := tview.NewModal().
+			SetTitle(" Merge Mode ").
+			SetText(fmt.Sprintf("Lane '%v' (%v tasks) has no matching lane in mode '%v'. Select the target lane, add it as a new lane, or archive its tasks:", l.content.Titles[lane], count, target)).
+			AddButtons(append(append([]string{}, targetTitles...), "New lane", "Archive", "Cancel")).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				l.pages.RemovePage("mergeLane")
+				switch {
+				case buttonIndex < 0 || buttonLabel == "Cancel":
+					l.setActiveIndex(lastIndex)
+					return
+				case buttonLabel == "Archive":
+					lanes[lane] = model.LaneArchive
+				case buttonIndex < len(targetTitles):
+					lanes[lane] = buttonIndex
+				}
+				l.promptUnmatchedLanes(target, targetTitles, lanes, lane+1, lastIndex)
+			})
+		l.pages.AddPage("mergeLane", prompt, false, true)
+		return
+	}
+
+	if err := l.modeStore.Merge(l.mode, target, lanes); err != nil {
+		l.showError("lanes", err.Error())
+		return
+	}
+	l.CloseMode(target)
+}
+
+// CmdRenameModeDialog asks for the new name of the current mode.
+func (l *Lanes) CmdRenameModeDialog() {
+	lastIndex := l.saveActive()
+	dlg := NewModalInputMode("Rename Mode", l.todoDirModes)
+	dlg.SetDoneFunc(func(text, _ string, success bool) {
+		l.hideDialog("renameMode")
+		l.pages.RemovePage("renameMode")
+		l.setActiveIndex(lastIndex)
+		if !success || len(text) == 0 || text == l.mode {
+			return
+		}
+		l.save()
+		if err := l.modeStore.Rename(l.mode, text); err != nil {
+			l.showError("lanes", err.Error())
+			return
+		}
+		l.CloseMode(text)
+	})
+	l.pages.AddPage("renameMode", dlg, false, true)
+	l.showDialog("renameMode", dlg)
 }
 
 func (l *Lanes) cmdSelectModeDialogAction(buttonIndex int, buttonLabel string, lastIndex int) {
@@ -116,11 +321,16 @@ func (l *Lanes) cmdSelectModeDialogAction(buttonIndex int, buttonLabel string, l
 			l.pages.HidePage("mode")
 			l.CmdRemoveModeDialog()
 			return
+		case "Rename...":
+			l.pages.HidePage("mode")
+			l.CmdRenameModeDialog()
+			return
 		case "Cancel":
 			// empty
 		default:
-			l.nextMode = buttonLabel
-			l.app.Stop()
+			l.pages.HidePage("mode")
+			l.switchMode(buttonLabel, "")
+			return
 		}
 	}
 	l.pages.HidePage("mode")
@@ -131,3 +341,100 @@ func (l *Lanes) CmdAddMode() {
 	l.saveActive()
 	l.pages.ShowPage("addMode")
 }
+
+// CmdMoveToMode moves the current task to a lane of another mode.
+func (l *Lanes) CmdMoveToMode() {
+	l.transferTaskDialog(false)
+}
+
+// CmdCopyToMode copies the current task to a lane of another mode.
+func (l *Lanes) CmdCopyToMode() {
+	l.transferTaskDialog(true)
+}
+
+// transferTaskDialog asks for the target mode and lane of the current task,
+// which is then moved or copied.
+func (l *Lanes) transferTaskDialog(copy bool) {
+	item := l.currentItem()
+	if item == nil {
+		return
+	}
+	lastIndex := l.saveActive()
+	l.transferTasksDialog([]model.Item{*item}, fmt.Sprintf("task '%v'", item.Title), copy, lastIndex)
+}
+
+// transferTasksDialog asks for the target mode and lane of the tasks, which
+// are then moved or copied.
+func (l *Lanes) transferTasksDialog(items []model.Item, description string, copy bool, lastIndex int) {
+	if l.modeStore == nil {
+		l.showError("lanes", "Tasks can only be moved between local modes.")
+		return
+	}
+	modes, _, err := l.ListValidModesRemoveProvided(l.mode)
+	if err != nil {
+		l.showError("lanes", err.Error())
+		return
+	}
+	if len(modes) == 0 {
+		l.showError("lanes", "There is no other mode.")
+		return
+	}
+	verb, title := "Move", " Move to Mode "
+	if copy {
+		verb, title = "Copy", " Copy to Mode "
+	}
+
+	modePage := tview.NewModal().
+		SetTitle(title).
+		SetText(fmt.Sprintf("%v %v to mode:", verb, description)).
+		AddButtons(append(modes, "Cancel")).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("transferMode")
+			if buttonIndex < 0 || buttonLabel == "Cancel" {
+				l.setActiveIndex(lastIndex)
+				return
+			}
+			target, err := l.modeStore.Open(buttonLabel)
+			if err != nil {
+				l.showError("lanes", err.Error())
+				return
+			}
+			l.transferLaneDialog(items, buttonLabel, target.Titles, copy, title, lastIndex)
+		})
+	l.pages.RemovePage("transferMode")
+	l.pages.AddPage("transferMode", modePage, false, true)
+}
+
+// transferLaneDialog asks for the lane of the target mode and moves or
+// copies the tasks, see model.ModeStore.TransferItems.
+func (l *Lanes) transferLaneDialog(items []model.Item, target string, titles []string, copy bool, title string, lastIndex int) {
+	lanePage := tview.NewModal().
+		SetTitle(title).
+		SetText(fmt.Sprintf("Lane of mode '%v':", target)).
+		AddButtons(append(append([]string{}, titles...), "Cancel")).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("transferLane")
+			l.setActiveIndex(lastIndex)
+			if buttonIndex < 0 || buttonIndex >= len(titles) {
+				return
+			}
+			if err := l.modeStore.TransferItems(items, l.mode, target, buttonIndex, copy); err != nil {
+				l.showError("lanes", err.Error())
+				return
+			}
+			for _, item := range items {
+				delete(l.marked, item.Guid)
+			}
+			if !copy {
+				// the moved tasks were removed from the file of this mode
+				if err := l.content.Read(); err != nil {
+					l.showError("lanes", err.Error())
+					return
+				}
+				l.RedrawAllLanes()
+				return
+			}
+			l.redrawLanes()
+		})
+	l.pages.AddPage("transferLane", lanePage, false, true)
+}
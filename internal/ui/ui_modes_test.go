@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestSwitchModeKeepsCursor(t *testing.T) {
+	store := model.NewModeStore(t.TempDir(), nil)
+	main, err := store.Open("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	main.AddItem(1, 0, "first", "", 2, "", "")
+	main.AddItem(1, 1, "second", "", 2, "", "")
+	if err := main.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLanes(main, tview.NewApplication(), "main", t.TempDir(), "")
+	opened := map[string]*model.ToDoContent{"main": main}
+	l.SetModeOpener(func(mode string) (*model.ToDoContent, error) {
+		c, err := store.Open(mode)
+		if err != nil {
+			return nil, err
+		}
+		if mode == "work" {
+			c.InsertNewLane(false, "Review", 2)
+		}
+		opened[mode] = c
+		return c, c.Save()
+	})
+	var modes []string
+	l.AddModeFunc(func(mode string, _ *model.ToDoContent) error {
+		modes = append(modes, mode)
+		return nil
+	})
+	l.RedrawAllLanes()
+	l.FocusItem(main.Items[1][1].Guid)
+
+	if err := l.SwitchMode("work", ""); err != nil {
+		t.Fatal(err)
+	}
+	if l.Mode() != "work" || l.Content() != opened["work"] || len(l.Lists()) != 4 || l.ActiveIndex() != 0 {
+		t.Fatalf("mode %v with %v lanes, active lane %v", l.Mode(), len(l.Lists()), l.ActiveIndex())
+	}
+
+	if err := l.SwitchMode("main", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Lists()) != 3 || l.ActiveIndex() != 1 || l.lanes[1].GetCurrentItem() != 1 {
+		t.Fatalf("cursor not restored: lane %v item %v", l.ActiveIndex(), l.lanes[1].GetCurrentItem())
+	}
+	if len(modes) != 3 || modes[1] != "work" || modes[2] != "main" {
+		t.Fatalf("mode funcs called with %v", modes)
+	}
+}
+
+func TestReloadLanes(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	l := NewLanes(c, tview.NewApplication(), "main", t.TempDir(), "")
+
+	c.InsertNewLane(false, "Review", 2)
+	l.reloadLanes(3)
+	if len(l.Lists()) != 4 || l.ActiveIndex() != 3 {
+		t.Fatalf("%v lanes, active lane %v", len(l.Lists()), l.ActiveIndex())
+	}
+
+	c.RemoveLane(3)
+	l.reloadLanes(3)
+	if len(l.Lists()) != 3 || l.ActiveIndex() != 2 {
+		t.Fatalf("%v lanes, active lane %v", len(l.Lists()), l.ActiveIndex())
+	}
+}
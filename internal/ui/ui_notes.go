@@ -9,18 +9,43 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/cklukas/todo/internal/util"
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/cklukas/todo/internal/util"
 )
 
 func (l *Lanes) CmdAddTask() {
+	l.addBelow = false
+	l.showAddTask()
+}
+
+// CmdAddTaskBelow adds a task below the current one instead of above.
+func (l *Lanes) CmdAddTaskBelow() {
+	l.addBelow = true
+	l.showAddTask()
+}
+
+func (l *Lanes) showAddTask() {
 	l.saveActive()
 	now := time.Now()
 	l.add.ClearExtras()
-	l.add.SetPriority(2)
+	l.add.SetPriority(l.settings.Priority())
 	l.add.SetDue("")
 	l.add.SetLaneColor(l.content.GetLaneColor(l.active))
 	l.add.SetColor("")
+	l.content.Lock()
+	l.add.SetItemTemplates(l.content.ItemTemplates)
+	l.add.SetQuickAdd(append([]string{}, l.content.Titles...))
+	var assignees []string
+	if l.filter == filterMine {
+		// new tasks stay visible in the filtered lanes
+		assignees = []string{l.userName}
+	}
+	l.add.SetEstimate(0, l.content.EstimateUnit())
+	l.add.SetAssignees(assignees, l.content.KnownMembers())
+	l.content.Unlock()
 	l.add.SetValue("", fmt.Sprintf("created: %v", now.Format(dueLayout())), "")
 	l.showDialog("add", l.add)
 }
@@ -41,30 +66,189 @@ func (l *Lanes) CmdEditTask() {
 		l.edit.SetInfo(item.UserName, createdStr, updatedBy, updatedStr)
 		l.edit.SetLaneColor(l.content.GetLaneColor(l.active))
 		l.edit.SetColor(item.Color)
+		l.edit.SetEstimate(item.Estimate, l.content.EstimateUnit())
+		l.edit.SetAssignees(item.Assignees, l.content.KnownMembers())
+		if item.Waiting != nil {
+			l.edit.SetWaiting(item.Waiting.On, isoToLocal(item.Waiting.FollowUp))
+		} else {
+			l.edit.SetWaiting("", "")
+		}
+		l.setEditLinks(item)
+		l.edit.SetEpic(item.Parent)
 		l.edit.SetValue(item.Title, item.Secondary, isoToLocal(item.Due))
 		l.showDialog("edit", l.edit)
 	}
 }
 
+// CmdEditNote edits the note of the current task with the built-in or an
+// external editor, depending on the settings.
 func (l *Lanes) CmdEditNote() {
+	if item := l.currentItem(); item != nil {
+		l.openNote(item.Guid, item.Note, item.Note)
+	}
+}
+
+// openNote edits the note of a task, starting with text. The note was base
+// when editing started; if it changed meanwhile, storeNote offers to merge.
+func (l *Lanes) openNote(guid, base, text string) {
+	if l.settings.BuiltinNoteEditor() {
+		l.editNoteBuiltin(guid, base, text)
+		return
+	}
+	var note string
+	var ok bool
+	edit := func() { note, ok = l.editNote(text) }
 	if runtime.GOOS == "windows" {
 		l.pages.ShowPage("wait")
 		l.app.ForceDraw()
-		l.editNote()
+		edit()
 		l.pages.HidePage("wait")
 	} else {
-		if !l.app.Suspend(l.editNote) {
+		if !l.app.Suspend(edit) {
 			l.app.Stop()
 			log.Fatal("internal suspend error")
 		}
 	}
-	l.content.Save()
+	if ok {
+		l.storeNote(guid, base, note)
+	}
+}
+
+// editNoteBuiltin edits the note of a task with the built-in editor.
+func (l *Lanes) editNoteBuiltin(guid, base, text string) {
+	title := "Note"
+	l.content.Lock()
+	if lane, pos, found := l.content.FindItem(guid); found {
+		title = "Note: " + tview.Escape(l.content.Items[lane][pos].Title)
+	}
+	l.content.Unlock()
+
+	editor := NewNoteEditor(title)
+	editor.applyTheme(l.theme)
+	editor.SetNote(text)
+	closeEditor := func() {
+		l.hideDialog("noteEditor")
+		l.pages.RemovePage("noteEditor")
+	}
+	editor.SetDoneFunc(func(note string, success bool) {
+		if success {
+			closeEditor()
+			l.storeNote(guid, base, note)
+			return
+		}
+		if !editor.Modified() {
+			closeEditor()
+			return
+		}
+		confirm := tview.NewModal().
+			SetText("Discard the changes of the note?").
+			AddButtons([]string{"Discard", "Edit"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				l.pages.RemovePage("noteDiscard")
+				if buttonLabel == "Discard" {
+					closeEditor()
+					return
+				}
+				l.app.SetFocus(editor)
+			})
+		l.pages.AddPage("noteDiscard", confirm, false, true)
+		l.app.SetFocus(confirm)
+	})
+	l.pages.RemovePage("noteEditor")
+	l.pages.AddPage("noteEditor", modal(editor, 0, 0), false, true)
+	l.showDialog("noteEditor", editor)
+}
+
+// storeNote saves the note of a task, edited starting from base. If the note
+// was changed meanwhile, e.g. by another instance, the user chooses to merge
+// both versions, to overwrite the other one, to edit the note again or to
+// discard the own changes.
+func (l *Lanes) storeNote(guid, base, text string) {
+	if text == base {
+		return
+	}
+	l.content.Lock()
+	lane, pos, found := l.content.FindItem(guid)
+	if !found {
+		l.content.Unlock()
+		l.showError("lanes", "The task was removed while its note was edited, the note was not saved.")
+		return
+	}
+	item := &l.content.Items[lane][pos]
+	other, otherName := item.Note, item.UpdatedByName
+	if other != base && other != text {
+		l.content.Unlock()
+		l.noteConflictDialog(guid, base, text, other, otherName)
+		return
+	}
+	item.Note = text
+	item.LastUpdate = time.Now().UTC().Format(time.RFC3339)
+	if usr, errU := user.Current(); errU == nil {
+		item.UpdatedByName = usr.Username
+	}
+	l.content.Unlock()
+	l.save()
+	l.redrawLane(lane, l.currentIndex(lane))
+}
+
+// noteConflictDialog asks how to save a note which was changed by someone
+// else while it was edited.
+func (l *Lanes) noteConflictDialog(guid, base, text, other, otherName string) {
+	buttons := []string{"Merge", "Overwrite", "Edit again", "Discard mine"}
+	msg := "The note was changed by someone else while you edited it."
+	if otherName != "" {
+		msg = fmt.Sprintf("The note was changed by %v while you edited it.", otherName)
+	}
+	m := tview.NewModal().
+		SetTitle(" Note Changed ").
+		SetText(msg).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("noteConflict")
+			l.setActive()
+			switch buttonLabel {
+			case "Merge":
+				merged, conflict := model.MergeText(base, text, other)
+				if conflict {
+					// let the user resolve the conflict markers
+					l.openNote(guid, other, merged)
+				} else {
+					l.storeNote(guid, other, merged)
+				}
+			case "Overwrite":
+				l.storeNote(guid, other, text)
+			case "Discard mine":
+			default:
+				l.openNote(guid, other, text)
+			}
+		})
+	l.pages.AddPage("noteConflict", m, false, true)
+	l.app.SetFocus(m)
+}
+
+// CmdDeleteTask deletes the current task, after confirmation unless
+// disabled in the settings.
+func (l *Lanes) CmdDeleteTask() {
+	if l.settings.ConfirmDeleteEnabled() {
+		l.pages.ShowPage("delete")
+		return
+	}
+	if l.currentItem() != nil {
+		l.deleteCurrentTask()
+	}
+}
+
+func (l *Lanes) deleteCurrentTask() {
+	item := l.currentIndex(l.active)
+	l.content.DelItem(l.active, item)
+	l.redrawLane(l.active, item)
+	l.save()
 }
 
 func (l *Lanes) CmdArchiveNote() {
 	if len(l.lanes) > 0 {
 		ll := (*l.lanes[l.active]).GetItemCount()
-		if ll > 0 {
+		if ll > 0 && !l.archiveEpic() {
 			l.saveActive()
 			l.pages.ShowPage("archive")
 		}
@@ -77,23 +261,23 @@ func (l *Lanes) CmdSelectNote() {
 }
 
 func (l *Lanes) up() {
-	currentPos := l.lanes[l.active].GetCurrentItem()
-	newPos := util.NormPos(currentPos-1, l.lanes[l.active].GetItemCount())
+	currentPos := l.currentIndex(l.active)
+	newPos := l.itemIndex(l.active, util.NormPos(l.lanes[l.active].GetCurrentItem()-1, l.lanes[l.active].GetItemCount()))
 	l.content.MoveItem(l.active, currentPos, l.active, newPos)
 	l.redrawLane(l.active, newPos)
 }
 
 func (l *Lanes) down() {
-	currentPos := l.lanes[l.active].GetCurrentItem()
-	newPos := util.NormPos(currentPos+1, l.lanes[l.active].GetItemCount())
+	currentPos := l.currentIndex(l.active)
+	newPos := l.itemIndex(l.active, util.NormPos(l.lanes[l.active].GetCurrentItem()+1, l.lanes[l.active].GetItemCount()))
 	l.content.MoveItem(l.active, currentPos, l.active, newPos)
 	l.redrawLane(l.active, newPos)
 }
 
 func (l *Lanes) moveSelectionLeft() {
-	currentPos := l.lanes[l.active].GetCurrentItem()
+	currentPos := l.currentIndex(l.active)
 	newLane := util.NormPos(l.active-1, len(l.lanes))
-	newPos := l.lanes[newLane].GetCurrentItem()
+	newPos := l.currentIndex(newLane)
 	l.content.MoveItem(l.active, currentPos, newLane, newPos)
 	l.redrawLane(l.active, currentPos)
 	l.redrawLane(newLane, newPos)
@@ -103,31 +287,31 @@ func (l *Lanes) moveSelectionLeft() {
 }
 
 func (l *Lanes) moveSelectionRight() {
-	currentPos := l.lanes[l.active].GetCurrentItem()
+	currentPos := l.currentIndex(l.active)
 	newLane := util.NormPos(l.active+1, len(l.lanes))
-	newPos := l.lanes[newLane].GetCurrentItem()
+	newPos := l.currentIndex(newLane)
+	guid, from := l.content.Items[l.active][currentPos].Guid, l.active
 	l.content.MoveItem(l.active, currentPos, newLane, newPos)
 	l.redrawLane(l.active, currentPos)
 	l.redrawLane(newLane, newPos)
 	l.selected()
 	l.incActive()
 	l.selected()
+	l.warnBlocked([]string{guid}, []int{from}, newLane)
 }
 
 func (l *Lanes) decActive() {
 	l.lanes[l.active].SetSelectedStyle(tcell.StyleDefault)
 	l.active--
 	l.setActive()
-	l.lanes[l.active].SetSelectedBackgroundColor(tcell.ColorLightBlue)
-	l.lanes[l.active].SetSelectedTextColor(tcell.ColorBlack)
+	l.highlight(l.lanes[l.active])
 }
 
 func (l *Lanes) incActive() {
 	l.lanes[l.active].SetSelectedStyle(tcell.StyleDefault)
 	l.active++
 	l.setActive()
-	l.lanes[l.active].SetSelectedBackgroundColor(tcell.ColorLightBlue)
-	l.lanes[l.active].SetSelectedTextColor(tcell.ColorBlack)
+	l.highlight(l.lanes[l.active])
 }
 
 func (l *Lanes) selected() {
@@ -139,100 +323,103 @@ func (l *Lanes) selected() {
 		}
 	}
 	if l.inselect || selectDisable {
-		l.lanes[l.active].SetSelectedBackgroundColor(tcell.ColorWhite)
-		l.lanes[l.active].SetSelectedTextColor(tcell.ColorBlack)
+		l.highlight(l.lanes[l.active])
 	} else {
-		l.lanes[l.active].SetSelectedBackgroundColor(tcell.ColorNavy)
-		l.lanes[l.active].SetSelectedTextColor(tcell.ColorWhite)
+		l.lanes[l.active].SetSelectedBackgroundColor(color(l.theme.Marked))
+		l.lanes[l.active].SetSelectedTextColor(color(l.theme.MarkedText))
 	}
 	l.inselect = !l.inselect
 	if selectDisable {
 		l.inselect = false
 	}
 	if l.inselect {
-		l.bMoveHelp.SetLabel("[blue::-]↔ ↕ [black::b]Use Arrow Keys to Move Task")
+		l.bMoveHelp.SetLabel(l.moveHelpLabel())
 	} else {
-		l.bMoveHelp.SetLabel("")
+		l.updateTimer(time.Now())
 	}
 }
 
-func (l *Lanes) editNote() {
-	item := l.currentItem()
-	if item != nil {
-		tmp, err := os.CreateTemp("", "todo_temp_note_")
-		if err == nil {
-			name := tmp.Name()
-			defer os.Remove(name)
-			tmp.Write([]byte(item.Note))
-			tmp.Close()
-			var cmd *exec.Cmd
-			visualEditorCmd := os.Getenv("VISUAL")
+// editNote edits a note with an external editor. It returns the edited text
+// and whether the editor was run successfully.
+func (l *Lanes) editNote(note string) (string, bool) {
+	result, ok := "", false
+	tmp, err := os.CreateTemp("", "todo_temp_note_")
+	if err == nil {
+		name := tmp.Name()
+		defer os.Remove(name)
+		tmp.Write([]byte(note))
+		tmp.Close()
+		var cmd *exec.Cmd
+		visualEditorCmd := os.Getenv("VISUAL")
 
-			if runtime.GOOS == "windows" || len(visualEditorCmd) > 0 {
-				editorCmd := os.Getenv("EDITOR")
-				if len(visualEditorCmd) > 0 {
-					editorCmd = visualEditorCmd
+		if runtime.GOOS == "windows" || (len(visualEditorCmd) > 0 && len(l.settings.Editor) == 0) {
+			editorCmd := os.Getenv("EDITOR")
+			if len(l.settings.Editor) > 0 {
+				editorCmd = l.settings.Editor
+			} else if len(visualEditorCmd) > 0 {
+				editorCmd = visualEditorCmd
 
-				} else {
-					if len(editorCmd) == 0 {
-						editorCmd = "notepad"
-					}
+			} else {
+				if len(editorCmd) == 0 {
+					editorCmd = "notepad"
 				}
+			}
 
-				words, err := util.Split(editorCmd)
+			words, err := util.Split(editorCmd)
+			if err != nil {
+				l.app.Stop()
+				log.Fatal(err)
+			}
+			words = append(words, name)
+			cmd = exec.Command(words[0], words[1:]...)
+			err = cmd.Start()
+			if err != nil {
+				l.app.Stop()
+				log.Fatal(err)
+			}
+
+			l.app.Suspend(func() {
+				err = cmd.Wait()
 				if err != nil {
 					l.app.Stop()
 					log.Fatal(err)
 				}
-				words = append(words, name)
-				cmd = exec.Command(words[0], words[1:]...)
-				err = cmd.Start()
+
+				note_raw, err := os.ReadFile(name)
+				if err == nil {
+					result, ok = string(note_raw), true
+				}
+			})
+		} else {
+			editorCmd := l.settings.Editor
+			if len(editorCmd) == 0 {
+				editorCmd = os.Getenv("EDITOR")
+			}
+			if len(editorCmd) == 0 {
+				editorCmd = "vim"
+			}
+
+			words, err := util.Split(editorCmd)
+			if err != nil {
+				l.app.Stop()
+				log.Fatal(err)
+			}
+			words = append(words, name)
+			cmd = exec.Command(words[0], words[1:]...)
+			cmd.Stdin = os.Stdin
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			err = cmd.Run()
+			if err == nil {
+				note_raw, err := os.ReadFile(name)
 				if err != nil {
 					l.app.Stop()
 					log.Fatal(err)
 				}
 
-				l.app.Suspend(func() {
-					err = cmd.Wait()
-					if err != nil {
-						l.app.Stop()
-						log.Fatal(err)
-					}
-
-					note_raw, err := os.ReadFile(name)
-					if err == nil {
-						item.Note = string(note_raw)
-						item.LastUpdate = time.Now().UTC().Format(time.RFC3339)
-						if usr, errU := user.Current(); errU == nil {
-							item.UpdatedByName = usr.Username
-						}
-					}
-				})
-			} else {
-				editorCmd := os.Getenv("EDITOR")
-				if len(editorCmd) == 0 {
-					editorCmd = "vim"
-				}
-
-				cmd = exec.Command(editorCmd, name)
-				cmd.Stdin = os.Stdin
-				cmd.Stdout = os.Stdout
-				cmd.Stderr = os.Stderr
-				err = cmd.Run()
-				if err == nil {
-					note_raw, err := os.ReadFile(name)
-					if err != nil {
-						l.app.Stop()
-						log.Fatal(err)
-					}
-
-					item.Note = string(note_raw)
-					item.LastUpdate = time.Now().UTC().Format(time.RFC3339)
-					if usr, errU := user.Current(); errU == nil {
-						item.UpdatedByName = usr.Username
-					}
-				}
+				result, ok = string(note_raw), true
 			}
 		}
 	}
+	return result, ok
 }
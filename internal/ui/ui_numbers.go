@@ -0,0 +1,30 @@
+package ui
+
+import "fmt"
+
+// CmdGoto asks for the number or GUID of a task and focuses it.
+func (l *Lanes) CmdGoto() {
+	lastIndex := l.saveActive()
+	dlg := NewModalInputText("Go to Task", "Task:", "The number of a task, e.g. 42 or #42, or its GUID.", 7, "")
+	dlg.SetDoneFunc(func(text, _ string, success bool) {
+		l.hideDialog("goto")
+		l.pages.RemovePage("goto")
+		l.setActiveIndex(lastIndex)
+		if success && text != "" {
+			l.gotoRef(text)
+		}
+	})
+	l.pages.AddPage("goto", dlg, false, true)
+	l.showDialog("goto", dlg)
+}
+
+// gotoRef focuses the task with the given reference, see
+// model.ToDoContent.FindRef. It returns false if there is no such task.
+func (l *Lanes) gotoRef(ref string) bool {
+	guid, err := l.content.ResolveRef(ref)
+	if err == nil && l.FocusItem(guid) {
+		return true
+	}
+	l.showError("lanes", fmt.Sprintf("Task '%v' not found.", ref))
+	return false
+}
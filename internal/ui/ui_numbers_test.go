@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/config"
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestTaskNumbers(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "epic", "", 2, "", "")
+	c.AddItem(1, 0, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+	if main, _ := l.lanes[1].GetItemText(0); strings.Contains(main, "#2") {
+		t.Fatalf("number shown without setting: %q", main)
+	}
+	l.ApplySettings(config.Settings{ShowNumbers: true})
+	if main, _ := l.lanes[1].GetItemText(0); !strings.Contains(main, "#2[-] review") {
+		t.Fatalf("number missing in %q", main)
+	}
+
+	// the quick-add syntax refers to other tasks by number
+	l.CmdAddTask()
+	l.add.main = "tests epic:1 blocks:#2"
+	l.add.done(l.add.main, "", true)
+	added := c.Items[0][0] // added above the epic
+	if added.Title != "tests" || added.Number != 3 || added.Parent != c.Items[0][1].Guid || len(added.Links) != 1 || added.Links[0].Guid != c.Items[1][0].Guid {
+		t.Fatalf("unexpected task %+v", added)
+	}
+	l.CmdAddTask()
+	l.add.main = "more epic:9"
+	l.add.done(l.add.main, "", true)
+	if name, _ := l.pages.GetFrontPage(); name != "error" || len(c.Items[0]) != 2 {
+		t.Fatalf("task with unknown epic added, front page %q", name)
+	}
+	l.pages.RemovePage("error")
+	l.hideDialog("add")
+
+	if !l.gotoRef("#2") || l.active != 1 {
+		t.Fatalf("task not focused, active lane %v", l.active)
+	}
+	if l.gotoRef("42") {
+		t.Fatal("unknown number found")
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "login", "", 2, "", "")
+	c.AddItem(0, 1, "logout", "", 2, "", "")
+	c.AddItem(1, 0, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	search := func(text string) {
+		l.CmdSearch()
+		dlg, ok := l.activeDialog.(*ModalInput)
+		if !ok {
+			t.Fatal("search dialog not shown")
+		}
+		dlg.done(text, "", true)
+	}
+	search("#2")
+	if n, m := l.lanes[0].GetItemCount(), l.lanes[1].GetItemCount(); n != 1 || m != 0 || !strings.Contains(l.lanes[0].GetTitle(), "(search)") {
+		t.Fatalf("%v and %v tasks shown for a number", n, m)
+	}
+	if item := l.currentItem(); item == nil || item.Title != "logout" {
+		t.Fatalf("unexpected task %+v", item)
+	}
+	search("LOG")
+	if n := l.lanes[0].GetItemCount(); n != 2 {
+		t.Fatalf("%v tasks shown for a text", n)
+	}
+	search("")
+	if l.filter != "" || l.lanes[1].GetItemCount() != 1 {
+		t.Fatalf("filter %q kept", l.filter)
+	}
+}
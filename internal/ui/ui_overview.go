@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// overviewLine is an entry of the overview list, either the summary of a
+// mode (empty guid) or one of its tasks.
+type overviewLine struct {
+	main      string
+	secondary string
+	mode      string
+	guid      string
+}
+
+// laneCounts describes the number of tasks and estimates per lane, e.g.
+// "To Do 3 (8pt), Done 1".
+func laneCounts(s model.ModeSummary) string {
+	parts := make([]string, len(s.Lanes))
+	for i, lane := range s.Lanes {
+		parts[i] = fmt.Sprintf("%v %v", lane.Title, lane.Count)
+		if lane.Estimate > 0 {
+			parts[i] += " (" + model.FormatEstimate(lane.Estimate, s.EstimateUnit) + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// overviewLines returns the lines of the overview. Each mode is followed by
+// its overdue tasks, the tasks due today, the waiting tasks to follow up and
+// the tasks with high priority; tasks matching several of these are listed
+// once.
+func overviewLines(summaries []model.ModeSummary, t *Theme) []overviewLine {
+	var lines []overviewLine
+	for _, s := range summaries {
+		head := overviewLine{main: tag(t.Title) + tview.Escape(s.Mode), mode: s.Mode}
+		if s.Err != nil {
+			head.secondary = tview.Escape(s.Err.Error())
+			lines = append(lines, head)
+			continue
+		}
+		head.secondary = laneCounts(s)
+		if len(s.Overdue) > 0 || len(s.DueToday) > 0 {
+			head.secondary += fmt.Sprintf(" - %v overdue, %v due today", len(s.Overdue), len(s.DueToday))
+		}
+		lines = append(lines, head)
+
+		seen := make(map[string]bool)
+		add := func(items []model.AgendaItem, marker, col string) {
+			for _, ai := range items {
+				if seen[ai.Item.Guid] {
+					continue
+				}
+				seen[ai.Item.Guid] = true
+				main := "  " + tview.Escape(ai.Item.Title)
+				if marker != "" {
+					main += " " + tag(col) + tview.Escape(marker)
+				}
+				secondary := "  " + tview.Escape(ai.LaneTitle)
+				if mark := model.PriorityMark(ai.Item.Priority); mark != "" {
+					secondary += " " + mark
+				}
+				lines = append(lines, overviewLine{main: main, secondary: secondary, mode: s.Mode, guid: ai.Item.Guid})
+			}
+		}
+		add(s.Overdue, "[overdue]", t.Overdue)
+		add(s.DueToday, "[due!]", t.Due)
+		add(s.FollowUp, "[follow up]", t.Due)
+		add(s.Top, "", "")
+	}
+	return lines
+}
+
+// FocusItem activates the lane of the task with the given GUID and selects
+// the task. It returns false if the task does not exist.
+func (l *Lanes) FocusItem(guid string) bool {
+	lane, idx, ok := l.content.FindItem(guid)
+	if !ok || lane >= len(l.lanes) {
+		return false
+	}
+	if !l.visible(&l.content.Items[lane][idx]) {
+		// the task is hidden by the filter
+		l.setFilter("")
+	}
+	l.setActiveIndex(lane)
+	l.lanes[lane].SetCurrentItem(l.listPos(lane, idx))
+	return true
+}
+
+// jumpTo shows a task or, for an empty guid, a mode.
+func (l *Lanes) jumpTo(mode, guid string) {
+	if mode != l.mode {
+		l.switchMode(mode, guid)
+		return
+	}
+	if guid != "" {
+		l.FocusItem(guid)
+	}
+}
+
+// CmdOverview shows the lane counts and the urgent tasks of all modes.
+// Enter jumps to the selected mode or task.
+func (l *Lanes) CmdOverview() {
+	lastIndex := l.saveActive()
+	if l.modeStore == nil {
+		l.showError("lanes", "The overview is only available for local modes.")
+		return
+	}
+	now := time.Now()
+	summaries, err := l.modeStore.Summaries(now)
+	if err != nil {
+		l.showError("lanes", err.Error())
+		return
+	}
+	for i, s := range summaries {
+		if s.Mode == l.mode {
+			// use the board shown, it may have changes not saved yet
+			l.content.Lock()
+			summaries[i] = model.Summarize(l.mode, l.content, now)
+			l.content.Unlock()
+		}
+	}
+
+	lines := overviewLines(summaries, l.theme)
+	list := tview.NewList()
+	for _, line := range lines {
+		list.AddItem(line.main, line.secondary, 0, nil)
+	}
+	list.SetBorder(true).SetTitle(" Overview - Enter: show, Esc: close ")
+	list.SetBackgroundColor(color(l.theme.Background))
+	l.highlight(list)
+
+	closeOverview := func() {
+		l.pages.RemovePage("overview")
+		l.setActiveIndex(lastIndex)
+	}
+	list.SetDoneFunc(closeOverview)
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		closeOverview()
+		l.jumpTo(lines[index].mode, lines[index].guid)
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if action, _ := l.keymap.Lookup([]string{keyName(event)}); action == ActionOverview || action == ActionQuit {
+			closeOverview()
+			return nil
+		}
+		return event
+	})
+
+	l.pages.RemovePage("overview")
+	l.pages.AddPage("overview", list, true, true)
+	l.app.SetFocus(list)
+}
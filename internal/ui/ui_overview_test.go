@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cklukas/todo/internal/model"
+	"github.com/rivo/tview"
+)
+
+func TestOverviewLines(t *testing.T) {
+	item := model.AgendaItem{Mode: "work", LaneTitle: "To Do", Item: model.Item{Title: "late", Guid: "1", Priority: 1}}
+	summaries := []model.ModeSummary{
+		{Mode: "main", Err: errors.New("data is encrypted")},
+		{Mode: "work", Lanes: []model.LaneCount{{Title: "To Do", Count: 1}}, Overdue: []model.AgendaItem{item}, Top: []model.AgendaItem{item}},
+	}
+
+	lines := overviewLines(summaries, DefaultTheme())
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %+v", lines)
+	}
+	if lines[0].mode != "main" || lines[0].guid != "" || lines[0].secondary != "data is encrypted" {
+		t.Fatalf("unexpected error line %+v", lines[0])
+	}
+	if lines[1].secondary != "To Do 1 - 1 overdue, 0 due today" {
+		t.Fatalf("unexpected summary %q", lines[1].secondary)
+	}
+	if lines[2].mode != "work" || lines[2].guid != "1" {
+		t.Fatalf("unexpected task line %+v", lines[2])
+	}
+}
+
+func TestFocusItem(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(1, 0, "task 1", "", 2, "", "")
+	c.AddItem(1, 1, "task 2", "", 2, "", "")
+	l := NewLanes(c, tview.NewApplication(), "main", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	if !l.FocusItem(c.Items[1][1].Guid) {
+		t.Fatalf("item not found")
+	}
+	if l.active != 1 || l.lanes[1].GetCurrentItem() != 1 {
+		t.Fatalf("focus at lane %v item %v", l.active, l.lanes[1].GetCurrentItem())
+	}
+	if l.FocusItem("unknown") {
+		t.Fatalf("unknown item found")
+	}
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/cklukas/todo/internal/util"
+)
+
+// pomodoro is a running focus session for a task. Focus phases and breaks
+// alternate until the session is stopped.
+type pomodoro struct {
+	guid    string
+	title   string
+	inBreak bool
+	end     time.Time
+}
+
+// pomodoroLabel returns the countdown of the running phase, shown instead of
+// the clock.
+func (l *Lanes) pomodoroLabel(now time.Time) string {
+	p := l.pomodoro
+	if p == nil {
+		return ""
+	}
+	left := p.end.Sub(now).Round(time.Second)
+	if left < 0 {
+		left = 0
+	}
+	phase := "Focus"
+	if p.inBreak {
+		phase = "Break"
+	}
+	return fmt.Sprintf("%v %02d:%02d", phase, int(left.Minutes()), int(left.Seconds())%60)
+}
+
+// updatePomodoro switches to the next phase when the running one is over. A
+// completed focus phase is counted on the task.
+func (l *Lanes) updatePomodoro(now time.Time) {
+	p := l.pomodoro
+	if p == nil || now.Before(p.end) {
+		return
+	}
+	if p.inBreak {
+		p.inBreak = false
+		p.end = now.Add(l.settings.PomodoroWorkTime())
+		l.notifyPomodoro("Break is over, focus on: " + p.title)
+		return
+	}
+	if lane, pos, found := l.content.FindItem(p.guid); found {
+		l.content.Items[lane][pos].Pomodoros++
+		l.save()
+		l.redrawLanes()
+	}
+	p.inBreak = true
+	p.end = now.Add(l.settings.PomodoroBreakTime())
+	l.notifyPomodoro("Pomodoro completed, take a break: " + p.title)
+}
+
+// notifyPomodoro runs the configured pomodoro command with the message as
+// last argument, or rings the terminal bell.
+func (l *Lanes) notifyPomodoro(message string) {
+	if l.settings.PomodoroCommand == "" {
+		l.app.SetAfterDrawFunc(func(screen tcell.Screen) {
+			l.app.SetAfterDrawFunc(nil)
+			screen.Beep()
+		})
+		return
+	}
+	words, err := util.Split(l.settings.PomodoroCommand)
+	if err != nil || len(words) == 0 {
+		return
+	}
+	cmd := exec.Command(words[0], append(words[1:], message)...)
+	if err := cmd.Start(); err == nil {
+		go cmd.Wait()
+	}
+}
+
+// CmdPomodoro starts a pomodoro session for the current task, or stops the
+// running session. If the "pomodoroLane" setting names a lane of the board,
+// the task is moved there.
+func (l *Lanes) CmdPomodoro() {
+	now := time.Now()
+	if l.pomodoro != nil {
+		l.pomodoro = nil
+		l.updateClock(now)
+		return
+	}
+	item := l.currentItem()
+	if item == nil || l.inselect {
+		return
+	}
+	guid, title := item.Guid, item.Title
+	if to := l.pomodoroLane(); to >= 0 && to != l.active {
+		l.content.MoveItem(l.active, l.currentIndex(l.active), to, 0)
+		l.save()
+		l.redrawLanes()
+		l.FocusItem(guid)
+	}
+	l.pomodoro = &pomodoro{guid: guid, title: title, end: now.Add(l.settings.PomodoroWorkTime())}
+	l.updateClock(now)
+}
+
+// pomodoroLane returns the lane configured for tasks in a pomodoro session,
+// or -1.
+func (l *Lanes) pomodoroLane() int {
+	if l.settings.PomodoroLane == "" {
+		return -1
+	}
+	for i, title := range l.content.Titles {
+		if strings.EqualFold(title, l.settings.PomodoroLane) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestPomodoro(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "deploy", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	app := tview.NewApplication().SetScreen(screen)
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.SetMoveHelpButton(tview.NewButton(""))
+	l.SetClock(tview.NewTextView())
+	l.settings.PomodoroLane = "doing"
+	l.settings.PomodoroWork = 20
+	l.RedrawAllLanes()
+
+	l.CmdPomodoro()
+	if len(c.Items[1]) != 1 || l.active != 1 || l.currentItem().Title != "deploy" {
+		t.Fatalf("task not moved to the pomodoro lane, active lane %v", l.active)
+	}
+	if text := l.clock.GetText(true); !strings.HasPrefix(text, "Focus 19:5") && text != "Focus 20:00" {
+		t.Fatalf("countdown %q", text)
+	}
+
+	// the end of the focus phase counts the pomodoro and starts the break
+	now := l.pomodoro.end
+	l.updatePomodoro(now)
+	if c.Items[1][0].Pomodoros != 1 || !l.pomodoro.inBreak || app.GetAfterDrawFunc() == nil {
+		t.Fatalf("pomodoro not completed: %+v", l.pomodoro)
+	}
+	if label := l.pomodoroLabel(now); label != "Break 05:00" {
+		t.Fatalf("break countdown %q", label)
+	}
+	now = l.pomodoro.end
+	l.updatePomodoro(now)
+	if l.pomodoro.inBreak || !l.pomodoro.end.Equal(now.Add(20*time.Minute)) || c.Items[1][0].Pomodoros != 1 {
+		t.Fatalf("focus phase not restarted: %+v", l.pomodoro)
+	}
+
+	// navigation is not blocked, the session continues for its task
+	l.decActive()
+	if l.pomodoro == nil {
+		t.Fatal("session stopped by navigation")
+	}
+	l.CmdPomodoro()
+	if l.pomodoro != nil || strings.HasPrefix(l.clock.GetText(true), "Focus") {
+		t.Fatal("session not stopped")
+	}
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cklukas/todo/internal/config"
+)
+
+// Settings returns the active settings.
+func (l *Lanes) Settings() config.Settings {
+	return l.settings
+}
+
+// SetSettingsChangedFunc sets the handler storing the values changed in the
+// settings dialog, either in the user settings or, if modeOnly is set, in
+// the settings of the current mode.
+func (l *Lanes) SetSettingsChangedFunc(handler func(values map[string]string, modeOnly bool) error) {
+	l.settingsChanged = handler
+}
+
+// ApplySettings activates the given settings. Invalid keymap or theme
+// settings are reported, the remaining settings are applied nevertheless.
+func (l *Lanes) ApplySettings(s config.Settings) error {
+	l.settings = s
+	SetDateFormat(s.DateFormat)
+	l.content.SetBackupDays(s.BackupDays)
+	l.content.SetEstimateUnit(s.EstimateUnit)
+	if !s.ClockEnabled() && l.clock != nil {
+		l.clock.SetText("")
+	}
+	l.setDetailPane(s.DetailPane, s.DetailPaneAt())
+
+	var errs []string
+	bindings := make(map[string][]string, len(s.Keymap.Bindings))
+	for action, keys := range s.Keymap.Bindings {
+		bindings[action] = keys
+	}
+	if km, err := NewKeymap(s.Keymap.Preset, bindings); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid keymap settings, using default keys: %v", err))
+		l.SetKeymap(DefaultKeymap())
+	} else {
+		l.SetKeymap(km)
+	}
+
+	theme := s.Theme
+	if theme == "" {
+		theme = DefaultThemeName
+	}
+	if err := l.ApplyTheme(theme); err != nil {
+		errs = append(errs, fmt.Sprintf("%v, using default theme", err))
+		l.ApplyTheme(DefaultThemeName)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// FocusDefaultLane activates the lane configured as "defaultLane".
+func (l *Lanes) FocusDefaultLane() {
+	if l.settings.DefaultLane == "" {
+		return
+	}
+	for i, title := range l.content.Titles {
+		if strings.EqualFold(title, l.settings.DefaultLane) {
+			l.setActiveIndex(i)
+			return
+		}
+	}
+}
+
+// CmdSettingsDialog shows the settings dialog. Saved changes are applied
+// immediately.
+func (l *Lanes) CmdSettingsDialog() {
+	lastIndex := l.saveActive()
+	themes := make([]string, len(l.themes))
+	for i, t := range l.themes {
+		themes[i] = t.Name
+	}
+	mode := l.mode
+	if mode == "main" {
+		mode = ""
+	}
+	dlg := NewSettingsModal(l.settings, l.content.Titles, themes, mode)
+	dlg.SetDoneFunc(func(values map[string]string, modeOnly, success bool) {
+		l.hideDialog("settings")
+		l.pages.RemovePage("settings")
+		l.setActiveIndex(lastIndex)
+		if !success || len(values) == 0 || l.settingsChanged == nil {
+			return
+		}
+		if err := l.settingsChanged(values, modeOnly); err != nil {
+			l.showError("lanes", err.Error())
+		}
+	})
+
+	l.pages.RemovePage("settings")
+	l.pages.AddPage("settings", dlg, false, true)
+	l.showDialog("settings", dlg)
+}
@@ -10,8 +10,8 @@ func (l *Lanes) CmdSortDialog() {
 		l.setActive()
 		if ok {
 			l.content.SetLaneSort(l.active, mode)
-			l.redrawLane(l.active, l.lanes[l.active].GetCurrentItem())
-			l.content.Save()
+			l.redrawLane(l.active, l.currentIndex(l.active))
+			l.save()
 		}
 	})
 	l.pages.AddPage("sort", modal(dlg, 0, 0), false, true)
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// Marks of the sprint chart: the actual work as bars and the guide, the even
+// burndown or the scope.
+const (
+	plotActual = '█'
+	plotGuide  = '·'
+)
+
+// sprintChart draws the burndown or, with burnup set, the burnup chart of a
+// sprint.
+type sprintChart struct {
+	*tview.Box
+	report model.SprintReport
+	burnup bool
+	theme  *Theme
+}
+
+func newSprintChart(report model.SprintReport, theme *Theme) *sprintChart {
+	c := &sprintChart{Box: tview.NewBox(), report: report, theme: theme}
+	c.updateTitle()
+	return c
+}
+
+func (c *sprintChart) updateTitle() {
+	kind := "Burndown"
+	if c.burnup {
+		kind = "Burnup"
+	}
+	c.SetTitle(fmt.Sprintf(" Sprint %v - %v ", c.report.Name, kind))
+}
+
+// plot returns the chart area of the given size as rows of runes, the top
+// row first. Each column is a day of the sprint; the burndown shows the
+// remaining work, the burnup the work done.
+func (c *sprintChart) plot(width, height int) [][]rune {
+	rows := make([][]rune, height)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(" ", width))
+	}
+	r := c.report
+	if r.Scope <= 0 || width <= 0 || height <= 0 {
+		return rows
+	}
+	level := func(v float64) int {
+		return int(v/r.Scope*float64(height-1) + 0.5)
+	}
+	for x := 0; x < width; x++ {
+		day := 0
+		if width > 1 {
+			day = x * (r.Length - 1) / (width - 1)
+		}
+		guide := r.Scope
+		if !c.burnup && r.Length > 1 {
+			guide = r.Scope * float64(r.Length-1-day) / float64(r.Length-1)
+		}
+		if day < len(r.Days) {
+			value := r.Days[day].Remaining
+			if c.burnup {
+				value = r.Days[day].Done
+			}
+			for y := 0; value > 0 && y <= level(value); y++ {
+				rows[height-1-y][x] = plotActual
+			}
+		}
+		if y := height - 1 - level(guide); rows[y][x] == ' ' {
+			rows[y][x] = plotGuide
+		}
+	}
+	return rows
+}
+
+// Draw draws the chart with the scale on the left and the dates of the
+// sprint below.
+func (c *sprintChart) Draw(screen tcell.Screen) {
+	c.Box.DrawForSubclass(screen, c)
+	x, y, width, height := c.GetInnerRect()
+	r := c.report
+	top := model.FormatEstimate(r.Scope, "")
+	labelWidth := len(top) + 1
+	if width <= labelWidth || height < 2 {
+		return
+	}
+	tview.Print(screen, top, x, y, labelWidth-1, tview.AlignRight, color(c.theme.SecondaryText))
+	tview.Print(screen, "0", x, y+height-2, labelWidth-1, tview.AlignRight, color(c.theme.SecondaryText))
+	tview.Print(screen, r.Start, x+labelWidth, y+height-1, width-labelWidth, tview.AlignLeft, color(c.theme.SecondaryText))
+	tview.Print(screen, r.End, x+labelWidth, y+height-1, width-labelWidth, tview.AlignRight, color(c.theme.SecondaryText))
+
+	actual := tcell.StyleDefault.Background(color(c.theme.Background)).Foreground(color(c.theme.Title))
+	guide := actual.Foreground(color(c.theme.SecondaryText))
+	for row, line := range c.plot(width-labelWidth, height-1) {
+		for col, ch := range line {
+			switch ch {
+			case plotActual:
+				screen.SetContent(x+labelWidth+col, y+row, ch, nil, actual)
+			case plotGuide:
+				screen.SetContent(x+labelWidth+col, y+row, ch, nil, guide)
+			}
+		}
+	}
+}
+
+// sprintSummary describes the progress of a sprint in one line.
+func sprintSummary(r model.SprintReport) string {
+	unit := r.Unit
+	if unit == "tasks" {
+		unit = " tasks"
+	}
+	text := fmt.Sprintf("%v to %v: scope %v, done %v, remaining %v", r.Start, r.End,
+		model.FormatEstimate(r.Scope, unit), model.FormatEstimate(r.Done, unit), model.FormatEstimate(r.Remaining, unit))
+	if r.Closed != "" {
+		text += ", closed " + r.Closed
+	}
+	return text
+}
+
+// CmdSprint shows the burndown chart of the running or the last sprint of
+// the mode; 'u' switches between burndown and burnup.
+func (l *Lanes) CmdSprint() {
+	lastIndex := l.saveActive()
+	l.content.Lock()
+	report, err := l.content.SprintReport("", time.Now())
+	l.content.Unlock()
+	if err != nil {
+		l.showError("lanes", fmt.Sprintf("%v, sprints are started with 'todo sprint start'", err))
+		return
+	}
+
+	chart := newSprintChart(report, l.theme)
+	chart.SetBorder(true)
+	chart.SetBackgroundColor(color(l.theme.Background))
+	summary := tview.NewTextView().SetText(sprintSummary(report) + "\nu: burnup/burndown, Esc: close")
+	summary.SetBackgroundColor(color(l.theme.Background))
+	summary.SetTextColor(color(l.theme.Text))
+	page := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(chart, 0, 1, true).
+		AddItem(summary, 2, 0, false)
+
+	closeChart := func() {
+		l.pages.RemovePage("sprint")
+		l.setActiveIndex(lastIndex)
+	}
+	chart.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeChart()
+			return nil
+		}
+		if event.Key() == tcell.KeyRune && event.Rune() == 'u' {
+			chart.burnup = !chart.burnup
+			chart.updateTitle()
+			return nil
+		}
+		if action, _ := l.keymap.Lookup([]string{keyName(event)}); action == ActionSprint || action == ActionQuit {
+			closeChart()
+			return nil
+		}
+		return event
+	})
+
+	l.pages.RemovePage("sprint")
+	l.pages.AddPage("sprint", page, true, true)
+	l.app.SetFocus(chart)
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestSprintChart(t *testing.T) {
+	r := model.SprintReport{Name: "s1", Scope: 4, Length: 3, Days: []model.SprintDay{
+		{Scope: 4, Remaining: 4, Ideal: 4},
+		{Scope: 4, Done: 2, Remaining: 2, Ideal: 2},
+	}}
+	chart := newSprintChart(r, DefaultTheme())
+	want := []string{"█  ", "██ ", "██·"}
+	for i, row := range chart.plot(3, 3) {
+		if string(row) != want[i] {
+			t.Fatalf("burndown row %v: %q", i, string(row))
+		}
+	}
+	chart.burnup = true
+	want = []string{"···", " █ ", " █ "}
+	for i, row := range chart.plot(3, 3) {
+		if string(row) != want[i] {
+			t.Fatalf("burnup row %v: %q", i, string(row))
+		}
+	}
+}
+
+func TestCmdSprint(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", t.TempDir(), t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+
+	l.CmdSprint()
+	if name, _ := l.pages.GetFrontPage(); name != "error" {
+		t.Fatalf("front page %q without sprint", name)
+	}
+	l.pages.RemovePage("error")
+	now := time.Now()
+	c.StartSprint("s1", now, now.AddDate(0, 0, 13), c.OpenTasks())
+	l.CmdSprint()
+	if name, _ := l.pages.GetFrontPage(); name != "sprint" {
+		t.Fatalf("front page %q", name)
+	}
+}
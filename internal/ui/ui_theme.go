@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme returns the active theme.
+func (l *Lanes) Theme() *Theme {
+	return l.theme
+}
+
+// SetThemes sets the themes offered in the theme selection dialog.
+func (l *Lanes) SetThemes(themes []*Theme) {
+	l.themes = themes
+}
+
+// SetThemeChangedFunc sets a handler called with the theme name after the
+// user selected a theme, e.g. to store the choice.
+func (l *Lanes) SetThemeChangedFunc(handler func(name string)) {
+	l.themeChanged = handler
+}
+
+// AddThemeFunc registers a function which colors primitives not managed by
+// the lanes, e.g. the status bar. It is called with the active theme right
+// away and again after each theme change.
+func (l *Lanes) AddThemeFunc(f func(t *Theme)) {
+	l.themeFuncs = append(l.themeFuncs, f)
+	f(l.theme)
+}
+
+// ApplyTheme activates the theme with the given name.
+func (l *Lanes) ApplyTheme(name string) error {
+	t := findTheme(l.themes, name)
+	if t == nil {
+		return fmt.Errorf("unknown theme '%v'", name)
+	}
+	l.theme = t
+	t.applyStyles()
+
+	for i, list := range l.lanes {
+		l.styleLane(i)
+		if i == l.active {
+			l.highlight(list)
+		}
+	}
+	for _, m := range l.modals {
+		m.SetBackgroundColor(color(t.Dialog)).
+			SetTextColor(color(t.DialogText)).
+			SetButtonBackgroundColor(color(t.Background)).
+			SetButtonTextColor(color(t.Text))
+	}
+	for _, m := range []*ModalInput{l.add, l.edit, l.addMode} {
+		m.applyTheme(t)
+	}
+	if l.bMoveHelp != nil && l.inselect {
+		l.bMoveHelp.SetLabel(l.moveHelpLabel())
+	}
+	for _, f := range l.themeFuncs {
+		f(t)
+	}
+	l.styleDetail()
+	l.RedrawAllLanes()
+	l.updateDetail()
+	return nil
+}
+
+// styleLane sets the theme colors of a lane.
+func (l *Lanes) styleLane(i int) {
+	t := l.theme
+	l.lanes[i].SetMainTextColor(color(t.Text)).
+		SetSecondaryTextColor(color(t.SecondaryText)).
+		SetBackgroundColor(l.laneBackground(i))
+	l.lanes[i].SetBorderColor(color(t.Border)).
+		SetTitleColor(color(t.Title))
+}
+
+// laneBackground returns the background color of a lane, which is the lane
+// color if one is set.
+func (l *Lanes) laneBackground(laneIndex int) tcell.Color {
+	if col := l.content.GetLaneColor(laneIndex); col != "" {
+		return tcell.GetColor(col)
+	}
+	return color(l.theme.Background)
+}
+
+// highlight sets the colors of the cursor of the active lane.
+func (l *Lanes) highlight(list *tview.List) {
+	list.SetSelectedBackgroundColor(color(l.theme.Selection))
+	list.SetSelectedTextColor(color(l.theme.SelectionText))
+}
+
+func (l *Lanes) moveHelpLabel() string {
+	return tag(l.theme.StatusMode) + "↔ ↕ [" + l.theme.StatusText + "::b]Use Arrow Keys to Move Task"
+}
+
+func (m *ModalInput) applyTheme(t *Theme) {
+	m.SetButtonBackgroundColor(color(t.Background)).
+		SetButtonTextColor(color(t.Text)).
+		SetLabelColor(color(t.DialogLabel)).
+		SetFieldBackgroundColor(color(t.Dialog)).
+		SetFieldTextColor(color(t.DialogText)).
+		SetBackgroundColor(color(t.Dialog))
+	m.frame.SetBackgroundColor(color(t.Dialog))
+	m.frame.SetBorderColor(color(t.Border)).
+		SetTitleColor(color(t.Title))
+	m.updateOKButton()
+}
+
+func (m *NoteEditor) applyTheme(t *Theme) {
+	style := tcell.StyleDefault.Foreground(color(t.DialogText)).Background(color(t.Dialog))
+	m.SetTextStyle(style).
+		SetPlaceholderStyle(style).
+		SetBackgroundColor(color(t.Dialog))
+	m.frame.SetBackgroundColor(color(t.Dialog))
+	m.frame.SetBorderColor(color(t.Border)).
+		SetTitleColor(color(t.Title))
+}
+
+// CmdSelectThemeDialog shows the available themes. The selected theme is
+// applied immediately.
+func (l *Lanes) CmdSelectThemeDialog() {
+	lastIndex := l.saveActive()
+	names := make([]string, len(l.themes))
+	activeIndex := 0
+	for i, t := range l.themes {
+		names[i] = t.Name
+		if t == l.theme {
+			activeIndex = i
+		}
+	}
+	themePage := tview.NewModal().
+		SetTitle(" Theme ").
+		SetText("Select the color theme. Own themes can be placed as JSON files in '~/.todo/themes'.").
+		AddButtons(append(names, "Cancel")).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("theme")
+			l.setActiveIndex(lastIndex)
+			if buttonIndex < 0 || buttonLabel == "Cancel" {
+				return
+			}
+			if err := l.ApplyTheme(buttonLabel); err != nil {
+				l.showError("lanes", err.Error())
+				return
+			}
+			if l.themeChanged != nil {
+				l.themeChanged(buttonLabel)
+			}
+		})
+
+	themePage.SetFocus(activeIndex)
+	l.pages.RemovePage("theme")
+	l.pages.AddPage("theme", themePage, false, true)
+}
@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+// currentUserName returns the name of the user running the program, used
+// for the timers.
+func currentUserName() string {
+	if usr, err := user.Current(); err == nil {
+		return usr.Username
+	}
+	return ""
+}
+
+// timerLabel returns the status bar text of the running timer of the user.
+func (l *Lanes) timerLabel(now time.Time) string {
+	item := l.content.RunningTimer(l.userName)
+	if item == nil {
+		return ""
+	}
+	e := item.Time[item.RunningEntry(l.userName)]
+	d := e.Duration(now).Round(time.Second)
+	elapsed := fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	return tag(l.theme.StatusMode) + "◷ " + elapsed + " [" + l.theme.StatusText + "::-]" + tview.Escape(shorten(item.Title, 26))
+}
+
+// updateTimer shows the running timer in the status bar, unless the help
+// for moving a task is shown there.
+func (l *Lanes) updateTimer(now time.Time) {
+	if l.bMoveHelp != nil && !l.inselect {
+		l.bMoveHelp.SetLabel(l.timerLabel(now))
+	}
+}
+
+// CmdToggleTimer starts the timer of the current task or stops it, if it is
+// running. Only one timer of the user runs at a time, a timer running for
+// another task, also of another mode, is stopped.
+func (l *Lanes) CmdToggleTimer() {
+	item := l.currentItem()
+	if item == nil {
+		return
+	}
+	now := time.Now()
+	if item.RunningEntry(l.userName) >= 0 {
+		l.content.StopTimer(l.userName, now)
+	} else {
+		if l.modeStore != nil {
+			if err := l.modeStore.StopTimers(l.userName, l.mode, now); err != nil {
+				l.showError("lanes", err.Error())
+			}
+		}
+		l.content.StartTimer(item.Guid, l.userName, now)
+	}
+	l.save()
+	l.redrawLanes()
+	l.updateTimer(now)
+}
+
+// timeLogHelp is the help at the start of the time entries in the editor.
+const timeLogHelp = `# One entry per line: start - end (time, or date and time), or date and
+# duration, optionally followed by the user. Lines starting with # are ignored.
+#   2006-01-02 09:00 - 10:30
+#   2006-01-02 1h30m
+`
+
+// CmdTimeLog shows the time entries of the current task in an editor, to
+// correct them or to add entries.
+func (l *Lanes) CmdTimeLog() {
+	item := l.currentItem()
+	if item == nil {
+		return
+	}
+	guid, old := item.Guid, append([]model.TimeEntry{}, item.Time...)
+	lines := make([]string, len(old))
+	for i, e := range old {
+		lines[i] = model.FormatTimeEntry(e, time.Local)
+	}
+
+	editor := NewNoteEditor(fmt.Sprintf("Time: %v (%v)", tview.Escape(item.Title), model.FormatDuration(item.TrackedTime(time.Now()))))
+	editor.SetHelp("Ctrl-S save, Esc cancel, Ctrl-Z undo, Ctrl-Y redo")
+	editor.applyTheme(l.theme)
+	editor.SetNote(timeLogHelp + strings.Join(lines, "\n"))
+	closeEditor := func() {
+		l.hideDialog("timeLog")
+		l.pages.RemovePage("timeLog")
+	}
+	editor.SetDoneFunc(func(text string, success bool) {
+		if !success || !editor.Modified() {
+			closeEditor()
+			return
+		}
+		entries, err := model.ParseTimeEntries(text, l.userName, time.Local, old)
+		if err != nil {
+			l.editorError(editor, err.Error())
+			return
+		}
+		lane, pos, found := l.content.FindItem(guid)
+		if !found {
+			l.editorError(editor, "The task was removed meanwhile.")
+			return
+		}
+		closeEditor()
+		l.content.Items[lane][pos].Time = entries
+		l.save()
+		l.redrawLanes()
+		l.updateTimer(time.Now())
+	})
+	l.pages.RemovePage("timeLog")
+	l.pages.AddPage("timeLog", modal(editor, 0, 0), false, true)
+	l.showDialog("timeLog", editor)
+}
+
+// editorError shows an error and returns to the editor.
+func (l *Lanes) editorError(editor *NoteEditor, message string) {
+	m := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("editorError")
+			l.app.SetFocus(editor)
+		})
+	l.pages.AddPage("editorError", m, false, true)
+	l.app.SetFocus(m)
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestToggleTimer(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "deploy", "", 2, "", "")
+	c.AddItem(0, 1, "review", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.SetMoveHelpButton(tview.NewButton(""))
+	l.RedrawAllLanes()
+
+	l.CmdToggleTimer()
+	if item := c.RunningTimer(l.userName); item == nil || item.Title != "deploy" {
+		t.Fatalf("running timer %+v", item)
+	}
+	if label := l.bMoveHelp.GetLabel(); !strings.Contains(label, "◷ 0:00:0") || !strings.Contains(label, "deploy") {
+		t.Fatalf("status bar %q", label)
+	}
+	if main, _ := l.lanes[0].GetItemText(0); !strings.Contains(main, "◷") {
+		t.Fatalf("timer marker missing in %q", main)
+	}
+
+	// starting the timer of another task stops the first one
+	l.lanes[0].SetCurrentItem(1)
+	l.CmdToggleTimer()
+	if item := c.RunningTimer(l.userName); item == nil || item.Title != "review" || len(c.Items[0][0].Time) != 1 || c.Items[0][0].Time[0].End == "" {
+		t.Fatalf("timers deploy %+v, review %+v", c.Items[0][0].Time, c.Items[0][1].Time)
+	}
+	l.CmdToggleTimer()
+	if c.RunningTimer(l.userName) != nil || l.bMoveHelp.GetLabel() != "" {
+		t.Fatalf("timer not stopped, status bar %q", l.bMoveHelp.GetLabel())
+	}
+}
+
+func TestTimeLogEditor(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	c.AddItem(0, 0, "deploy", "", 2, "", "")
+	c.SetFileName(t.TempDir()+"/todo.json", "", "")
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	save := tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl)
+
+	l.CmdTimeLog()
+	editor, ok := l.activeDialog.(*NoteEditor)
+	if !ok {
+		t.Fatalf("time log editor not shown")
+	}
+	editor.SetText(editor.GetText()+"2024-05-06 09:00 - 10:30\n2024-05-07 45m", true)
+	editor.InputHandler()(save, func(tview.Primitive) {})
+	if d := c.Items[0][0].TrackedTime(time.Now()); d != 135*time.Minute || l.dialogActive {
+		t.Fatalf("time entries not saved: %+v", c.Items[0][0].Time)
+	}
+
+	l.CmdTimeLog()
+	editor = l.activeDialog.(*NoteEditor)
+	editor.SetText("2024-05-06 10:00 - 9:00", true)
+	editor.InputHandler()(save, func(tview.Primitive) {})
+	if name, _ := l.pages.GetFrontPage(); name != "editorError" || len(c.Items[0][0].Time) != 2 {
+		t.Fatalf("invalid entry not reported, front page %q", name)
+	}
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// CmdToggleWaiting shows only the waiting tasks, or all tasks again.
+func (l *Lanes) CmdToggleWaiting() {
+	l.toggleFilter(filterWaiting)
+}
+
+// remindFollowUps shows the waiting tasks whose follow-up date has come and
+// which were not shown yet. The board is checked once a minute, while no
+// dialog is open.
+func (l *Lanes) remindFollowUps(now time.Time) {
+	if now.Sub(l.followUpCheck) < time.Minute || l.dialogActive {
+		return
+	}
+	if name, _ := l.pages.GetFrontPage(); name != "lanes" {
+		return
+	}
+	l.followUpCheck = now
+	if l.reminded == nil {
+		l.reminded = make(map[string]string)
+	}
+	var lines []string
+	for _, ai := range l.content.WaitingItems(l.mode) {
+		item := ai.Item
+		if !item.FollowUpDue(now) || l.reminded[item.Guid] == item.Waiting.FollowUp {
+			continue
+		}
+		l.reminded[item.Guid] = item.Waiting.FollowUp
+		lines = append(lines, fmt.Sprintf("%v (waiting on %v since %v)", shorten(item.Title, 40), item.Waiting.On, isoToLocal(item.Waiting.Since)))
+	}
+	if len(lines) == 0 {
+		return
+	}
+	lastIndex := l.saveActive()
+	m := tview.NewModal().
+		SetTitle(" Follow Up ").
+		SetText("Time to follow up on:\n\n" + tview.Escape(strings.Join(lines, "\n"))).
+		AddButtons([]string{"Show waiting", "OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			l.pages.RemovePage("followUp")
+			if buttonLabel == "Show waiting" {
+				l.setFilter(filterWaiting)
+			}
+			l.setActiveIndex(lastIndex)
+		})
+	l.pages.AddPage("followUp", m, false, true)
+	l.app.SetFocus(m)
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/cklukas/todo/internal/model"
+)
+
+func TestWaiting(t *testing.T) {
+	c := &model.ToDoContent{}
+	c.InitializeNew()
+	for i, title := range []string{"review", "deploy", "docs"} {
+		c.AddItem(0, i, title, "", 2, "", "")
+	}
+	c.SetFileName(t.TempDir()+"/todo.json", "", t.TempDir())
+	app := tview.NewApplication()
+	l := NewLanes(c, app, "", t.TempDir(), "")
+	l.RedrawAllLanes()
+
+	l.lanes[0].SetCurrentItem(1)
+	l.CmdEditTask()
+	l.edit.waitingOn, l.edit.followUp = "ops", "someday"
+	l.edit.done("deploy", "", true)
+	if c.Items[0][1].IsWaiting() {
+		t.Fatal("waiting set with invalid follow-up")
+	}
+	l.pages.RemovePage("error")
+	l.edit.followUp = "2000-01-01"
+	l.edit.done("deploy", "", true)
+	if w := c.Items[0][1].Waiting; w == nil || w.On != "ops" || w.FollowUp != "2000-01-01" {
+		t.Fatalf("waiting %+v", w)
+	}
+	if _, secondary := l.lanes[0].GetItemText(1); !strings.Contains(secondary, "waiting on ops") {
+		t.Fatalf("waiting missing in %q", secondary)
+	}
+
+	l.CmdToggleWaiting()
+	if n := l.lanes[0].GetItemCount(); n != 1 || !strings.Contains(l.lanes[0].GetTitle(), "(waiting)") {
+		t.Fatalf("%v tasks shown, title %q", n, l.lanes[0].GetTitle())
+	}
+	l.CmdToggleWaiting()
+
+	// a due follow-up is shown once
+	now := time.Now()
+	l.remindFollowUps(now)
+	if name, _ := l.pages.GetFrontPage(); name != "followUp" {
+		t.Fatalf("front page %q", name)
+	}
+	l.pages.RemovePage("followUp")
+	l.remindFollowUps(now.Add(time.Hour))
+	if name, _ := l.pages.GetFrontPage(); name == "followUp" {
+		t.Fatal("follow-up shown twice")
+	}
+}